@@ -4,11 +4,11 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
-	"github.com/krateoplatformops/krateoctl/internal/cmd/install/secrets"
 	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/deletor"
@@ -51,8 +51,16 @@ type applyCmd struct {
 	repository     string
 	installType    string
 	debug          bool
-	initSecrets    bool // Dev-only hidden flag for generating sample secrets
-	skipValidation bool // Skip configuration validation
+	skipValidation bool   // Skip configuration validation
+	autoEnable     bool   // Enable the components required by an enabled component
+	parallelism    int    // Maximum number of workflow steps executed concurrently
+	resume         bool   // Skip the steps completed by the previous run
+	atomic         bool   // Roll back failed chart upgrades and uninstall failed chart installs
+	prune          bool   // Delete the resources of the steps removed since the stored snapshot
+	dryRun         string // Validate the run against the API server ("server") without changing anything
+	output         shared.OutputFormat
+	timeout        time.Duration // Bound of the whole apply, zero for none
+	jobTimeout     time.Duration // Maximum time to wait for every pre-upgrade and post-upgrade Job
 
 	restConfigFn    restConfigProvider
 	getterFactory   getterFactory
@@ -62,6 +70,7 @@ type applyCmd struct {
 	errEvaluator    func([]workflows.StepResult[any]) error
 	stateFactory    stateStoreFactory
 	ensureCRDFn     ensureCRDFunc
+	out             io.Writer // Receives the --output report (default os.Stdout)
 	stateName       string
 }
 
@@ -94,6 +103,9 @@ func (c *applyCmd) ensureDeps() {
 	if c.ensureCRDFn == nil {
 		c.ensureCRDFn = state.EnsureCRD
 	}
+	if c.out == nil {
+		c.out = os.Stdout
+	}
 	c.namespace = shared.EnsureNamespace(c.namespace)
 	c.stateName = shared.EnsureStateName(c.stateName)
 }
@@ -113,6 +125,17 @@ func (c *applyCmd) Usage() string {
 	fmt.Fprint(&wri, "  --type string         choose which file variant to use. Supported values: nodeport, loadbalancer, ingress. For example, nodeport looks for krateo.nodeport.yaml and files like pre-upgrade.nodeport.yaml. (default \"nodeport\")\n")
 	fmt.Fprint(&wri, "  --profile string      optional profile name (e.g. dev, prod)\n")
 	fmt.Fprint(&wri, "  --skip-validation     skip configuration validation (useful for emergency recovery)\n")
+	fmt.Fprint(&wri, "  --auto-enable-dependencies\n")
+	fmt.Fprint(&wri, "                        enable the components required by an enabled component\n")
+	fmt.Fprint(&wri, "  --parallelism int     maximum number of independent steps (see dependsOn) executed concurrently (default 1)\n")
+	fmt.Fprint(&wri, "  --resume              skip the steps already completed by the previous run, unless their configuration changed\n")
+	fmt.Fprint(&wri, "  --atomic              roll back failed chart upgrades and uninstall failed chart installs (chart steps can override it with atomic)\n")
+	fmt.Fprint(&wri, "  --prune               delete objects and uninstall releases of the steps removed from the config, or disabled, since the last apply\n")
+	fmt.Fprint(&wri, "  --dry-run string      set to \"server\" to validate every step against the cluster (admission webhooks, quotas, schemas) without changing anything\n")
+	fmt.Fprint(&wri, "  --output string       print a machine-readable report of the run on stdout: json or yaml (logs move to stderr)\n")
+	fmt.Fprint(&wri, "  --timeout duration    stop the whole apply after this time, as an interrupt would (default 0, no limit)\n")
+	fmt.Fprint(&wri, "  --lifecycle-timeout duration\n")
+	fmt.Fprint(&wri, "                        maximum time to wait for every pre-upgrade and post-upgrade Job (default 5m)\n")
 	fmt.Fprint(&wri, "  --debug               enable debug-level logging (can also use KRATEOCTL_DEBUG env var)\n\n")
 	fmt.Fprint(&wri, "MODES:\n\n")
 	fmt.Fprint(&wri, "  Remote mode: When --version is specified, config is fetched from the releases\n")
@@ -135,6 +158,20 @@ func (c *applyCmd) Usage() string {
 	fmt.Fprint(&wri, "  krateoctl install apply --config ./krateo.yaml --type loadbalancer\n\n")
 	fmt.Fprint(&wri, "  # Apply using ingress-specific files such as krateo.ingress.yaml\n")
 	fmt.Fprint(&wri, "  krateoctl install apply --config ./krateo.yaml --type ingress\n\n")
+	fmt.Fprint(&wri, "  # Run up to 4 independent steps at the same time\n")
+	fmt.Fprint(&wri, "  krateoctl install apply --config ./krateo.yaml --parallelism 4\n\n")
+	fmt.Fprint(&wri, "  # Continue a failed apply from the first step that did not complete\n")
+	fmt.Fprint(&wri, "  krateoctl install apply --config ./krateo.yaml --resume\n\n")
+	fmt.Fprint(&wri, "  # Roll back the charts whose upgrade fails\n")
+	fmt.Fprint(&wri, "  krateoctl install apply --version v1.0.0 --atomic\n\n")
+	fmt.Fprint(&wri, "  # Remove what the steps deleted from krateo.yaml had installed\n")
+	fmt.Fprint(&wri, "  krateoctl install apply --config ./krateo.yaml --prune\n\n")
+	fmt.Fprint(&wri, "  # Check a new release against the cluster without applying it\n")
+	fmt.Fprint(&wri, "  krateoctl install apply --version v1.0.0 --dry-run=server\n\n")
+	fmt.Fprint(&wri, "  # Give up after 30 minutes; the completed steps are recorded for --resume\n")
+	fmt.Fprint(&wri, "  krateoctl install apply --version v1.0.0 --timeout 30m\n\n")
+	fmt.Fprint(&wri, "  # Print a JSON report of the steps for a CI pipeline\n")
+	fmt.Fprint(&wri, "  krateoctl install apply --version v1.0.0 --output json > report.json\n\n")
 	return wri.String()
 }
 
@@ -146,19 +183,68 @@ func (c *applyCmd) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&c.installType, "type", "nodeport", "choose which file variant to use: nodeport, loadbalancer, or ingress")
 	f.StringVar(&c.profile, "profile", "", "optional profile name")
 	f.BoolVar(&c.skipValidation, "skip-validation", false, "skip configuration validation")
+	f.BoolVar(&c.autoEnable, "auto-enable-dependencies", false, "enable the components required by an enabled component")
+	f.IntVar(&c.parallelism, "parallelism", 1, "maximum number of independent steps executed concurrently")
+	f.BoolVar(&c.resume, "resume", false, "skip the steps already completed by the previous run")
+	f.BoolVar(&c.atomic, "atomic", false, "roll back failed chart upgrades and uninstall failed chart installs")
+	f.BoolVar(&c.prune, "prune", false, "delete the resources of the steps removed since the last apply")
+	f.StringVar(&c.dryRun, "dry-run", "", "validate the run against the cluster without changing anything: server")
+	f.Var(&c.output, "output", "print a machine-readable report of the run on stdout: json or yaml")
+	f.DurationVar(&c.timeout, "timeout", 0, "stop the whole apply after this time (0 for no limit)")
+	f.DurationVar(&c.jobTimeout, "lifecycle-timeout", lifecycle.DefaultJobTimeout, "maximum time to wait for every pre-upgrade and post-upgrade Job")
 	f.BoolVar(&c.debug, "debug", false, "enable debug-level logging")
-	// Hidden utility flag - not documented in Usage()
-	f.BoolVar(&c.initSecrets, "init-secrets", false, "")
 }
 
 func (c *applyCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
 	c.ensureDeps()
 
 	// 1. Initialize UI and Logging
+	// Logs go to stderr when stdout carries the --output report.
+	logOut := io.Writer(os.Stdout)
+	if c.output != "" {
+		logOut = os.Stderr
+	}
 	// Enable debug mode from flag or environment variable
-	spin := ui.NewSpinner(os.Stdout)
+	spin := ui.NewSpinner(logOut)
 	l := shared.NewLogger(spin, c.debug || os.Getenv(shared.KRATEOCTL_DEBUG_ENV) != "")
 	defer spin.Stop("")
+
+	version := c.version
+	if version == "" {
+		version = "local"
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, c.timeout, fmt.Errorf("timed out after %s", c.timeout))
+		defer cancel()
+	}
+
+	report := shared.NewReport("apply", c.namespace, version)
+	status := c.run(ctx, l, spin, report)
+	if c.output == "" {
+		return status
+	}
+
+	spin.Stop("")
+	if err := report.Write(c.out, c.output, status); err != nil {
+		l.Error("Failed to write the report: %v", err)
+		return subcommands.ExitFailure
+	}
+	return status
+}
+
+// run applies the configuration and records the outcome in report.
+func (c *applyCmd) run(ctx context.Context, l *ui.Logger, spin *ui.Spinner, report *shared.Report) subcommands.ExitStatus {
+	if c.dryRun != "" && c.dryRun != "server" {
+		report.Fail(l, "Invalid --dry-run value %q: only \"server\" is supported, use \"krateoctl install template\" to render offline", c.dryRun)
+		return subcommands.ExitUsageError
+	}
+	dryRun := c.dryRun == "server"
+	if dryRun && c.resume {
+		report.Fail(l, "--resume cannot be combined with --dry-run")
+		return subcommands.ExitUsageError
+	}
+
 	lifecycleManager := lifecycle.NewManager(c.namespace, func(cfg *rest.Config) (*getter.Getter, error) {
 		return c.getterFactory(cfg)
 	})
@@ -168,16 +254,16 @@ func (c *applyCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface
 
 	// 2. Load Configuration
 	result, err := shared.LoadConfigAndSteps(shared.NewLoadOptions(shared.LoadOptionsInput{
-		ConfigFile:       c.configFile,
-		Namespace:        c.namespace,
-		Profile:          c.profile,
-		Version:          c.version,
-		Repository:       c.repository,
-		InstallationType: c.installType,
+		ConfigFile:             c.configFile,
+		Namespace:              c.namespace,
+		Profile:                c.profile,
+		Version:                c.version,
+		Repository:             c.repository,
+		InstallationType:       c.installType,
+		AutoEnableDependencies: c.autoEnable,
 	}), c.namespace, l.Info, c.skipValidation)
 	if err != nil {
-		l.Error("Failed to load configuration: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(l, "Failed to load configuration: %v", err)
 	}
 
 	if len(result.Steps) == 0 {
@@ -189,34 +275,25 @@ func (c *applyCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface
 	l.Info("\n📡 Connecting to Kubernetes cluster...")
 	rc, err := c.restConfigFn()
 	if err != nil {
-		l.Error("Failed to load kubeconfig: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(l, "Failed to load kubeconfig: %v", err)
 	}
 	l.Info("✓ Kubernetes connection established")
 
-	if err := c.ensureCRDFn(ctx, rc); err != nil {
-		l.Error("Failed to ensure installation CRD: %v", err)
-		return subcommands.ExitFailure
+	if dryRun {
+		return c.executeDryRun(ctx, rc, l, spin, result, report)
 	}
 
-	// Initialize sample secrets if requested (dev-only hidden feature)
-	if c.initSecrets {
-		l.Info("\n🔐 Initializing sample secrets...")
-		if err := secrets.InitializeSecrets(ctx, rc, c.namespace); err != nil {
-			l.Error("Failed to initialize secrets: %v", err)
-			return subcommands.ExitFailure
-		}
-		l.Info("✓ Sample secrets created successfully (%s, %s, %s) in namespace '%s'", secrets.KrateoDbSecretName, secrets.KrateoDbUserSecretName, secrets.JWTSecretName, c.namespace)
+	if err := c.ensureCRDFn(ctx, rc); err != nil {
+		return report.Fail(l, "Failed to ensure installation CRD: %v", err)
 	}
 
 	// 4.5. Apply Pre-Upgrade Manifests (if they exist)
 	a, err := c.applierFactory(rc)
 	if err != nil {
-		l.Error("Failed to initialize applier: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(l, "Failed to initialize applier: %v", err)
 	}
 
-	if err := lifecycleManager.Apply(ctx, a, l, lifecycle.ApplyOptions{
+	if err := report.Phase("pre-upgrade", lifecycleManager.Apply(ctx, a, l, lifecycle.ApplyOptions{
 		Phase:            "pre-upgrade",
 		Version:          c.version,
 		Repository:       c.repository,
@@ -224,9 +301,9 @@ func (c *applyCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface
 		RestConfig:       rc,
 		JobNameSuffix:    jobNameSuffix,
 		InstallationType: c.installType,
-	}); err != nil {
-		l.Error("Failed to apply pre-upgrade manifests: %v", err)
-		return subcommands.ExitFailure
+		JobTimeout:       c.jobTimeout,
+	})); err != nil {
+		return report.Fail(l, "Failed to apply pre-upgrade manifests: %v", err)
 	}
 
 	// 5. Execute Workflow
@@ -238,47 +315,49 @@ func (c *applyCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface
 	spin.Start()
 
 	// Use the shared workflow executor for the core apply path.
-	version := c.version
-	if version == "" {
-		version = "local"
-	}
-	execResult, err := shared.ExecuteWorkflow(ctx, rc, shared.ExecuteWorkflowOptions{
+	execOpts := shared.ExecuteWorkflowOptions{
 		Namespace:        c.namespace,
 		StateName:        c.stateName,
 		Logger:           l,
 		Result:           result,
 		ProgressReporter: c.createProgressReporter(spin, l, len(result.Steps)),
+		StepProgress:     c.createStepProgress(spin),
 		SaveState:        false,
-		Version:          version,
-	}, shared.WorkflowDeps{
-		GetterFactory:  shared.GetterFactory(c.getterFactory),
-		ApplierFactory: shared.ApplierFactory(c.applierFactory),
-		DeletorFactory: shared.DeletorFactory(c.deletorFactory),
-		WorkflowFactory: func(opts workflows.Opts) (shared.WorkflowRunner, error) {
-			wf, err := c.workflowFactory(opts)
-			if err != nil {
-				return nil, err
-			}
-			return sharedWorkflowRunner{workflowRunner: wf}, nil
-		},
-		ErrEvaluator: shared.ErrEvaluator(c.errEvaluator),
-		StateFactory: shared.StateStoreFactory(c.stateFactory),
-	})
+		Version:          report.Version,
+		Parallelism:      c.parallelism,
+		Checkpoint:       true,
+		Resume:           c.resume,
+		Atomic:           c.atomic,
+	}
+	deps := c.workflowDeps()
+	execResult, err := shared.ExecuteWorkflow(ctx, rc, execOpts, deps)
 	spin.Stop("")
 
 	// 6. Final Report
 	l.Info("═════════════════════════════════════════════════════════════")
 	if execResult != nil {
 		shared.LogWorkflowResults(l, result.Steps, execResult.Results)
+		report.Steps = shared.StepReports(result.Steps, execResult.Results)
 	}
 
 	if err != nil {
+		report.Error = err.Error()
+		if cause := context.Cause(ctx); cause != nil {
+			report.Error = fmt.Sprintf("%v: %v", cause, err)
+			l.Error("\nApply %v: the completed steps are recorded, run again with --resume to continue.", cause)
+			return subcommands.ExitFailure
+		}
 		l.Error("\nWorkflow completed with errors.")
 		return subcommands.ExitFailure
 	}
 
 	// 6.5. Apply Post-Upgrade Manifests (if they exist)
-	if err := lifecycleManager.Apply(ctx, a, l, lifecycle.ApplyOptions{
+	var stepVars, runVars map[string]string
+	if execResult != nil {
+		stepVars = workflows.ResultVars(execResult.Results)
+		runVars = workflows.RunVars(execResult.Results)
+	}
+	if err := report.Phase("post-upgrade", lifecycleManager.Apply(ctx, a, l, lifecycle.ApplyOptions{
 		Phase:            "post-upgrade",
 		Version:          c.version,
 		Repository:       c.repository,
@@ -286,20 +365,37 @@ func (c *applyCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface
 		RestConfig:       rc,
 		JobNameSuffix:    jobNameSuffix,
 		InstallationType: c.installType,
-	}); err != nil {
-		l.Error("Failed to apply post-upgrade manifests: %v", err)
-		return subcommands.ExitFailure
+		Vars:             stepVars,
+		JobTimeout:       c.jobTimeout,
+	})); err != nil {
+		return report.Fail(l, "Failed to apply post-upgrade manifests: %v", err)
+	}
+
+	// 6.6. Prune the steps removed since the stored snapshot
+	if c.prune {
+		spin.SetPrefix("🗑  ")
+		spin.Start()
+		pruneResult, err := shared.PruneWorkflow(ctx, rc, execOpts, deps, runVars)
+		spin.Stop("")
+		if pruneResult != nil && len(pruneResult.Steps) > 0 {
+			l.Info("\n🗑 Pruned %d steps removed from the configuration:", len(pruneResult.Steps))
+			shared.LogWorkflowResults(l, pruneResult.Steps, pruneResult.Results)
+			report.Pruned = shared.StepReports(pruneResult.Steps, pruneResult.Results)
+		}
+		if err != nil {
+			return report.Fail(l, "Failed to prune removed steps: %v", err)
+		}
 	}
 
 	if execResult != nil && execResult.Snapshot != nil {
 		store, storeErr := c.stateFactory(rc, c.namespace)
 		if storeErr != nil {
-			l.Error("Failed to initialize installation state store: %v", storeErr)
-			return subcommands.ExitFailure
+			return report.Fail(l, "Failed to initialize installation state store: %v", storeErr)
 		}
 		if err := store.Save(ctx, c.stateName, execResult.Snapshot); err != nil {
 			l.Warn("⚠ Unable to persist installation snapshot: %v", err)
 		} else {
+			report.Snapshot = c.stateName
 			l.Info("✓ Installation snapshot saved as %q with apiVersion %q and kind %q in the namespace %q", c.stateName, "krateo.io/v1", "Installation", c.namespace)
 		}
 	}
@@ -308,15 +404,45 @@ func (c *applyCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface
 	return subcommands.ExitSuccess
 }
 
+// workflowDeps adapts the factories of the command to the shared workflow executor.
+func (c *applyCmd) workflowDeps() shared.WorkflowDeps {
+	return shared.WorkflowDeps{
+		GetterFactory:  shared.GetterFactory(c.getterFactory),
+		ApplierFactory: shared.ApplierFactory(c.applierFactory),
+		DeletorFactory: shared.DeletorFactory(c.deletorFactory),
+		WorkflowFactory: func(opts workflows.Opts) (shared.WorkflowRunner, error) {
+			wf, err := c.workflowFactory(opts)
+			if err != nil {
+				return nil, err
+			}
+			return sharedWorkflowRunner{workflowRunner: wf}, nil
+		},
+		ErrEvaluator: shared.ErrEvaluator(c.errEvaluator),
+		StateFactory: shared.StateStoreFactory(c.stateFactory),
+	}
+}
+
 func (c *applyCmd) createProgressReporter(spin *ui.Spinner, l *ui.Logger, total int) workflows.StepNotifier {
+	// Steps may start out of declaration order when they run as a graph,
+	// so progress is counted on notifications rather than on the step index.
+	started := 0
 	return func(idx int, step *types.Step, skipped bool) {
+		started++
 		status := "executing"
 		if skipped {
 			status = "skipped"
 		}
-		spin.SetSuffix(fmt.Sprintf("step %d/%d - %s (%s)", idx+1, total, step.ID, status))
+		spin.SetSuffix(fmt.Sprintf("step %d/%d - %s (%s)", started, total, step.ID, status))
 
 		l.V(ui.LevelDebug).Info("Processing workflow step: index=%d id=%s type=%s",
 			idx+1, step.ID, step.Type)
 	}
 }
+
+// createStepProgress shows the status reported by long running steps, such as
+// the time left to a wait step, in the spinner suffix.
+func (c *applyCmd) createStepProgress(spin *ui.Spinner) func(id, message string) {
+	return func(id, message string) {
+		spin.SetSuffix(fmt.Sprintf("%s (%s)", id, message))
+	}
+}
@@ -1,19 +1,26 @@
 package apply
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/deletor"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
 	"github.com/krateoplatformops/krateoctl/internal/install/state"
 	"github.com/krateoplatformops/krateoctl/internal/subcommands"
 	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
 	"k8s.io/client-go/rest"
 )
@@ -104,17 +111,355 @@ func TestApplyExecute(t *testing.T) {
 	}
 }
 
+func TestApplyExecuteResume(t *testing.T) {
+	for _, resume := range []bool{false, true} {
+		cfg := writeApplyConfig(t, "componentsDefinition:\n  demo:\n    steps:\n      - step-one\nsteps:\n  - id: step-one\n    type: chart\n    with:\n      releaseName: demo\n")
+
+		store := &stubStateStore{checkpoints: []state.Checkpoint{{ID: "step-one", Digest: "abc"}}}
+		var opts workflows.Opts
+		cmd := &applyCmd{
+			configFile:   cfg,
+			namespace:    "test-ns",
+			resume:       resume,
+			restConfigFn: func() (*rest.Config, error) { return &rest.Config{}, nil },
+			getterFactory: func(*rest.Config) (*getter.Getter, error) {
+				return &getter.Getter{}, nil
+			},
+			applierFactory: func(*rest.Config) (*applier.Applier, error) {
+				return &applier.Applier{}, nil
+			},
+			deletorFactory: func(*rest.Config) (*deletor.Deletor, error) {
+				return &deletor.Deletor{}, nil
+			},
+			workflowFactory: func(o workflows.Opts) (workflowRunner, error) {
+				opts = o
+				return &stubWorkflow{}, nil
+			},
+			stateFactory: func(*rest.Config, string) (state.Store, error) { return store, nil },
+			ensureCRDFn:  func(context.Context, *rest.Config) error { return nil },
+			stateName:    "test-install",
+		}
+
+		if status := cmd.Execute(context.Background(), flag.NewFlagSet("apply", flag.ContinueOnError)); status != subcommands.ExitSuccess {
+			t.Fatalf("Execute(resume=%v) = %v, want %v", resume, status, subcommands.ExitSuccess)
+		}
+		if opts.OnStepCompleted == nil {
+			t.Fatalf("Execute(resume=%v) did not register a checkpoint recorder", resume)
+		}
+		if store.reset == resume {
+			t.Fatalf("Execute(resume=%v) reset checkpoints = %v", resume, store.reset)
+		}
+		if got := opts.Completed["step-one"].Digest; resume && got != "abc" {
+			t.Fatalf("Execute(resume=true) completed digest = %q, want %q", got, "abc")
+		}
+		if !resume && len(opts.Completed) != 0 {
+			t.Fatalf("Execute(resume=false) completed = %v, want none", opts.Completed)
+		}
+	}
+}
+
+func TestApplyExecuteAtomic(t *testing.T) {
+	cfg := writeApplyConfig(t, "componentsDefinition:\n  demo:\n    steps:\n      - step-one\nsteps:\n  - id: step-one\n    type: chart\n    with:\n      releaseName: demo\n")
+
+	var opts workflows.Opts
+	cmd := &applyCmd{
+		configFile:   cfg,
+		namespace:    "test-ns",
+		atomic:       true,
+		restConfigFn: func() (*rest.Config, error) { return &rest.Config{}, nil },
+		getterFactory: func(*rest.Config) (*getter.Getter, error) {
+			return &getter.Getter{}, nil
+		},
+		applierFactory: func(*rest.Config) (*applier.Applier, error) {
+			return &applier.Applier{}, nil
+		},
+		deletorFactory: func(*rest.Config) (*deletor.Deletor, error) {
+			return &deletor.Deletor{}, nil
+		},
+		workflowFactory: func(o workflows.Opts) (workflowRunner, error) {
+			opts = o
+			return &stubWorkflow{}, nil
+		},
+		stateFactory: func(*rest.Config, string) (state.Store, error) { return &stubStateStore{}, nil },
+		ensureCRDFn:  func(context.Context, *rest.Config) error { return nil },
+		stateName:    "test-install",
+	}
+
+	if status := cmd.Execute(context.Background(), flag.NewFlagSet("apply", flag.ContinueOnError)); status != subcommands.ExitSuccess {
+		t.Fatalf("Execute() = %v, want %v", status, subcommands.ExitSuccess)
+	}
+	if !opts.Atomic {
+		t.Fatalf("Execute() did not enable atomic chart steps")
+	}
+}
+
+func TestApplyExecutePrune(t *testing.T) {
+	cfg := writeApplyConfig(t, "componentsDefinition:\n  demo:\n    steps:\n      - step-one\nsteps:\n  - id: step-one\n    type: chart\n    with:\n      releaseName: demo\n")
+
+	snapshot, err := state.BuildSnapshot(nil, []*types.Step{
+		{ID: "step-one", Type: types.TypeChart, With: &map[string]any{"releaseName": "demo"}},
+		{ID: "removed", Type: types.TypeChart, With: &map[string]any{"releaseName": "legacy"}},
+	}, "v1")
+	if err != nil {
+		t.Fatalf("BuildSnapshot() error = %v", err)
+	}
+
+	store := &stubStateStore{snapshot: snapshot}
+	var ops []steps.Op
+	runner := &stubWorkflow{}
+	cmd := &applyCmd{
+		configFile:   cfg,
+		namespace:    "test-ns",
+		prune:        true,
+		restConfigFn: func() (*rest.Config, error) { return &rest.Config{}, nil },
+		getterFactory: func(*rest.Config) (*getter.Getter, error) {
+			return &getter.Getter{}, nil
+		},
+		applierFactory: func(*rest.Config) (*applier.Applier, error) {
+			return &applier.Applier{}, nil
+		},
+		deletorFactory: func(*rest.Config) (*deletor.Deletor, error) {
+			return &deletor.Deletor{}, nil
+		},
+		workflowFactory: func(o workflows.Opts) (workflowRunner, error) {
+			ops = append(ops, o.Op)
+			return runner, nil
+		},
+		stateFactory: func(*rest.Config, string) (state.Store, error) { return store, nil },
+		ensureCRDFn:  func(context.Context, *rest.Config) error { return nil },
+		stateName:    "test-install",
+	}
+
+	if status := cmd.Execute(context.Background(), flag.NewFlagSet("apply", flag.ContinueOnError)); status != subcommands.ExitSuccess {
+		t.Fatalf("Execute() = %v, want %v", status, subcommands.ExitSuccess)
+	}
+	if len(ops) != 2 || ops[1] != steps.Delete {
+		t.Fatalf("workflow operations = %v, want an apply followed by a delete run", ops)
+	}
+	if want := []string{"step-one", "removed"}; !slices.Equal(runner.ran, want) {
+		t.Fatalf("executed steps = %v, want %v", runner.ran, want)
+	}
+	if !store.saved {
+		t.Fatalf("Execute() did not save the new snapshot after pruning")
+	}
+}
+
+func TestApplyExecuteDryRun(t *testing.T) {
+	cfg := writeApplyConfig(t, "componentsDefinition:\n  demo:\n    steps:\n      - step-one\nsteps:\n  - id: step-one\n    type: chart\n    with:\n      releaseName: demo\n")
+
+	tests := []struct {
+		name       string
+		dryRun     string
+		stepErr    error
+		wantStatus subcommands.ExitStatus
+		wantRun    bool
+	}{
+		{
+			name:       "accepted steps succeed without changes",
+			dryRun:     "server",
+			wantStatus: subcommands.ExitSuccess,
+			wantRun:    true,
+		},
+		{
+			name:       "rejected steps fail",
+			dryRun:     "server",
+			stepErr:    errors.New("admission webhook denied the request"),
+			wantStatus: subcommands.ExitFailure,
+			wantRun:    true,
+		},
+		{
+			name:       "client mode is not supported",
+			dryRun:     "client",
+			wantStatus: subcommands.ExitUsageError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &stubStateStore{}
+			var opts []workflows.Opts
+			ensured := false
+			cmd := &applyCmd{
+				configFile:   cfg,
+				namespace:    "test-ns",
+				dryRun:       tc.dryRun,
+				restConfigFn: func() (*rest.Config, error) { return &rest.Config{}, nil },
+				getterFactory: func(*rest.Config) (*getter.Getter, error) {
+					return &getter.Getter{}, nil
+				},
+				applierFactory: func(*rest.Config) (*applier.Applier, error) {
+					return &applier.Applier{}, nil
+				},
+				deletorFactory: func(*rest.Config) (*deletor.Deletor, error) {
+					return &deletor.Deletor{}, nil
+				},
+				workflowFactory: func(o workflows.Opts) (workflowRunner, error) {
+					opts = append(opts, o)
+					return &stubWorkflow{}, nil
+				},
+				errEvaluator: func([]workflows.StepResult[any]) error { return tc.stepErr },
+				stateFactory: func(*rest.Config, string) (state.Store, error) { return store, nil },
+				ensureCRDFn: func(context.Context, *rest.Config) error {
+					ensured = true
+					return nil
+				},
+				stateName: "test-install",
+			}
+
+			if status := cmd.Execute(context.Background(), flag.NewFlagSet("apply", flag.ContinueOnError)); status != tc.wantStatus {
+				t.Fatalf("Execute() = %v, want %v", status, tc.wantStatus)
+			}
+			if ran := len(opts) > 0; ran != tc.wantRun {
+				t.Fatalf("workflow ran = %v, want %v", ran, tc.wantRun)
+			}
+			if tc.wantRun && !opts[0].DryRun {
+				t.Fatalf("Execute() did not run the workflow in dry-run mode")
+			}
+			if ensured || store.saved || store.reset || len(store.checkpoints) > 0 {
+				t.Fatalf("Execute() changed the installation state in dry-run mode")
+			}
+		})
+	}
+}
+
+func TestApplyExecuteReport(t *testing.T) {
+	cfg := writeApplyConfig(t, "componentsDefinition:\n  demo:\n    steps:\n      - step-one\nsteps:\n  - id: step-one\n    type: chart\n    with:\n      releaseName: demo\n")
+
+	tests := []struct {
+		name         string
+		errEvaluator func([]workflows.StepResult[any]) error
+		wantStatus   string
+		wantSnapshot string
+	}{
+		{
+			name:         "reports the steps and the saved snapshot",
+			wantStatus:   shared.StateSucceeded,
+			wantSnapshot: "test-install",
+		},
+		{
+			name: "reports the workflow error",
+			errEvaluator: func([]workflows.StepResult[any]) error {
+				return errors.New("workflow failure")
+			},
+			wantStatus: shared.StateFailed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := &applyCmd{
+				configFile:   cfg,
+				namespace:    "test-ns",
+				output:       shared.OutputJSON,
+				out:          &out,
+				restConfigFn: func() (*rest.Config, error) { return &rest.Config{}, nil },
+				getterFactory: func(*rest.Config) (*getter.Getter, error) {
+					return &getter.Getter{}, nil
+				},
+				applierFactory: func(*rest.Config) (*applier.Applier, error) {
+					return &applier.Applier{}, nil
+				},
+				deletorFactory: func(*rest.Config) (*deletor.Deletor, error) {
+					return &deletor.Deletor{}, nil
+				},
+				workflowFactory: func(workflows.Opts) (workflowRunner, error) {
+					return &stubWorkflow{}, nil
+				},
+				stateFactory: func(*rest.Config, string) (state.Store, error) { return &stubStateStore{}, nil },
+				ensureCRDFn:  func(context.Context, *rest.Config) error { return nil },
+				errEvaluator: tc.errEvaluator,
+				stateName:    "test-install",
+			}
+
+			cmd.Execute(context.Background(), flag.NewFlagSet("apply", flag.ContinueOnError))
+
+			var report shared.Report
+			if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+				t.Fatalf("Unmarshal() error = %v\n%s", err, out.String())
+			}
+			if report.Status != tc.wantStatus || report.Snapshot != tc.wantSnapshot {
+				t.Fatalf("report status = %q, snapshot %q, want %q and %q", report.Status, report.Snapshot, tc.wantStatus, tc.wantSnapshot)
+			}
+			if tc.wantStatus == shared.StateFailed && report.Error != "workflow failure" {
+				t.Fatalf("report error = %q, want the workflow failure", report.Error)
+			}
+			if len(report.Steps) != 1 || report.Steps[0].ID != "step-one" || report.Steps[0].Type != types.TypeChart {
+				t.Fatalf("report steps = %+v, want step-one", report.Steps)
+			}
+		})
+	}
+}
+
+func TestApplyExecuteTimeout(t *testing.T) {
+	cfg := writeApplyConfig(t, "componentsDefinition:\n  demo:\n    steps:\n      - step-one\nsteps:\n  - id: step-one\n    type: chart\n    with:\n      releaseName: demo\n")
+
+	var out bytes.Buffer
+	var runCtx context.Context
+	store := &stubStateStore{}
+	cmd := &applyCmd{
+		configFile:   cfg,
+		namespace:    "test-ns",
+		output:       shared.OutputJSON,
+		out:          &out,
+		timeout:      time.Nanosecond,
+		restConfigFn: func() (*rest.Config, error) { return &rest.Config{}, nil },
+		getterFactory: func(*rest.Config) (*getter.Getter, error) {
+			return &getter.Getter{}, nil
+		},
+		applierFactory: func(*rest.Config) (*applier.Applier, error) {
+			return &applier.Applier{}, nil
+		},
+		deletorFactory: func(*rest.Config) (*deletor.Deletor, error) {
+			return &deletor.Deletor{}, nil
+		},
+		workflowFactory: func(workflows.Opts) (workflowRunner, error) {
+			return &stubWorkflow{}, nil
+		},
+		stateFactory: func(*rest.Config, string) (state.Store, error) { return store, nil },
+		ensureCRDFn: func(ctx context.Context, _ *rest.Config) error {
+			runCtx = ctx
+			<-ctx.Done()
+			return nil
+		},
+		errEvaluator: func([]workflows.StepResult[any]) error { return runCtx.Err() },
+		stateName:    "test-install",
+	}
+
+	if status := cmd.Execute(context.Background(), flag.NewFlagSet("apply", flag.ContinueOnError)); status != subcommands.ExitFailure {
+		t.Fatalf("Execute() = %v, want %v", status, subcommands.ExitFailure)
+	}
+
+	var report shared.Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Unmarshal() error = %v\n%s", err, out.String())
+	}
+	if !strings.HasPrefix(report.Error, "timed out after 1ns") {
+		t.Fatalf("report error = %q, want the global timeout", report.Error)
+	}
+	if store.saved {
+		t.Fatalf("Execute() saved the snapshot of a timed out apply")
+	}
+}
+
 type stubWorkflow struct {
 	called bool
+	ran    []string
 }
 
 func (s *stubWorkflow) Run(_ context.Context, spec *types.Workflow, _ func(*types.Step) bool, _ workflows.StepNotifier) []workflows.StepResult[any] {
 	s.called = true
+	for _, step := range spec.Steps {
+		s.ran = append(s.ran, step.ID)
+	}
 	return make([]workflows.StepResult[any], len(spec.Steps))
 }
 
 type stubStateStore struct {
-	saved bool
+	saved       bool
+	reset       bool
+	checkpoints []state.Checkpoint
+	snapshot    *state.Snapshot
 }
 
 func (s *stubStateStore) Save(_ context.Context, _ string, snapshot *state.Snapshot) error {
@@ -123,7 +468,26 @@ func (s *stubStateStore) Save(_ context.Context, _ string, snapshot *state.Snaps
 }
 
 func (s *stubStateStore) Load(_ context.Context, _ string) (*state.Snapshot, error) {
-	return nil, nil
+	return s.snapshot, nil
+}
+
+func (s *stubStateStore) SaveCheckpoint(_ context.Context, _ string, cp state.Checkpoint) error {
+	s.checkpoints = append(s.checkpoints, cp)
+	return nil
+}
+
+func (s *stubStateStore) LoadCheckpoints(_ context.Context, _ string) ([]state.Checkpoint, error) {
+	return s.checkpoints, nil
+}
+
+func (s *stubStateStore) ResetCheckpoints(_ context.Context, _ string) error {
+	s.reset = true
+	s.checkpoints = nil
+	return nil
+}
+
+func (s *stubStateStore) Delete(_ context.Context, _ string) error {
+	return nil
 }
 
 func writeApplyConfig(t *testing.T, data string) string {
@@ -0,0 +1,73 @@
+package apply
+
+import (
+	"context"
+
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+	"github.com/krateoplatformops/krateoctl/internal/ui"
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"k8s.io/client-go/rest"
+)
+
+// executeDryRun runs the workflow in server dry-run mode: every object and
+// release is validated and admitted by the API server without being stored.
+// The installation CRD, the lifecycle manifests, the checkpoints and the
+// snapshot are left alone, so the run does not change the cluster at all.
+func (c *applyCmd) executeDryRun(ctx context.Context, rc *rest.Config, l *ui.Logger, spin *ui.Spinner, result *shared.LoadResult, report *shared.Report) subcommands.ExitStatus {
+	report.DryRun = true
+
+	l.Info("\n🔎 Checking %d steps against namespace '%s' (server dry run)...", len(result.Steps), c.namespace)
+	l.Info("═════════════════════════════════════════════════════════════")
+
+	spin.SetPrefix("🔎 ")
+	spin.Start()
+
+	execOpts := shared.ExecuteWorkflowOptions{
+		Namespace:        c.namespace,
+		StateName:        c.stateName,
+		Logger:           l,
+		Result:           result,
+		ProgressReporter: c.createProgressReporter(spin, l, len(result.Steps)),
+		StepProgress:     c.createStepProgress(spin),
+		Parallelism:      c.parallelism,
+		DryRun:           true,
+	}
+	deps := c.workflowDeps()
+	execResult, err := shared.ExecuteWorkflow(ctx, rc, execOpts, deps)
+	spin.Stop("")
+
+	l.Info("═════════════════════════════════════════════════════════════")
+	if execResult == nil {
+		return report.Fail(l, "Dry run failed: %v", err)
+	}
+	summary := shared.LogDryRunResults(l, result.Steps, execResult.Results)
+	report.Steps = shared.StepReports(result.Steps, execResult.Results)
+
+	if c.prune {
+		pruneResult, pruneErr := shared.PruneWorkflow(ctx, rc, execOpts, deps, workflows.RunVars(execResult.Results))
+		if pruneResult != nil && len(pruneResult.Steps) > 0 {
+			l.Info("\n🗑 Steps removed from the configuration that would be pruned:")
+			summary.Merge(shared.LogDryRunResults(l, pruneResult.Steps, pruneResult.Results))
+			report.Pruned = shared.StepReports(pruneResult.Steps, pruneResult.Results)
+		}
+		if pruneResult == nil && pruneErr != nil {
+			return report.Fail(l, "Failed to check the pruning of removed steps: %v", pruneErr)
+		}
+		if err == nil {
+			err = pruneErr
+		}
+	}
+
+	l.Info("\n🔎 Dry run: %s", summary)
+	if err != nil || summary.Rejected > 0 {
+		if err != nil {
+			report.Error = err.Error()
+		}
+		l.Error("Dry run found rejected steps, nothing was changed.")
+		return subcommands.ExitFailure
+	}
+
+	l.Info("✓ Dry run completed, nothing was changed\n")
+	return subcommands.ExitSuccess
+}
@@ -0,0 +1,413 @@
+package components
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
+	"github.com/krateoplatformops/krateoctl/internal/config"
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+	"github.com/krateoplatformops/krateoctl/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+func Command() subcommands.Command {
+	return &componentsCmd{}
+}
+
+type componentsCmd struct {
+	configFile    string
+	profile       string
+	installType   string
+	autoEnable    bool
+	debug         bool
+	overridesPath string    // krateo-overrides.yaml, next to which the profile files live
+	out           io.Writer // Receives the list and describe output (default os.Stdout)
+}
+
+func (c *componentsCmd) Name() string { return "components" }
+func (c *componentsCmd) Synopsis() string {
+	return "list, describe, enable and disable components"
+}
+
+func (c *componentsCmd) Usage() string {
+	wri := bytes.Buffer{}
+	fmt.Fprintf(&wri, "%s. Show the components of the local configuration, or toggle one by editing krateo-overrides.yaml or a profile file.\n\n", c.Synopsis())
+
+	fmt.Fprint(&wri, "USAGE:\n\n")
+	fmt.Fprint(&wri, "  krateoctl install components list [FLAGS]\n")
+	fmt.Fprint(&wri, "  krateoctl install components describe <name> [FLAGS]\n")
+	fmt.Fprint(&wri, "  krateoctl install components enable <name> [FLAGS]\n")
+	fmt.Fprint(&wri, "  krateoctl install components disable <name> [FLAGS]\n\n")
+
+	fmt.Fprint(&wri, "FLAGS:\n\n")
+	fmt.Fprint(&wri, "  --config string\n")
+	fmt.Fprintf(&wri, "        path to local configuration file (default \"%s\")\n", shared.DefaultConfigPath)
+	fmt.Fprint(&wri, "  --profile string\n")
+	fmt.Fprint(&wri, "        profile to show, or to write the change into (krateo-overrides.<profile>.yaml)\n")
+	fmt.Fprint(&wri, "  --type string\n")
+	fmt.Fprint(&wri, "        choose which file variant to use. Supported values: nodeport, loadbalancer, ingress. (default \"nodeport\")\n")
+	fmt.Fprint(&wri, "  --auto-enable-dependencies\n")
+	fmt.Fprint(&wri, "        enable the components required by an enabled component\n")
+	fmt.Fprint(&wri, "  --debug\n")
+	fmt.Fprint(&wri, "        enable debug-level logging (can also use KRATEOCTL_DEBUG env var)\n\n")
+
+	fmt.Fprint(&wri, "NOTES:\n\n")
+	fmt.Fprint(&wri, "  - The source of a component is the file that last sets its enabled state:\n")
+	fmt.Fprint(&wri, "    krateo.yaml (base), a profile or krateo-overrides.yaml (override).\n")
+	fmt.Fprint(&wri, "  - enable and disable write components.<name>.enabled into krateo-overrides.yaml or,\n")
+	fmt.Fprint(&wri, "    with --profile, into the profile, keeping the comments and the order of the keys.\n\n")
+
+	fmt.Fprint(&wri, "EXAMPLES:\n\n")
+	fmt.Fprint(&wri, "  # List the components with their state\n")
+	fmt.Fprint(&wri, "  krateoctl install components list\n\n")
+	fmt.Fprint(&wri, "  # Disable a component in the dev profile\n")
+	fmt.Fprint(&wri, "  krateoctl install components disable finops --profile dev\n\n")
+
+	return wri.String()
+}
+
+func (c *componentsCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.configFile, "config", shared.DefaultConfigPath, "path to local configuration file")
+	f.StringVar(&c.profile, "profile", "", "profile to show, or to write the change into")
+	f.StringVar(&c.installType, "type", "nodeport", "choose which file variant to use: nodeport, loadbalancer, or ingress")
+	f.BoolVar(&c.autoEnable, "auto-enable-dependencies", false, "enable the components required by an enabled component")
+	f.BoolVar(&c.debug, "debug", false, "enable debug-level logging")
+}
+
+func (c *componentsCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	if c.overridesPath == "" {
+		c.overridesPath = shared.DefaultOverridesPath
+	}
+	if c.out == nil {
+		c.out = os.Stdout
+	}
+
+	l := shared.NewLogger(os.Stderr, c.debug || os.Getenv(shared.KRATEOCTL_DEBUG_ENV) != "")
+
+	args, err := parseInterspersed(fs)
+	if err != nil {
+		return subcommands.ExitUsageError
+	}
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, c.Usage())
+		return subcommands.ExitUsageError
+	}
+
+	action, args := args[0], args[1:]
+	if action == "list" {
+		if len(args) != 0 {
+			l.Error("list takes no arguments")
+			return subcommands.ExitUsageError
+		}
+		return c.list(l)
+	}
+
+	if len(args) != 1 {
+		l.Error("%s takes the name of a component", action)
+		return subcommands.ExitUsageError
+	}
+
+	switch action {
+	case "describe":
+		return c.describe(l, args[0])
+	case "enable":
+		return c.toggle(l, args[0], true)
+	case "disable":
+		return c.toggle(l, args[0], false)
+	default:
+		l.Error("unknown components action %q (expected: list|describe|enable|disable)", action)
+		return subcommands.ExitUsageError
+	}
+}
+
+// parseInterspersed returns the positional arguments of fs, parsing the flags
+// that follow them, as in "enable finops --profile dev".
+func parseInterspersed(fs *flag.FlagSet) ([]string, error) {
+	var positional []string
+	args := fs.Args()
+	for len(args) > 0 {
+		if !strings.HasPrefix(args[0], "-") {
+			positional = append(positional, args[0])
+			args = args[1:]
+			continue
+		}
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+	}
+	return positional, nil
+}
+
+// component is a component with its resolved state.
+type component struct {
+	name   string
+	def    config.ComponentConfig
+	state  config.ComponentState
+	source string
+}
+
+// load returns the components of the configuration selected by the flags.
+func (c *componentsCmd) load() (*config.Config, []component, error) {
+	loader := config.NewLoader(config.LoadOptions{
+		ConfigPath:        c.configFile,
+		UserOverridesPath: c.overridesPath,
+		Profile:           c.profile,
+		InstallationType:  c.installType,
+	})
+
+	sources, err := loader.Sources()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := loader.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := config.NewConfig(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.WithAutoEnableDependencies(c.autoEnable)
+
+	states, err := cfg.GetEnabledComponents()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defs := cfg.GetComponents()
+	list := make([]component, 0, len(defs))
+	for _, name := range slices.Sorted(maps.Keys(defs)) {
+		list = append(list, component{
+			name:   name,
+			def:    defs[name],
+			state:  states[name],
+			source: componentSource(sources, name),
+		})
+	}
+	return cfg, list, nil
+}
+
+// componentSource describes the last source setting the enabled state of the
+// component or, when none does, the first one declaring it.
+func componentSource(sources []config.Source, name string) string {
+	var declared, enabled *config.Source
+	for i := range sources {
+		for _, section := range []string{"componentsDefinition", "components"} {
+			comp, ok := lookup(sources[i].Data, section, name).(map[string]any)
+			if !ok {
+				continue
+			}
+			if declared == nil {
+				declared = &sources[i]
+			}
+			if _, ok := comp["enabled"]; ok {
+				enabled = &sources[i]
+			}
+		}
+	}
+
+	src := enabled
+	if src == nil {
+		src = declared
+	}
+	switch {
+	case src == nil:
+		return "-"
+	case src.Kind == config.SourceProfile:
+		return fmt.Sprintf("%s (profile %s)", src.Path, src.Profile)
+	default:
+		return fmt.Sprintf("%s (%s)", src.Path, src.Kind)
+	}
+}
+
+func lookup(data map[string]any, keys ...string) any {
+	var cur any = data
+	for _, key := range keys {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[key]
+	}
+	return cur
+}
+
+func (c *componentsCmd) list(l *ui.Logger) subcommands.ExitStatus {
+	_, list, err := c.load()
+	if err != nil {
+		l.Error("Failed to load configuration: %v", err)
+		return subcommands.ExitFailure
+	}
+	if len(list) == 0 {
+		l.Info("ℹ No components configured")
+		return subcommands.ExitSuccess
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tENABLED\tSOURCE\tDESCRIPTION")
+	for _, comp := range list {
+		fmt.Fprintf(w, "%s\t%t\t%s\t%s\n", comp.name, comp.state.Enabled, comp.source, comp.def.Description)
+	}
+	if err := w.Flush(); err != nil {
+		l.Error("Failed to print the components: %v", err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+func (c *componentsCmd) describe(l *ui.Logger, name string) subcommands.ExitStatus {
+	cfg, list, err := c.load()
+	if err != nil {
+		l.Error("Failed to load configuration: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	i := slices.IndexFunc(list, func(comp component) bool { return comp.name == name })
+	if i < 0 {
+		l.Error("Component %q is not defined", name)
+		return subcommands.ExitFailure
+	}
+	comp := list[i]
+
+	steps, err := cfg.GetSteps()
+	if err != nil {
+		l.Error("Failed to get steps: %v", err)
+		return subcommands.ExitFailure
+	}
+	types := make(map[string]string, len(steps))
+	for _, step := range steps {
+		types[step.ID] = string(step.Type)
+	}
+
+	enabled := fmt.Sprint(comp.state.Enabled)
+	if comp.state.Reason != "" {
+		enabled += " (" + comp.state.Reason + ")"
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", comp.name)
+	fmt.Fprintf(w, "Description:\t%s\n", orNone(comp.def.Description))
+	fmt.Fprintf(w, "Enabled:\t%s\n", enabled)
+	fmt.Fprintf(w, "Source:\t%s\n", comp.source)
+	fmt.Fprintf(w, "Requires:\t%s\n", orNone(strings.Join(comp.def.Requires, ", ")))
+	fmt.Fprintf(w, "Conflicts:\t%s\n", orNone(strings.Join(comp.def.Conflicts, ", ")))
+	fmt.Fprintln(w, "Steps:")
+	for _, id := range comp.def.Steps {
+		fmt.Fprintf(w, "  - %s\t(%s)\n", id, orNone(types[id]))
+	}
+	if err := w.Flush(); err != nil {
+		l.Error("Failed to print the component: %v", err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// toggle writes the enabled state of the component into krateo-overrides.yaml
+// or, with --profile, into the profile, then reports the resulting state.
+func (c *componentsCmd) toggle(l *ui.Logger, name string, enabled bool) subcommands.ExitStatus {
+	if strings.Contains(c.profile, ",") {
+		l.Error("enable and disable accept a single --profile")
+		return subcommands.ExitUsageError
+	}
+
+	base := *c
+	base.profile = ""
+	if _, list, err := base.load(); err != nil {
+		l.Error("Failed to load configuration: %v", err)
+		return subcommands.ExitFailure
+	} else if !slices.ContainsFunc(list, func(comp component) bool { return comp.name == name }) {
+		l.Error("Component %q is not defined", name)
+		return subcommands.ExitFailure
+	}
+
+	path, keys, err := c.target(name)
+	if err != nil {
+		l.Error("%v", err)
+		return subcommands.ExitFailure
+	}
+	if err := config.SetFileValue(path, keys, enabled); err != nil {
+		l.Error("Failed to update %s: %v", path, err)
+		return subcommands.ExitFailure
+	}
+
+	action := "disabled"
+	if enabled {
+		action = "enabled"
+	}
+	l.Info("✓ Component %s %s in %s", name, action, path)
+
+	cfg, list, err := c.load()
+	if err != nil {
+		l.Error("Failed to load the updated configuration: %v", err)
+		return subcommands.ExitFailure
+	}
+	for _, comp := range list {
+		switch {
+		case comp.name == name && comp.state.Enabled != enabled:
+			l.Warn("⚠ Component %s is still %s, set by %s", name, stateName(comp.state), comp.source)
+		case comp.state.Reason != "":
+			l.Info("ℹ Component %s %s", comp.name, comp.state.Reason)
+		}
+	}
+	if err := config.NewValidator(cfg).Validate(); err != nil {
+		l.Warn("⚠ The configuration does not validate: %v", err)
+	}
+
+	return subcommands.ExitSuccess
+}
+
+func stateName(state config.ComponentState) string {
+	if state.Enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// target returns the file, and the path in it, where the enabled state of the
+// component is written: krateo-overrides.yaml, the in-file profile or the
+// krateo-overrides.<profile>.yaml file, created when the profile is new.
+func (c *componentsCmd) target(name string) (string, []string, error) {
+	keys := []string{"components", name, "enabled"}
+	if c.profile == "" {
+		return c.overridesPath, keys, nil
+	}
+
+	profilePath := config.ProfilePath(c.overridesPath, c.profile)
+	if _, err := os.Stat(profilePath); err == nil {
+		return profilePath, keys, nil
+	}
+
+	content, err := os.ReadFile(c.overridesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("failed to read %s: %w", c.overridesPath, err)
+	}
+	var overrides map[string]any
+	if err := yaml.Unmarshal(content, &overrides); err != nil {
+		return "", nil, fmt.Errorf("failed to parse YAML from %s: %w", c.overridesPath, err)
+	}
+	if profiles, ok := overrides["profiles"].(map[string]any); ok {
+		if _, ok := profiles[c.profile]; ok {
+			return c.overridesPath, append([]string{"profiles", c.profile}, keys...), nil
+		}
+	}
+
+	return profilePath, keys, nil
+}
@@ -0,0 +1,173 @@
+package components
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+)
+
+const testConfig = `componentsDefinition:
+  authn:
+    description: Authentication service
+    steps: [install-authn]
+  frontend:
+    description: Web portal
+    requires: [authn]
+    steps: [install-frontend]
+steps:
+  - id: install-authn
+    type: chart
+  - id: install-frontend
+    type: chart
+`
+
+const testOverrides = `# Local overrides
+components:
+  authn:
+    enabled: true # keep it
+profiles:
+  dev:
+    components: {}
+`
+
+func newTestCmd(t *testing.T) (*componentsCmd, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "krateo.yaml"), testConfig)
+	writeFile(t, filepath.Join(dir, "krateo-overrides.yaml"), testOverrides)
+
+	return &componentsCmd{
+		configFile:    filepath.Join(dir, "krateo.yaml"),
+		overridesPath: filepath.Join(dir, "krateo-overrides.yaml"),
+		out:           &bytes.Buffer{},
+	}, dir
+}
+
+func run(t *testing.T, cmd *componentsCmd, args ...string) subcommands.ExitStatus {
+	t.Helper()
+
+	// SetFlags resets the configuration file, it is passed again as a flag.
+	args = append([]string{"--config", cmd.configFile}, args...)
+
+	fs := flag.NewFlagSet("components", flag.ContinueOnError)
+	cmd.SetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return cmd.Execute(context.Background(), fs)
+}
+
+func TestComponentsList(t *testing.T) {
+	cmd, dir := newTestCmd(t)
+
+	if status := run(t, cmd, "list"); status != subcommands.ExitSuccess {
+		t.Fatalf("Execute() = %v, want success", status)
+	}
+
+	out := cmd.out.(*bytes.Buffer).String()
+	for _, want := range []string{
+		"authn     true     " + filepath.Join(dir, "krateo-overrides.yaml") + " (override)  Authentication service",
+		"frontend  true     " + filepath.Join(dir, "krateo.yaml") + " (base)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("list output =\n%s\nwant a line containing %q", out, want)
+		}
+	}
+}
+
+func TestComponentsDisable(t *testing.T) {
+	cmd, dir := newTestCmd(t)
+
+	if status := run(t, cmd, "disable", "authn"); status != subcommands.ExitSuccess {
+		t.Fatalf("Execute() = %v, want success", status)
+	}
+
+	got := readFile(t, filepath.Join(dir, "krateo-overrides.yaml"))
+	if want := strings.Replace(testOverrides, "enabled: true # keep it", "enabled: false # keep it", 1); got != want {
+		t.Fatalf("overrides =\n%s\nwant\n%s", got, want)
+	}
+
+	cmd.out = &bytes.Buffer{}
+	if status := run(t, cmd, "describe", "frontend"); status != subcommands.ExitSuccess {
+		t.Fatalf("Execute() = %v, want success", status)
+	}
+	out := cmd.out.(*bytes.Buffer).String()
+	for _, want := range []string{
+		"Enabled:      true",
+		"Requires:     authn",
+		"  - install-frontend  (chart)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("describe output =\n%s\nwant a line containing %q", out, want)
+		}
+	}
+}
+
+func TestComponentsToggleProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		file    string
+		want    string
+	}{
+		{
+			name:    "in-file profile",
+			profile: "dev",
+			file:    "krateo-overrides.yaml",
+			want:    "  dev:\n    components: {frontend: {enabled: false}}\n",
+		},
+		{
+			name:    "new profile file",
+			profile: "prod",
+			file:    "krateo-overrides.prod.yaml",
+			want:    "components:\n  frontend:\n    enabled: false\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, dir := newTestCmd(t)
+
+			if status := run(t, cmd, "disable", "frontend", "--profile", tc.profile); status != subcommands.ExitSuccess {
+				t.Fatalf("Execute() = %v, want success", status)
+			}
+			if got := readFile(t, filepath.Join(dir, tc.file)); !strings.Contains(got, tc.want) {
+				t.Fatalf("%s =\n%s\nwant it to contain\n%s", tc.file, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestComponentsUnknown(t *testing.T) {
+	cmd, _ := newTestCmd(t)
+
+	if status := run(t, cmd, "enable", "events"); status != subcommands.ExitFailure {
+		t.Fatalf("Execute() = %v, want failure", status)
+	}
+	if status := run(t, cmd, "rename", "authn"); status != subcommands.ExitUsageError {
+		t.Fatalf("Execute() = %v, want a usage error", status)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	return string(data)
+}
@@ -8,8 +8,12 @@ import (
 	"os"
 
 	"github.com/krateoplatformops/krateoctl/internal/cmd/install/apply"
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/components"
 	"github.com/krateoplatformops/krateoctl/internal/cmd/install/migrate"
 	"github.com/krateoplatformops/krateoctl/internal/cmd/install/plan"
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/profiles"
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/template"
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/uninstall"
 	"github.com/krateoplatformops/krateoctl/internal/subcommands"
 )
 
@@ -26,10 +30,14 @@ func (c *installCmd) Usage() string {
 	w := &bytes.Buffer{}
 	fmt.Fprintf(w, "%s\n\n", c.Synopsis())
 	fmt.Fprint(w, "USAGE:\n\n")
-	fmt.Fprint(w, "  krateoctl install <plan|apply|migrate|migrate-full> [FLAGS]\n\n")
+	fmt.Fprint(w, "  krateoctl install <plan|apply|template|uninstall|components|profiles|migrate|migrate-full> [FLAGS]\n\n")
 	fmt.Fprint(w, "SUBCOMMANDS:\n\n")
 	fmt.Fprint(w, "  plan                  preview configuration changes\n")
 	fmt.Fprint(w, "  apply                 apply configuration changes to cluster\n")
+	fmt.Fprint(w, "  template              render the manifests produced by the configuration\n")
+	fmt.Fprint(w, "  uninstall             remove an installation from the cluster\n")
+	fmt.Fprint(w, "  components            list, describe, enable and disable components\n")
+	fmt.Fprint(w, "  profiles              list profiles and show the overrides they produce\n")
 	fmt.Fprint(w, "  migrate               convert legacy KrateoPlatformOps to krateo.yaml (manual migration)\n")
 	fmt.Fprint(w, "  migrate-full          convert and switch over automatically (full migration)\n")
 	return w.String()
@@ -53,12 +61,20 @@ func (c *installCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...any) su
 		cmd = plan.Command()
 	case "apply":
 		cmd = apply.Command()
+	case "template":
+		cmd = template.Command()
+	case "uninstall":
+		cmd = uninstall.Command()
+	case "components":
+		cmd = components.Command()
+	case "profiles":
+		cmd = profiles.Command()
 	case "migrate":
 		cmd = migrate.Command()
 	case "migrate-full":
 		cmd = migrate.CommandFullSpecs()
 	default:
-		fmt.Fprintf(os.Stderr, "unknown install subcommand %q (expected: plan|apply|migrate|migrate-full)\n", name)
+		fmt.Fprintf(os.Stderr, "unknown install subcommand %q (expected: plan|apply|template|uninstall|components|profiles|migrate|migrate-full)\n", name)
 		return subcommands.ExitUsageError
 	}
 
@@ -5,6 +5,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 
 	_ "embed"
@@ -16,6 +17,7 @@ import (
 	"github.com/krateoplatformops/krateoctl/internal/install/migrate/legacy"
 	"github.com/krateoplatformops/krateoctl/internal/install/state"
 	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+	"github.com/krateoplatformops/krateoctl/internal/ui"
 	"github.com/krateoplatformops/krateoctl/internal/util/kube"
 	"github.com/krateoplatformops/krateoctl/internal/workflows"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
@@ -64,6 +66,7 @@ type migrateFullSpecsCmd struct {
 	installerCRDRelease string
 	force               bool
 	debug               bool
+	outputFormat        shared.OutputFormat
 
 	restConfigFn      restConfigProvider
 	dynamicFactory    dynamicFactory
@@ -76,6 +79,7 @@ type migrateFullSpecsCmd struct {
 	deletorFactory    deletorFactory
 	workflowFactory   workflowFactory
 	errEvaluator      func([]workflows.StepResult[any]) error
+	out               io.Writer // Receives the --output-format report (default os.Stdout)
 }
 
 func (c *migrateFullSpecsCmd) Name() string { return "migrate-full" }
@@ -98,6 +102,7 @@ func (c *migrateFullSpecsCmd) Usage() string {
 	buf.WriteString("  --installer-release string\n        Helm release name for the installer (default \"installer\")\n")
 	buf.WriteString("  --installer-crd-release string\n        Helm release name for the installer CRD (default \"installer-crd\")\n")
 	buf.WriteString("  --force\n        overwrite the output file if it already exists\n")
+	buf.WriteString("  --output-format string\n        print a machine-readable report of the migration on stdout: json or yaml\n")
 	buf.WriteString("  --debug\n        enable debug-level logging (can also use KRATEOCTL_DEBUG env var)\n\n")
 	buf.WriteString("PREREQUISITES:\n\n")
 	buf.WriteString("  Use this command only for Krateo 2.7.0 installations managed by the installer controller.\n\n")
@@ -121,7 +126,9 @@ func (c *migrateFullSpecsCmd) Usage() string {
 	buf.WriteString("  # Run the full automatic migration and also save the generated file\n")
 	buf.WriteString("  krateoctl install migrate-full --type nodeport --output ./krateo.yaml\n\n")
 	buf.WriteString("  # Run the full migration when installer releases use custom names\n")
-	buf.WriteString("  krateoctl install migrate-full --type ingress --installer-release my-installer --installer-crd-release my-installer-crd\n")
+	buf.WriteString("  krateoctl install migrate-full --type ingress --installer-release my-installer --installer-crd-release my-installer-crd\n\n")
+	buf.WriteString("  # Run the full migration and keep a JSON report of every stage and step\n")
+	buf.WriteString("  krateoctl install migrate-full --type nodeport --output-format json > migration.json\n")
 
 	return buf.String()
 }
@@ -135,6 +142,7 @@ func (c *migrateFullSpecsCmd) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&c.installerRelease, "installer-release", "installer", "Helm release name for the installer")
 	f.StringVar(&c.installerCRDRelease, "installer-crd-release", "installer-crd", "Helm release name for the installer CRD")
 	f.BoolVar(&c.force, "force", false, "overwrite the output file if it already exists")
+	f.Var(&c.outputFormat, "output-format", "print a machine-readable report of the migration on stdout: json or yaml")
 	f.BoolVar(&c.debug, "debug", false, "enable debug-level logging")
 }
 
@@ -150,6 +158,9 @@ func (c *migrateFullSpecsCmd) ensureDeps() {
 	if c.writeFile == nil {
 		c.writeFile = os.WriteFile
 	}
+	if c.out == nil {
+		c.out = os.Stdout
+	}
 	if c.kubeClientFactory == nil {
 		c.kubeClientFactory = func(cfg *rest.Config) (kubernetes.Interface, error) {
 			return kubernetes.NewForConfig(cfg)
@@ -192,25 +203,37 @@ func (c *migrateFullSpecsCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ..
 	// Enable debug mode from flag or environment variable
 	logger := shared.NewLogger(os.Stderr, c.debug || os.Getenv(shared.KRATEOCTL_DEBUG_ENV) != "")
 
+	report := shared.NewReport("migrate-full", c.namespace, "local")
+	status := c.run(ctx, logger, report)
+	if c.outputFormat == "" {
+		return status
+	}
+
+	if err := report.Write(c.out, c.outputFormat, status); err != nil {
+		logger.Error("Failed to write the report: %v", err)
+		return subcommands.ExitFailure
+	}
+	return status
+}
+
+// run performs the migration and records the outcome of every stage in report.
+func (c *migrateFullSpecsCmd) run(ctx context.Context, logger *ui.Logger, report *shared.Report) subcommands.ExitStatus {
 	rc, err := c.restConfigFn()
 	if err != nil {
-		logger.Error("Failed to load kubeconfig: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(logger, "Failed to load kubeconfig: %v", err)
 	}
 
 	dyn, err := c.dynamicFactory(rc)
 	if err != nil {
-		logger.Error("Failed to initialize dynamic client: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(logger, "Failed to initialize dynamic client: %v", err)
 	}
 
 	// Step 1: Fetch legacy resource
 	var legacyObj *unstructured.Unstructured
 	logger.Info("Step 1/6: Fetching legacy KrateoPlatformOps CR...")
 	legacyObj, err = fetchLegacyResource(ctx, dyn, c.namespace, c.name)
-	if err != nil {
-		logger.Error("Failed to read KrateoPlatformOps resource: %v", err)
-		return subcommands.ExitFailure
+	if err := report.Phase("fetch-legacy", err); err != nil {
+		return report.Fail(logger, "Failed to read KrateoPlatformOps resource: %v", err)
 	}
 	logger.Info("✓ Found KrateoPlatformOps: %s/%s", c.namespace, c.name)
 
@@ -218,19 +241,16 @@ func (c *migrateFullSpecsCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ..
 	logger.Info("Step 2/6: Converting to new format...")
 	doc, err := legacy.ConvertDocument(legacyObj.Object, c.namespace)
 	if err != nil {
-		logger.Error("Failed to convert legacy spec: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(logger, "Failed to convert legacy spec: %v", err)
 	}
 
 	if err := applyDefaultComponents(doc, c.installType); err != nil {
-		logger.Error("Failed to load components definition: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(logger, "Failed to load components definition: %v", err)
 	}
 
 	data, err := yaml.Marshal(doc)
 	if err != nil {
-		logger.Error("Failed to marshal converted configuration: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(logger, "Failed to marshal converted configuration: %v", err)
 	}
 
 	if c.outputPath != "" {
@@ -240,8 +260,7 @@ func (c *migrateFullSpecsCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ..
 			writeFile:  c.writeFile,
 			data:       data,
 		}); err != nil {
-			logger.Error("Failed to write %s: %v", c.outputPath, err)
-			return subcommands.ExitFailure
+			return report.Fail(logger, "Failed to write %s: %v", c.outputPath, err)
 		}
 		logger.Info("✓ Generated new configuration: %s", c.outputPath)
 	} else {
@@ -250,9 +269,8 @@ func (c *migrateFullSpecsCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ..
 
 	// Step 3: Scale installer to 0
 	logger.Info("Step 3/6: Scaling installer to 0...")
-	if err := c.scaleInstallerController(ctx, rc, 0); err != nil {
-		logger.Error("Failed to scale installer: %v", err)
-		return subcommands.ExitFailure
+	if err := report.Phase("scale-installer", c.scaleInstallerController(ctx, rc, 0)); err != nil {
+		return report.Fail(logger, "Failed to scale installer: %v", err)
 	}
 	logger.Info("✓ Scaled installer to 0")
 
@@ -261,14 +279,12 @@ func (c *migrateFullSpecsCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ..
 
 	// Ensure CRD exists
 	if err := c.ensureCRDFn(ctx, rc); err != nil {
-		logger.Error("Failed to ensure Installation CRD: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(logger, "Failed to ensure Installation CRD: %v", err)
 	}
 
 	var raw map[string]any
 	if err := yaml.Unmarshal(data, &raw); err != nil {
-		logger.Error("Failed to parse generated configuration: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(logger, "Failed to parse generated configuration: %v", err)
 	}
 	if raw == nil {
 		raw = make(map[string]any)
@@ -277,8 +293,7 @@ func (c *migrateFullSpecsCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ..
 	// Load and validate the generated config directly from memory.
 	result, err := shared.BuildLoadResult(raw, c.namespace, logger.Debug, false)
 	if err != nil {
-		logger.Error("Failed to load generated configuration: %v", err)
-		return subcommands.ExitFailure
+		return report.Fail(logger, "Failed to load generated configuration: %v", err)
 	}
 
 	if len(result.Steps) == 0 {
@@ -314,22 +329,24 @@ func (c *migrateFullSpecsCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ..
 			ErrEvaluator: shared.ErrEvaluator(c.errEvaluator),
 			StateFactory: shared.StateStoreFactory(c.stateFactory),
 		})
-		if err != nil {
-			logger.Error("Workflow execution failed: %v", err)
+		if execResult != nil {
+			report.Steps = shared.StepReports(result.Steps, execResult.Results)
+		}
+		if err := report.Phase("apply", err); err != nil {
 			if execResult != nil {
 				shared.LogWorkflowResults(logger, result.Steps, execResult.Results)
 			}
-			return subcommands.ExitFailure
+			return report.Fail(logger, "Workflow execution failed: %v", err)
 		}
 
 		store, err := c.stateFactory(rc, c.namespace)
 		if err != nil {
-			logger.Error("Failed to initialize installation state store: %v", err)
-			return subcommands.ExitFailure
+			return report.Fail(logger, "Failed to initialize installation state store: %v", err)
 		}
 		if err := store.Save(ctx, state.DefaultInstallationName, execResult.Snapshot); err != nil {
 			logger.Warn("⚠ Unable to persist installation snapshot: %v", err)
 		} else {
+			report.Snapshot = state.DefaultInstallationName
 			logger.Info("✓ Saved installation snapshot: %s/%s", c.namespace, state.DefaultInstallationName)
 		}
 		shared.LogWorkflowResults(logger, result.Steps, execResult.Results)
@@ -338,22 +355,20 @@ func (c *migrateFullSpecsCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ..
 
 	// Step 5: Remove finalizer and delete old CR
 	logger.Info("Step 5/6: Removing finalizer and deleting old KrateoPlatformOps CR...")
-	if err := c.removeFinalizer(ctx, dyn); err != nil {
-		logger.Error("Failed to remove finalizer: %v", err)
-		return subcommands.ExitFailure
+	if err := report.Phase("remove-finalizer", c.removeFinalizer(ctx, dyn)); err != nil {
+		return report.Fail(logger, "Failed to remove finalizer: %v", err)
 	}
 	logger.Info("✓ Removed finalizer")
 
-	if err := c.deleteLegacyResource(ctx, dyn); err != nil {
-		logger.Error("Failed to delete old KrateoPlatformOps CR: %v", err)
-		return subcommands.ExitFailure
+	if err := report.Phase("delete-legacy", c.deleteLegacyResource(ctx, dyn)); err != nil {
+		return report.Fail(logger, "Failed to delete old KrateoPlatformOps CR: %v", err)
 	}
 	logger.Info("✓ Deleted old KrateoPlatformOps CR")
 
 	// Step 6: Uninstall old installer
 	logger.Info("Step 6/6: Uninstalling old installer charts...")
-	if err := c.uninstallOldInstaller(ctx, rc); err != nil {
-		logger.Error("Failed to uninstall old installer: %v", err)
+	if err := report.Phase("uninstall-installer", c.uninstallOldInstaller(ctx, rc)); err != nil {
+		report.Fail(logger, "Failed to uninstall old installer: %v", err)
 		logger.Error("ℹ You can manually uninstall with:")
 		logger.Error("  helm uninstall %s -n %s", c.installerRelease, c.installerNamespace)
 		logger.Error("  helm uninstall %s -n %s", c.installerCRDRelease, c.installerNamespace)
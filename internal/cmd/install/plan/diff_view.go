@@ -12,6 +12,7 @@ import (
 	"github.com/krateoplatformops/krateoctl/internal/diff"
 	"github.com/krateoplatformops/krateoctl/internal/install/state"
 	"github.com/krateoplatformops/krateoctl/internal/ui"
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
 )
 
@@ -19,6 +20,7 @@ type diffStepSummary struct {
 	ID   string         `json:"id"`
 	Type string         `json:"type"`
 	Skip bool           `json:"skip,omitempty"`
+	When string         `json:"when,omitempty"`
 	With map[string]any `json:"with,omitempty"`
 }
 
@@ -28,7 +30,7 @@ type diffRow struct {
 	Summary string
 }
 
-func (c *planCmd) renderDiff(l *ui.Logger, w io.Writer, leftLabel string, leftBytes []byte, rightLabel string, rightBytes []byte, left any, right any) error {
+func (c *planCmd) renderDiff(l *ui.Logger, w io.Writer, leftLabel string, leftBytes []byte, rightLabel string, rightBytes []byte, left any, right any, conditions map[string]workflows.ConditionPreview) error {
 	format, err := normalizeDiffFormat(c.diffFormat)
 	if err != nil {
 		return err
@@ -46,9 +48,9 @@ func (c *planCmd) renderDiff(l *ui.Logger, w io.Writer, leftLabel string, leftBy
 			return err
 		}
 
-		rows, changed := buildDiffRows(leftSummaries, rightSummaries)
-		rows = filterChangedRows(rows)
-		if !changed || len(rows) == 0 {
+		rows, _ := buildDiffRows(leftSummaries, rightSummaries)
+		rows = filterChangedRows(annotateConditions(rows, conditions))
+		if len(rows) == 0 {
 			l.Info("✓ Computed plan matches %s", leftLabel)
 			return nil
 		}
@@ -56,15 +58,67 @@ func (c *planCmd) renderDiff(l *ui.Logger, w io.Writer, leftLabel string, leftBy
 		l.Warn("⚠️  Step diff summary:")
 		return renderDiffTable(w, rows)
 	default:
+		rightSummaries, err := summarizeDiffSteps(right)
+		if err != nil {
+			return err
+		}
+
 		delta := diff.Diff(leftLabel, leftBytes, rightLabel, rightBytes)
 		if len(delta) == 0 {
 			l.Info("✓ Computed plan matches %s", leftLabel)
-			return nil
+		} else {
+			l.Warn("⚠️  Differences vs %s:\n%s", leftLabel, diff.Colorize(delta))
 		}
 
-		l.Warn("⚠️  Differences vs %s:\n%s", leftLabel, diff.Colorize(delta))
+		for _, step := range rightSummaries {
+			if preview, ok := conditions[step.ID]; ok {
+				l.Info("ℹ %s: %s", step.ID, preview.Reason)
+			}
+		}
+		return nil
+	}
+}
+
+// renderPrune lists the steps of the installed snapshot whose resources
+// 'install apply --prune' would delete or uninstall.
+func renderPrune(l *ui.Logger, installed *state.Snapshot, steps []*types.Step) error {
+	list, err := state.PruneSteps(installed, steps)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
 		return nil
 	}
+
+	l.Warn("⚠️  %d step(s) would be pruned by 'install apply --prune':", len(list))
+	for _, step := range list {
+		l.Warn("  - %s (%s)", step.ID, step.Type)
+	}
+	return nil
+}
+
+// annotateConditions marks the rows of steps guarded by a when condition.
+// Unchanged steps that would be skipped are reported as "skipped", the ones
+// whose condition can only be evaluated at apply time as "conditional".
+func annotateConditions(rows []diffRow, conditions map[string]workflows.ConditionPreview) []diffRow {
+	for i, row := range rows {
+		preview, ok := conditions[row.ID]
+		if !ok || row.Status == "removed" {
+			continue
+		}
+
+		if row.Status != "unchanged" {
+			rows[i].Summary = row.Summary + "; " + preview.Reason
+			continue
+		}
+
+		rows[i].Status = "conditional"
+		if preview.Skipped {
+			rows[i].Status = "skipped"
+		}
+		rows[i].Summary = preview.Reason
+	}
+	return rows
 }
 
 func filterChangedRows(rows []diffRow) []diffRow {
@@ -139,6 +193,7 @@ func summarizeTypedStep(step *types.Step) diffStepSummary {
 		ID:   step.ID,
 		Type: string(step.Type),
 		Skip: step.Skip,
+		When: step.When,
 	}
 	if step.With != nil {
 		summary.With = cloneStringMap(*step.With)
@@ -161,6 +216,9 @@ func summarizeMapStep(step map[string]any) diffStepSummary {
 	if skip, ok := step["skip"].(bool); ok {
 		summary.Skip = skip
 	}
+	if when, ok := step["when"].(string); ok {
+		summary.When = when
+	}
 	if with := asStringMap(step["with"]); with != nil {
 		summary.With = with
 	}
@@ -403,6 +461,9 @@ func summarizeStepDiff(left, right diffStepSummary, status string) string {
 				parts = append(parts, "skip disabled")
 			}
 		}
+		if left.When != right.When {
+			parts = append(parts, fmt.Sprintf("when changed %s -> %s", quoteOrDash(left.When), quoteOrDash(right.When)))
+		}
 		if changes := diffValues("with", left.With, right.With, 0); len(changes) > 0 {
 			parts = append(parts, changes...)
 		}
@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 
 	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
 	"github.com/krateoplatformops/krateoctl/internal/install/state"
 	"github.com/krateoplatformops/krateoctl/internal/subcommands"
 	"github.com/krateoplatformops/krateoctl/internal/util/kube"
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
 	"gopkg.in/yaml.v3"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/rest"
@@ -31,14 +34,16 @@ type planCmd struct {
 	installType    string
 	diffInstalled  bool
 	diffFormat     string
-	output         bool
+	output         planOutput
 	version        string
 	repository     string
 	debug          bool
 	skipValidation bool
+	autoEnable     bool
 	restConfigFn   restConfigProvider
 	stateFactory   stateStoreFactory
 	stateName      string
+	out            io.Writer // Receives the configuration or the report printed by --output (default os.Stdout)
 }
 
 func (c *planCmd) Name() string     { return "plan" }
@@ -65,14 +70,19 @@ func (c *planCmd) Usage() string {
 	fmt.Fprint(&wri, "  --type string\n")
 	fmt.Fprint(&wri, "        choose which file variant to use. Supported values: nodeport, loadbalancer, ingress. For example, nodeport looks for krateo.nodeport.yaml and files like pre-upgrade.nodeport.yaml. (default \"nodeport\")\n")
 	fmt.Fprint(&wri, "  --diff-installed\n")
-	fmt.Fprint(&wri, "        compare computed plan against the stored installation snapshot\n")
+	fmt.Fprint(&wri, "        compare computed plan against the stored installation snapshot and list the\n")
+	fmt.Fprint(&wri, "        steps whose resources 'install apply --prune' would remove\n")
 	fmt.Fprint(&wri, "  --diff-format string\n")
 	fmt.Fprint(&wri, "        choose how diffs are rendered: unified (default) or table\n")
 	fmt.Fprint(&wri, "        table shows a step-by-step summary for the compared plan\n")
 	fmt.Fprint(&wri, "  --output\n")
 	fmt.Fprint(&wri, "        output computed plan steps as multi-document YAML to stdout\n")
+	fmt.Fprint(&wri, "  --output=json|yaml\n")
+	fmt.Fprint(&wri, "        print a machine-readable report of the planned steps to stdout instead\n")
 	fmt.Fprint(&wri, "  --skip-validation\n")
 	fmt.Fprint(&wri, "        skip configuration validation (useful for emergency recovery)\n")
+	fmt.Fprint(&wri, "  --auto-enable-dependencies\n")
+	fmt.Fprint(&wri, "        enable the components required by an enabled component\n")
 	fmt.Fprint(&wri, "  --debug\n")
 	fmt.Fprint(&wri, "        enable debug-level logging (can also use KRATEOCTL_DEBUG env var)\n\n")
 
@@ -84,8 +94,11 @@ func (c *planCmd) Usage() string {
 	fmt.Fprint(&wri, "  - Main config is read from krateo.yaml (overridable with --config in local mode).\n")
 	fmt.Fprint(&wri, "  - Overrides are loaded from krateo-overrides.yaml and, when --profile is set, from\n")
 	fmt.Fprint(&wri, "    profile-specific files like krateo-overrides.<profile>.yaml.\n")
+	fmt.Fprint(&wri, "  - A profile can build on others with extends: [base-profile]; they are applied first.\n")
 	fmt.Fprint(&wri, "  - Components and steps are filtered according to the active profile; disabled steps\n")
 	fmt.Fprint(&wri, "    are still shown but include 'skip: true' in the output.\n")
+	fmt.Fprint(&wri, "  - Steps with a 'when' condition are evaluated against the var steps whose value is\n")
+	fmt.Fprint(&wri, "    known offline; the diff reports them as skipped or conditional, with the reason.\n")
 	fmt.Fprint(&wri, "  - Type-specific files such as pre-upgrade.nodeport.yaml are used first.\n")
 	fmt.Fprint(&wri, "    If no type-specific file exists, the generic file pre-upgrade.yaml is used.\n")
 	fmt.Fprint(&wri, "  - When --output is set, computed steps are written as a stream of YAML documents,\n")
@@ -106,6 +119,8 @@ func (c *planCmd) Usage() string {
 	fmt.Fprint(&wri, "  krateoctl install plan --config ./krateo.yaml --type loadbalancer\n\n")
 	fmt.Fprint(&wri, "  # Preview using ingress-specific files such as krateo.ingress.yaml\n")
 	fmt.Fprint(&wri, "  krateoctl install plan --config ./krateo.yaml --type ingress\n\n")
+	fmt.Fprint(&wri, "  # Print the planned steps, and the ones that would be pruned, as JSON\n")
+	fmt.Fprint(&wri, "  krateoctl install plan --diff-installed --output=json\n\n")
 
 	return wri.String()
 }
@@ -119,8 +134,9 @@ func (c *planCmd) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&c.installType, "type", "nodeport", "choose which file variant to use: nodeport, loadbalancer, or ingress")
 	f.BoolVar(&c.diffInstalled, "diff-installed", false, "compare the computed plan with the stored installation snapshot")
 	f.StringVar(&c.diffFormat, "diff-format", "unified", "diff rendering mode: unified or table")
-	f.BoolVar(&c.output, "output", false, "output computed plan steps as multi-document YAML")
+	f.Var(&c.output, "output", "output computed plan steps as multi-document YAML, or a report with --output=json|yaml")
 	f.BoolVar(&c.skipValidation, "skip-validation", false, "skip configuration validation")
+	f.BoolVar(&c.autoEnable, "auto-enable-dependencies", false, "enable the components required by an enabled component")
 	f.BoolVar(&c.debug, "debug", false, "enable debug-level logging")
 }
 
@@ -131,6 +147,9 @@ func (c *planCmd) ensureDeps() {
 	if c.stateFactory == nil {
 		c.stateFactory = shared.DefaultStateStoreFactory
 	}
+	if c.out == nil {
+		c.out = os.Stdout
+	}
 	c.namespace = shared.EnsureNamespace(c.namespace)
 	c.stateName = shared.EnsureStateName(c.stateName)
 }
@@ -142,12 +161,13 @@ func (c *planCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{
 	l := shared.NewLogger(os.Stderr, c.debug || os.Getenv(shared.KRATEOCTL_DEBUG_ENV) != "")
 
 	result, err := shared.LoadConfigAndSteps(shared.NewLoadOptions(shared.LoadOptionsInput{
-		ConfigFile:       c.configFile,
-		Namespace:        c.namespace,
-		Profile:          c.profile,
-		Version:          c.version,
-		Repository:       c.repository,
-		InstallationType: c.installType,
+		ConfigFile:             c.configFile,
+		Namespace:              c.namespace,
+		Profile:                c.profile,
+		Version:                c.version,
+		Repository:             c.repository,
+		InstallationType:       c.installType,
+		AutoEnableDependencies: c.autoEnable,
 	}), c.namespace, l.Info, c.skipValidation)
 	if err != nil {
 		l.Error("Failed to load configuration: %v", err)
@@ -171,20 +191,28 @@ func (c *planCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{
 		return subcommands.ExitFailure
 	}
 
-	boriginalSteps, err := yaml.Marshal(result.OriginalSteps)
+	conditions := workflows.PreviewConditions(ctx, steps)
+
+	// The diffs never print sensitive values, the snapshot is already redacted.
+	originalSteps := types.RedactSteps(result.OriginalSteps)
+	redactedSteps := types.RedactSteps(steps)
+
+	boriginalSteps, err := yaml.Marshal(originalSteps)
 	if err != nil {
 		l.Error("✗ Failed to marshal original steps: %v", err)
 		return subcommands.ExitFailure
 	}
 
-	bSteps, err := yaml.Marshal(steps)
+	bSteps, err := yaml.Marshal(redactedSteps)
 	if err != nil {
 		l.Error("✗ Failed to marshal steps: %v", err)
 		return subcommands.ExitFailure
 	}
 
+	var installed *state.Snapshot
+
 	// Output the computed configuration as YAML if requested
-	if c.output {
+	if c.output.config {
 		// Output the original configuration file
 		if c.configFile != "" {
 			configFile, err := os.Open(c.configFile)
@@ -194,7 +222,7 @@ func (c *planCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{
 			}
 			defer configFile.Close()
 
-			if _, err := io.Copy(os.Stdout, configFile); err != nil {
+			if _, err := io.Copy(c.out, configFile); err != nil {
 				l.Error("✗ Failed to output config file: %v", err)
 				return subcommands.ExitFailure
 			}
@@ -214,9 +242,10 @@ func (c *planCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{
 				return subcommands.ExitFailure
 			}
 
-			installed, err := store.Load(ctx, c.stateName)
+			installed, err = store.Load(ctx, c.stateName)
 			switch {
 			case apierrors.IsNotFound(err):
+				installed = nil
 				l.Info("ℹ Installation snapshot %q not found in namespace %q", c.stateName, c.namespace)
 			case err != nil:
 				l.Error("Failed to read installation snapshot: %v", err)
@@ -234,18 +263,93 @@ func (c *planCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{
 					return subcommands.ExitFailure
 				}
 
-				if err := c.renderDiff(l, os.Stderr, "installed", installedBytes, "plan", planBytes, installed, snapshot); err != nil {
+				if err := c.renderDiff(l, os.Stderr, "installed", installedBytes, "plan", planBytes, installed, snapshot, conditions); err != nil {
 					l.Error("%v", err)
 					return subcommands.ExitFailure
 				}
+
+				if err := renderPrune(l, installed, steps); err != nil {
+					l.Error("Failed to compute the steps to prune: %v", err)
+					return subcommands.ExitFailure
+				}
 			}
 		} else {
-			if err := c.renderDiff(l, os.Stderr, "original", boriginalSteps, "computed", bSteps, result.OriginalSteps, steps); err != nil {
+			if err := c.renderDiff(l, os.Stderr, "original", boriginalSteps, "computed", bSteps, originalSteps, redactedSteps, conditions); err != nil {
 				l.Error("%v", err)
 				return subcommands.ExitFailure
 			}
 		}
 	}
 
+	if c.output.format != "" {
+		if err := c.writeReport(version, steps, conditions, installed); err != nil {
+			l.Error("Failed to write the report: %v", err)
+			return subcommands.ExitFailure
+		}
+	}
+
 	return subcommands.ExitSuccess
 }
+
+// writeReport prints the planned steps, with the outcome of their when
+// conditions, and the steps that apply --prune would remove from installed.
+func (c *planCmd) writeReport(version string, steps []*types.Step, conditions map[string]workflows.ConditionPreview, installed *state.Snapshot) error {
+	report := shared.NewReport("plan", c.namespace, version)
+	report.Steps = planReports(steps, conditions)
+
+	pruned, err := state.PruneSteps(installed, steps)
+	if err != nil {
+		return fmt.Errorf("compute the steps to prune: %w", err)
+	}
+	report.Pruned = planReports(pruned, nil)
+
+	return report.Write(c.out, c.output.format, subcommands.ExitSuccess)
+}
+
+func planReports(steps []*types.Step, conditions map[string]workflows.ConditionPreview) []shared.StepReport {
+	out := make([]shared.StepReport, 0, len(steps))
+	for _, step := range steps {
+		rep := shared.StepReport{ID: step.ID, Type: step.Type, State: shared.StatePlanned}
+		preview, guarded := conditions[step.ID]
+		switch {
+		case step.Skip:
+			rep.State = shared.StateSkipped
+		case guarded && preview.Skipped:
+			rep.State = shared.StateSkipped
+			rep.Reason = preview.Reason
+		case guarded:
+			rep.Reason = preview.Reason
+		}
+		out = append(out, rep)
+	}
+	return out
+}
+
+// planOutput is the --output flag. Used alone it prints the configuration, as
+// it always did; --output=json or --output=yaml print the plan report.
+type planOutput struct {
+	config bool
+	format shared.OutputFormat
+}
+
+func (o *planOutput) IsBoolFlag() bool { return true }
+
+func (o *planOutput) String() string {
+	if o.format != "" {
+		return string(o.format)
+	}
+	return strconv.FormatBool(o.config)
+}
+
+func (o *planOutput) Set(value string) error {
+	switch value {
+	case "true":
+		*o = planOutput{config: true}
+		return nil
+	case "false":
+		*o = planOutput{}
+		return nil
+	}
+	*o = planOutput{}
+	return o.format.Set(value)
+}
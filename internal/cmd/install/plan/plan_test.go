@@ -3,12 +3,15 @@ package plan
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
 	"github.com/krateoplatformops/krateoctl/internal/subcommands"
 )
 
@@ -87,6 +90,138 @@ steps:
 	}
 }
 
+func TestPlanExecuteTableDiffConditions(t *testing.T) {
+	configPath := writeTestConfig(t, `componentsDefinition:
+  demo:
+    steps:
+      - ingress-class
+      - install-ingress
+      - install-backend
+steps:
+  - id: ingress-class
+    type: var
+    with:
+      name: INGRESS_CLASS
+      value: ""
+  - id: install-ingress
+    type: chart
+    when: .env.INGRESS_CLASS != ""
+    with:
+      releaseName: ingress
+  - id: install-backend
+    type: chart
+    when: .steps["install-ingress"] == null
+    with:
+      releaseName: backend
+`)
+
+	cmd := &planCmd{
+		configFile: configPath,
+		diffFormat: "table",
+	}
+
+	stderr := captureStderr(t, func() {
+		status := cmd.Execute(context.Background(), flag.NewFlagSet("plan", flag.ContinueOnError))
+		if status != subcommands.ExitSuccess {
+			t.Fatalf("Execute() = %v, want %v", status, subcommands.ExitSuccess)
+		}
+	})
+
+	for _, want := range []string{
+		"install-ingress", "skipped", `when condition ".env.INGRESS_CLASS != \"\"" is false`,
+		"install-backend", "conditional", "evaluated at apply time",
+	} {
+		if !bytes.Contains([]byte(stderr), []byte(want)) {
+			t.Fatalf("table diff output missing %q:\n%s", want, stderr)
+		}
+	}
+}
+
+func TestPlanExecuteReport(t *testing.T) {
+	configPath := writeTestConfig(t, `componentsDefinition:
+  demo:
+    steps:
+      - ingress-class
+      - install-ingress
+      - step-one
+steps:
+  - id: ingress-class
+    type: var
+    with:
+      name: INGRESS_CLASS
+      value: ""
+  - id: install-ingress
+    type: chart
+    when: .env.INGRESS_CLASS != ""
+    with:
+      releaseName: ingress
+  - id: step-one
+    type: chart
+    with:
+      releaseName: demo
+`)
+
+	var out bytes.Buffer
+	cmd := &planCmd{out: &out}
+	fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+	cmd.SetFlags(fs)
+	if err := fs.Parse([]string{"--config", configPath, "--output=json"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if status := cmd.Execute(context.Background(), fs); status != subcommands.ExitSuccess {
+		t.Fatalf("Execute() = %v, want %v", status, subcommands.ExitSuccess)
+	}
+
+	var report shared.Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Unmarshal() error = %v\n%s", err, out.String())
+	}
+	if report.Command != "plan" || report.Status != shared.StateSucceeded {
+		t.Fatalf("report = %+v, want a succeeded plan", report)
+	}
+
+	states := make(map[string]string)
+	for _, step := range report.Steps {
+		states[step.ID] = step.State
+	}
+	want := map[string]string{
+		"ingress-class":   shared.StatePlanned,
+		"install-ingress": shared.StateSkipped,
+		"step-one":        shared.StatePlanned,
+	}
+	if !maps.Equal(states, want) {
+		t.Fatalf("step states = %v, want %v", states, want)
+	}
+}
+
+func TestPlanOutputFlag(t *testing.T) {
+	for _, arg := range []string{"--output", "--output=json", "--output=table"} {
+		t.Run(arg, func(t *testing.T) {
+			cmd := &planCmd{}
+			fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			cmd.SetFlags(fs)
+
+			err := fs.Parse([]string{arg})
+			switch arg {
+			case "--output":
+				if err != nil || !cmd.output.config {
+					t.Fatalf("Parse(%s) = %v, output %+v, want the configuration", arg, err, cmd.output)
+				}
+			case "--output=json":
+				if err != nil || cmd.output.format != shared.OutputJSON {
+					t.Fatalf("Parse(%s) = %v, output %+v, want a json report", arg, err, cmd.output)
+				}
+			default:
+				if err == nil {
+					t.Fatalf("Parse(%s) succeeded, want an error", arg)
+				}
+			}
+		})
+	}
+}
+
 func writeTestConfig(t *testing.T, data string) string {
 	t.Helper()
 
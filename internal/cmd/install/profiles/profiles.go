@@ -0,0 +1,193 @@
+package profiles
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
+	"github.com/krateoplatformops/krateoctl/internal/config"
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+	"github.com/krateoplatformops/krateoctl/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+func Command() subcommands.Command {
+	return &profilesCmd{}
+}
+
+type profilesCmd struct {
+	debug         bool
+	overridesPath string    // krateo-overrides.yaml, next to which the profile files live
+	out           io.Writer // Receives the list and show output (default os.Stdout)
+}
+
+func (c *profilesCmd) Name() string { return "profiles" }
+func (c *profilesCmd) Synopsis() string {
+	return "list profiles and show the overrides they produce"
+}
+
+func (c *profilesCmd) Usage() string {
+	wri := bytes.Buffer{}
+	fmt.Fprintf(&wri, "%s. Profiles are read from the krateo-overrides.<profile>.yaml files and the profiles section of krateo-overrides.yaml.\n\n", c.Synopsis())
+
+	fmt.Fprint(&wri, "USAGE:\n\n")
+	fmt.Fprint(&wri, "  krateoctl install profiles list [FLAGS]\n")
+	fmt.Fprint(&wri, "  krateoctl install profiles show <name> [FLAGS]\n\n")
+
+	fmt.Fprint(&wri, "FLAGS:\n\n")
+	fmt.Fprint(&wri, "  --debug\n")
+	fmt.Fprint(&wri, "        enable debug-level logging (can also use KRATEOCTL_DEBUG env var)\n\n")
+
+	fmt.Fprint(&wri, "NOTES:\n\n")
+	fmt.Fprint(&wri, "  - A profile builds on other profiles with extends: [base-profile]. The profiles it\n")
+	fmt.Fprint(&wri, "    extends are applied first, transitively.\n")
+	fmt.Fprint(&wri, "  - show prints the merged overrides of the profile and, next to every value, the file\n")
+	fmt.Fprint(&wri, "    and the profile that set it. The top-level keys of krateo-overrides.yaml are still\n")
+	fmt.Fprint(&wri, "    applied after the profile.\n\n")
+
+	fmt.Fprint(&wri, "EXAMPLES:\n\n")
+	fmt.Fprint(&wri, "  # List the profiles\n")
+	fmt.Fprint(&wri, "  krateoctl install profiles list\n\n")
+	fmt.Fprint(&wri, "  # Show what the prod-eu profile overrides\n")
+	fmt.Fprint(&wri, "  krateoctl install profiles show prod-eu\n\n")
+
+	return wri.String()
+}
+
+func (c *profilesCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.debug, "debug", false, "enable debug-level logging")
+}
+
+func (c *profilesCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	if c.overridesPath == "" {
+		c.overridesPath = shared.DefaultOverridesPath
+	}
+	if c.out == nil {
+		c.out = os.Stdout
+	}
+
+	l := shared.NewLogger(os.Stderr, c.debug || os.Getenv(shared.KRATEOCTL_DEBUG_ENV) != "")
+	loader := config.NewLoader(config.LoadOptions{UserOverridesPath: c.overridesPath})
+
+	args := fs.Args()
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, c.Usage())
+		return subcommands.ExitUsageError
+	}
+
+	switch action, args := args[0], args[1:]; {
+	case action == "list" && len(args) == 0:
+		return c.list(l, loader)
+	case action == "show" && len(args) == 1:
+		return c.show(l, loader, args[0])
+	case action == "list":
+		l.Error("list takes no arguments")
+		return subcommands.ExitUsageError
+	case action == "show":
+		l.Error("show takes the name of a profile")
+		return subcommands.ExitUsageError
+	default:
+		l.Error("unknown profiles action %q (expected: list|show)", action)
+		return subcommands.ExitUsageError
+	}
+}
+
+func (c *profilesCmd) list(l *ui.Logger, loader *config.Loader) subcommands.ExitStatus {
+	profiles, err := loader.Profiles()
+	if err != nil {
+		l.Error("Failed to load profiles: %v", err)
+		return subcommands.ExitFailure
+	}
+	if len(profiles) == 0 {
+		l.Info("ℹ No profiles defined next to %s", c.overridesPath)
+		return subcommands.ExitSuccess
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tEXTENDS\tSOURCE")
+	for _, p := range profiles {
+		extends := strings.Join(p.Extends, ", ")
+		if extends == "" {
+			extends = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, extends, strings.Join(p.Paths, ", "))
+	}
+	if err := w.Flush(); err != nil {
+		l.Error("Failed to print the profiles: %v", err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+func (c *profilesCmd) show(l *ui.Logger, loader *config.Loader, name string) subcommands.ExitStatus {
+	sources, err := loader.ProfileSources(name)
+	if err != nil {
+		l.Error("Failed to resolve profile %s: %v", name, err)
+		return subcommands.ExitFailure
+	}
+
+	merged, provenance, err := config.MergeSources(sources)
+	if err != nil {
+		l.Error("Failed to merge profile %s: %v", name, err)
+		return subcommands.ExitFailure
+	}
+
+	var node yaml.Node
+	if err := node.Encode(merged); err != nil {
+		l.Error("Failed to encode profile %s: %v", name, err)
+		return subcommands.ExitFailure
+	}
+	annotate(&node, nil, provenance)
+
+	enc := yaml.NewEncoder(c.out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		l.Error("Failed to print profile %s: %v", name, err)
+		return subcommands.ExitFailure
+	}
+	if err := enc.Close(); err != nil {
+		l.Error("Failed to print profile %s: %v", name, err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+// annotate sorts the keys of the mappings under node and comments every value
+// that is not a mapping with the source that set it.
+func annotate(node *yaml.Node, path []string, provenance config.Provenance) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	pairs := make(map[string][2]*yaml.Node, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs[node.Content[i].Value] = [2]*yaml.Node{node.Content[i], node.Content[i+1]}
+	}
+
+	node.Content = node.Content[:0]
+	for _, key := range slices.Sorted(maps.Keys(pairs)) {
+		k, v := pairs[key][0], pairs[key][1]
+		keys := append(slices.Clone(path), key)
+		if v.Kind == yaml.MappingNode {
+			annotate(v, keys, provenance)
+		} else if src := provenance.Of(keys...); src != nil {
+			k.LineComment = "from " + sourceName(src)
+		}
+		node.Content = append(node.Content, k, v)
+	}
+}
+
+func sourceName(src *config.Source) string {
+	if src.InFile {
+		return fmt.Sprintf("%s (profiles.%s)", src.Path, src.Profile)
+	}
+	return fmt.Sprintf("%s (profile %s)", src.Path, src.Profile)
+}
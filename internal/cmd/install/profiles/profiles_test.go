@@ -0,0 +1,107 @@
+package profiles
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+)
+
+const testOverrides = `components:
+  authn:
+    enabled: true
+profiles:
+  prod-eu:
+    extends: [prod]
+    components:
+      finops:
+        enabled: true
+`
+
+const testProdProfile = `components:
+  authn:
+    enabled: true
+  frontend:
+    enabled: false
+`
+
+func newTestCmd(t *testing.T) (*profilesCmd, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "krateo-overrides.yaml"), testOverrides)
+	writeFile(t, filepath.Join(dir, "krateo-overrides.prod.yaml"), testProdProfile)
+
+	return &profilesCmd{
+		overridesPath: filepath.Join(dir, "krateo-overrides.yaml"),
+		out:           &bytes.Buffer{},
+	}, dir
+}
+
+func run(t *testing.T, cmd *profilesCmd, args ...string) subcommands.ExitStatus {
+	t.Helper()
+
+	fs := flag.NewFlagSet("profiles", flag.ContinueOnError)
+	cmd.SetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return cmd.Execute(context.Background(), fs)
+}
+
+func TestProfilesList(t *testing.T) {
+	cmd, dir := newTestCmd(t)
+
+	if status := run(t, cmd, "list"); status != subcommands.ExitSuccess {
+		t.Fatalf("Execute() = %v, want success", status)
+	}
+
+	out := cmd.out.(*bytes.Buffer).String()
+	for _, want := range []string{
+		"prod     -        " + filepath.Join(dir, "krateo-overrides.prod.yaml"),
+		"prod-eu  prod     " + filepath.Join(dir, "krateo-overrides.yaml"),
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("list output =\n%s\nwant a line containing %q", out, want)
+		}
+	}
+}
+
+func TestProfilesShow(t *testing.T) {
+	cmd, dir := newTestCmd(t)
+
+	if status := run(t, cmd, "show", "prod-eu"); status != subcommands.ExitSuccess {
+		t.Fatalf("Execute() = %v, want success", status)
+	}
+
+	out := cmd.out.(*bytes.Buffer).String()
+	for _, want := range []string{
+		"enabled: true # from " + filepath.Join(dir, "krateo-overrides.prod.yaml") + " (profile prod)",
+		"enabled: false # from " + filepath.Join(dir, "krateo-overrides.prod.yaml") + " (profile prod)",
+		"enabled: true # from " + filepath.Join(dir, "krateo-overrides.yaml") + " (profiles.prod-eu)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("show output =\n%s\nwant a line containing %q", out, want)
+		}
+	}
+	if strings.Contains(out, "extends") {
+		t.Fatalf("show output =\n%s\nwant no extends", out)
+	}
+
+	if status := run(t, cmd, "show", "missing"); status != subcommands.ExitFailure {
+		t.Fatalf("Execute(show missing) = %v, want failure", status)
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
@@ -28,17 +28,20 @@ type LoadOptionsInput struct {
 	Version          string
 	Repository       string
 	InstallationType string
+	// AutoEnableDependencies enables the components required by the enabled ones.
+	AutoEnableDependencies bool
 }
 
 func NewLoadOptions(input LoadOptionsInput) config.LoadOptions {
 	return config.LoadOptions{
-		ConfigPath:        input.ConfigFile,
-		Namespace:         input.Namespace,
-		UserOverridesPath: DefaultOverridesPath,
-		Profile:           input.Profile,
-		Version:           input.Version,
-		Repository:        input.Repository,
-		InstallationType:  input.InstallationType,
+		ConfigPath:             input.ConfigFile,
+		Namespace:              input.Namespace,
+		UserOverridesPath:      DefaultOverridesPath,
+		Profile:                input.Profile,
+		Version:                input.Version,
+		Repository:             input.Repository,
+		InstallationType:       input.InstallationType,
+		AutoEnableDependencies: input.AutoEnableDependencies,
 	}
 }
 
@@ -2,6 +2,8 @@ package shared
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/krateoplatformops/krateoctl/internal/config"
@@ -37,17 +39,22 @@ func LoadConfigAndSteps(opts config.LoadOptions, namespace string, logger func(s
 		return nil, fmt.Errorf("Failed to load configuration: %w", err)
 	}
 
-	return BuildLoadResult(data, namespace, logger, skipValidation)
+	return buildLoadResult(data, namespace, logger, skipValidation, opts.AutoEnableDependencies)
 }
 
 // BuildLoadResult validates raw configuration data and resolves the active steps.
 func BuildLoadResult(data map[string]any, namespace string, logger func(string, ...any), skipValidation bool) (*LoadResult, error) {
+	return buildLoadResult(data, namespace, logger, skipValidation, false)
+}
+
+func buildLoadResult(data map[string]any, namespace string, logger func(string, ...any), skipValidation, autoEnable bool) (*LoadResult, error) {
 	applyNamespaceTemplate(data, namespace)
 
 	cfg, err := config.NewConfig(data)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to build configuration: %w", err)
 	}
+	cfg.WithAutoEnableDependencies(autoEnable)
 
 	if !skipValidation {
 		validator := config.NewValidator(cfg)
@@ -59,6 +66,10 @@ func BuildLoadResult(data map[string]any, namespace string, logger func(string,
 		}
 	}
 
+	if logger != nil {
+		logComponentReasons(cfg, logger)
+	}
+
 	steps, err := cfg.GetActiveSteps()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get steps: %w", err)
@@ -76,6 +87,20 @@ func BuildLoadResult(data map[string]any, namespace string, logger func(string,
 	}, nil
 }
 
+// logComponentReasons reports the components enabled or disabled by the
+// requires of another component.
+func logComponentReasons(cfg *config.Config, logger func(string, ...any)) {
+	states, err := cfg.GetEnabledComponents()
+	if err != nil {
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(states)) {
+		if reason := states[name].Reason; reason != "" {
+			logger("ℹ Component %s %s", name, reason)
+		}
+	}
+}
+
 func applyNamespaceTemplate(value any, namespace string) any {
 	if namespace == "" {
 		return value
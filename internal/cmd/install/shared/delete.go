@@ -0,0 +1,63 @@
+package shared
+
+import (
+	"context"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	"k8s.io/client-go/rest"
+)
+
+type DeleteWorkflowResult struct {
+	// Steps are the steps that were run, in the order of Results.
+	Steps   []*types.Step
+	Results []workflows.StepResult[any]
+}
+
+// ResolveVars runs the var steps of list, and nothing else, so that the steps
+// run in delete mode can expand the same ${VAR} placeholders they were applied
+// with. A failed var step is reported through the error while the variables
+// resolved so far are still returned.
+func ResolveVars(ctx context.Context, rc *rest.Config, opts ExecuteWorkflowOptions, deps WorkflowDeps, list []*types.Step) (map[string]string, error) {
+	vars := types.Subset(list, func(step *types.Step) bool {
+		return step.Type == types.TypeVar && !step.Skip
+	})
+	if len(vars) == 0 {
+		return nil, nil
+	}
+
+	wf, err := newWorkflow(rc, opts, deps, workflows.Opts{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := wf.Run(ctx, &types.Workflow{Steps: vars}, nil, nil)
+	return workflows.RunVars(results), deps.ErrEvaluator(results)
+}
+
+// DeleteWorkflow runs the steps of list through the delete path of their
+// handlers: objects and manifests are deleted and chart releases uninstalled,
+// in reverse dependency order. Var steps are left out, their values are taken
+// from vars (see ResolveVars), as are the skipped steps.
+func DeleteWorkflow(ctx context.Context, rc *rest.Config, opts ExecuteWorkflowOptions, deps WorkflowDeps, list []*types.Step, vars map[string]string) (*DeleteWorkflowResult, error) {
+	list = types.Subset(list, func(step *types.Step) bool {
+		return step.Type != types.TypeVar && !step.Skip
+	})
+	if len(list) == 0 {
+		return &DeleteWorkflowResult{}, nil
+	}
+
+	wf, err := newWorkflow(rc, opts, deps, workflows.Opts{
+		Op:   steps.Delete,
+		Vars: vars,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Run reverses the steps in place, so list matches the results afterwards.
+	results := wf.Run(ctx, &types.Workflow{Steps: list}, nil, opts.ProgressReporter)
+
+	return &DeleteWorkflowResult{Steps: list, Results: results}, deps.ErrEvaluator(results)
+}
@@ -0,0 +1,119 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/ui"
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+)
+
+// DryRunSummary counts the objects and releases of a dry run by change. Steps
+// that report no change, such as var or wait steps, are not counted.
+type DryRunSummary struct {
+	Create     int
+	Update     int
+	Unchanged  int
+	Delete     int
+	Unverified int
+	Rejected   int
+}
+
+func (s DryRunSummary) String() string {
+	return fmt.Sprintf("%d to create, %d to update, %d unchanged, %d to delete, %d unverified, %d rejected",
+		s.Create, s.Update, s.Unchanged, s.Delete, s.Unverified, s.Rejected)
+}
+
+// Merge adds the counts of o, e.g. the ones of the prune run, to s.
+func (s *DryRunSummary) Merge(o DryRunSummary) {
+	s.Create += o.Create
+	s.Update += o.Update
+	s.Unchanged += o.Unchanged
+	s.Delete += o.Delete
+	s.Unverified += o.Unverified
+	s.Rejected += o.Rejected
+}
+
+func (s *DryRunSummary) add(change string) {
+	switch change {
+	case string(applier.ChangeCreate):
+		s.Create++
+	case string(applier.ChangeUpdate):
+		s.Update++
+	case string(applier.ChangeUnchanged):
+		s.Unchanged++
+	case steps.ChangeDelete:
+		s.Delete++
+	case steps.ChangeUnverified:
+		s.Unverified++
+	}
+}
+
+// LogDryRunResults reports, step by step, what a run in dry-run mode would
+// create, change or delete, and the steps rejected by the API server.
+func LogDryRunResults(logger *ui.Logger, list []*types.Step, results []workflows.StepResult[any]) DryRunSummary {
+	var summary DryRunSummary
+	for i, step := range list {
+		res := results[i]
+		switch {
+		case res.ID() == "":
+			logger.Info("[PEND] %s (%s) not checked", step.ID, step.Type)
+		case step.Skip:
+			logger.Info("[SKIP] %s (%s)", step.ID, step.Type)
+		case res.SkipReason() != "":
+			logger.Info("[SKIP] %s (%s): %s", step.ID, step.Type, res.SkipReason())
+		case res.Err() != nil:
+			summary.Rejected++
+			logger.Error("[REJECTED] %s (%s): %v", step.ID, step.Type, res.Err())
+		default:
+			logDryRunResult(logger, &summary, step, res.Result())
+		}
+	}
+
+	return summary
+}
+
+func logDryRunResult(logger *ui.Logger, summary *DryRunSummary, step *types.Step, result any) {
+	switch res := result.(type) {
+	case *steps.ObjectResult:
+		if res == nil || res.Change == "" {
+			break
+		}
+		summary.add(res.Change)
+		logger.Info("%s %s (%s) %s %s", changeTag(res.Change), step.ID, step.Type, res.Kind, objectRef(res))
+		return
+	case *steps.ChartResult:
+		if res == nil || res.Change == "" {
+			break
+		}
+		summary.add(res.Change)
+		logger.Info("%s %s (%s) release %s/%s", changeTag(res.Change), step.ID, step.Type, res.Namespace, res.ReleaseName)
+		return
+	case *steps.ManifestsResult:
+		if res == nil {
+			break
+		}
+		logger.Info("✓ %s (%s) %d objects", step.ID, step.Type, len(res.Objects))
+		for _, obj := range res.Objects {
+			summary.add(obj.Change)
+			logger.Info("    %s %s %s", changeTag(obj.Change), obj.Kind, objectRef(&obj))
+		}
+		return
+	}
+
+	logger.Info("✓ %s (%s)", step.ID, step.Type)
+}
+
+func changeTag(change string) string {
+	return "[" + strings.ToUpper(change) + "]"
+}
+
+func objectRef(obj *steps.ObjectResult) string {
+	if obj.Namespace == "" {
+		return obj.Name
+	}
+	return obj.Namespace + "/" + obj.Name
+}
@@ -0,0 +1,44 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krateoplatformops/krateoctl/internal/install/state"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+)
+
+// LoadPruneSteps returns the steps of the stored installation snapshot that the
+// new plan no longer manages (see state.PruneSteps). It is empty when no
+// snapshot has been stored yet.
+func LoadPruneSteps(ctx context.Context, store state.Store, name string, steps []*types.Step) ([]*types.Step, error) {
+	snapshot, err := store.Load(ctx, name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load installation snapshot: %w", err)
+	}
+
+	return state.PruneSteps(snapshot, steps)
+}
+
+// PruneWorkflow deletes the objects and uninstalls the releases of the steps
+// removed from the configuration since the stored snapshot, or disabled with
+// their component (see DeleteWorkflow). vars holds the variables set by the
+// apply run (see workflows.RunVars).
+func PruneWorkflow(ctx context.Context, rc *rest.Config, opts ExecuteWorkflowOptions, deps WorkflowDeps, vars map[string]string) (*DeleteWorkflowResult, error) {
+	store, err := deps.StateFactory(rc, opts.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("initialize installation state store: %w", err)
+	}
+
+	list, err := LoadPruneSteps(ctx, store, opts.StateName, opts.Result.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeleteWorkflow(ctx, rc, opts, deps, list, vars)
+}
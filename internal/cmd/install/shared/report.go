@@ -0,0 +1,191 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+	"github.com/krateoplatformops/krateoctl/internal/ui"
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	"sigs.k8s.io/yaml"
+)
+
+// OutputFormat selects the machine-readable report written to stdout by the
+// --output flag. The zero value disables the report.
+type OutputFormat string
+
+const (
+	OutputJSON OutputFormat = "json"
+	OutputYAML OutputFormat = "yaml"
+)
+
+func (f *OutputFormat) String() string {
+	return string(*f)
+}
+
+func (f *OutputFormat) Set(value string) error {
+	switch OutputFormat(value) {
+	case OutputJSON, OutputYAML:
+		*f = OutputFormat(value)
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q: use json or yaml", value)
+}
+
+// Step states of a Report.
+const (
+	StateSucceeded   = "succeeded"
+	StateFailed      = "failed"
+	StateInterrupted = "interrupted"
+	StateSkipped     = "skipped"
+	StateResumed     = "resumed"
+	StatePending     = "pending"
+	StatePlanned     = "planned"
+)
+
+// Report is the machine-readable outcome of an install command, meant for CI
+// pipelines that would otherwise scrape the log lines.
+type Report struct {
+	Command   string `json:"command"`
+	Namespace string `json:"namespace"`
+	Version   string `json:"version,omitempty"`
+	DryRun    bool   `json:"dryRun,omitempty"`
+	// Status is succeeded or failed; Error holds the failure that stopped the command.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Phases are the stages run around the workflow, such as the lifecycle manifests.
+	Phases []PhaseReport `json:"phases,omitempty"`
+	Steps  []StepReport  `json:"steps"`
+	// Pruned are the steps removed from the configuration and run in delete mode.
+	Pruned []StepReport `json:"pruned,omitempty"`
+	// Snapshot is the name of the installation snapshot saved by the command.
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+type PhaseReport struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+type StepReport struct {
+	ID       string         `json:"id"`
+	Type     types.StepType `json:"type"`
+	State    string         `json:"state"`
+	Reason   string         `json:"reason,omitempty"`
+	Duration string         `json:"duration,omitempty"`
+	Attempts int            `json:"attempts,omitempty"`
+	Branch   []string       `json:"branch,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	// Result is the typed result of the step handler, e.g. a steps.ChartResult.
+	Result any `json:"result,omitempty"`
+}
+
+func NewReport(command, namespace, version string) *Report {
+	return &Report{
+		Command:   command,
+		Namespace: namespace,
+		Version:   version,
+		Steps:     []StepReport{},
+	}
+}
+
+// Fail logs the error and records it as the cause of the failure of the command.
+func (r *Report) Fail(l *ui.Logger, format string, args ...any) subcommands.ExitStatus {
+	msg := fmt.Sprintf(format, args...)
+	l.Error("%s", msg)
+	r.Error = msg
+	return subcommands.ExitFailure
+}
+
+// Phase records the outcome of a stage and returns err unchanged.
+func (r *Report) Phase(name string, err error) error {
+	phase := PhaseReport{Name: name, State: StateSucceeded}
+	if err != nil {
+		phase.State = StateFailed
+		phase.Error = err.Error()
+	}
+	r.Phases = append(r.Phases, phase)
+	return err
+}
+
+// Write sets the final status and prints the report on w in the given format.
+func (r *Report) Write(w io.Writer, format OutputFormat, status subcommands.ExitStatus) error {
+	r.Status = StateSucceeded
+	if status != subcommands.ExitSuccess {
+		r.Status = StateFailed
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case OutputYAML:
+		data, err = yaml.Marshal(r)
+	default:
+		data, err = json.MarshalIndent(r, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("encode report: %w", err)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// StepReports describes the outcome of every step of a workflow run, in the
+// order of list (see LogWorkflowResults).
+func StepReports(list []*types.Step, results []workflows.StepResult[any]) []StepReport {
+	out := make([]StepReport, 0, len(list))
+	for i, step := range list {
+		res := results[i]
+		rep := StepReport{ID: step.ID, Type: step.Type}
+		switch {
+		case res.ID() == "":
+			rep.State = StatePending
+		case step.Skip:
+			rep.State = StateSkipped
+		case res.Resumed():
+			rep.State = StateResumed
+			rep.Result = typedResult(res.Result())
+		case res.SkipReason() != "":
+			rep.State = StateSkipped
+			rep.Reason = res.SkipReason()
+		case res.Err() != nil:
+			rep.State = StateFailed
+			if res.Interrupted() {
+				rep.State = StateInterrupted
+			}
+			rep.Error = res.Err().Error()
+			rep.Result = typedResult(res.Result())
+			if branch := res.Branch(); len(branch) > 1 {
+				rep.Branch = branch
+			}
+		default:
+			rep.State = StateSucceeded
+			rep.Result = typedResult(res.Result())
+		}
+		if d := res.Duration(); d > 0 {
+			rep.Duration = d.Round(time.Millisecond).String()
+			rep.Attempts = res.Attempts()
+		}
+		out = append(out, rep)
+	}
+	return out
+}
+
+// typedResult drops the typed nil results returned by failed handlers.
+// Sensitive var values are masked (see steps.Redact).
+func typedResult(v any) any {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+	return steps.Redact(v)
+}
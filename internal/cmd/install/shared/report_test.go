@@ -0,0 +1,76 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	"sigs.k8s.io/yaml"
+)
+
+func TestOutputFormatSet(t *testing.T) {
+	var f OutputFormat
+	if err := f.Set("yaml"); err != nil || f != OutputYAML {
+		t.Fatalf("Set(yaml) = %v, format %q", err, f)
+	}
+	if err := f.Set("table"); err == nil {
+		t.Fatalf("Set(table) succeeded, want an error")
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	report := NewReport("apply", "krateo-system", "2.7.0")
+	report.Phase("pre-upgrade", nil)
+	report.Phase("post-upgrade", errors.New("job failed"))
+	report.Steps = StepReports([]*types.Step{
+		{ID: "install-authn", Type: types.TypeChart},
+	}, make([]workflows.StepResult[any], 1))
+	report.Snapshot = "krateo"
+
+	for _, format := range []OutputFormat{OutputJSON, OutputYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := report.Write(&buf, format, subcommands.ExitFailure); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			var got Report
+			data := buf.Bytes()
+			if format == OutputYAML {
+				var err error
+				if data, err = yaml.YAMLToJSON(data); err != nil {
+					t.Fatalf("YAMLToJSON() error = %v", err)
+				}
+			}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v\n%s", err, buf.String())
+			}
+
+			if got.Status != StateFailed || got.Snapshot != "krateo" || got.Version != "2.7.0" {
+				t.Fatalf("report = %+v, want a failed run of 2.7.0 saving krateo", got)
+			}
+			if len(got.Phases) != 2 || got.Phases[1].State != StateFailed || got.Phases[1].Error != "job failed" {
+				t.Fatalf("phases = %+v, want post-upgrade to fail", got.Phases)
+			}
+			if len(got.Steps) != 1 || got.Steps[0].State != StatePending || got.Steps[0].Type != types.TypeChart {
+				t.Fatalf("steps = %+v, want install-authn pending", got.Steps)
+			}
+		})
+	}
+}
+
+func TestTypedResultRedactsSensitiveValues(t *testing.T) {
+	got := typedResult(&steps.VarResult{Name: "DB_PASSWORD", Value: "s3cr3t", Sensitive: true})
+	if v := got.(*steps.VarResult); v.Value != redact.Mask {
+		t.Fatalf("typedResult() value = %q, want %q", v.Value, redact.Mask)
+	}
+	if got := typedResult((*steps.VarResult)(nil)); got != nil {
+		t.Fatalf("typedResult(nil) = %v, want nil", got)
+	}
+}
@@ -2,7 +2,11 @@ package shared
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/deletor"
@@ -10,7 +14,10 @@ import (
 	"github.com/krateoplatformops/krateoctl/internal/install/state"
 	"github.com/krateoplatformops/krateoctl/internal/ui"
 	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	charthandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/chart"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 )
 
@@ -40,8 +47,14 @@ type ExecuteWorkflowOptions struct {
 	Logger           *ui.Logger
 	Result           *LoadResult
 	ProgressReporter workflows.StepNotifier
+	StepProgress     func(id, message string) // Status updates from long running steps (e.g. wait)
 	SaveState        bool
 	Version          string // Installation version (e.g., from --version flag or "local" for --config)
+	Parallelism      int    // Maximum number of steps executed concurrently (default 1)
+	Checkpoint       bool   // Record per-step progress in the installation state
+	Resume           bool   // Skip the steps completed by the previous run (requires Checkpoint)
+	Atomic           bool   // Roll back failed chart upgrades and uninstall failed chart installs
+	DryRun           bool   // Validate the steps against the API server without persisting anything
 }
 
 type ExecuteWorkflowResult struct {
@@ -56,7 +69,27 @@ func ExecuteWorkflow(ctx context.Context, rc *rest.Config, opts ExecuteWorkflowO
 		return nil, fmt.Errorf("build installation snapshot: %w", err)
 	}
 
-	wf, err := newWorkflow(rc, opts.Namespace, opts.Logger, deps)
+	var (
+		completed   map[string]workflows.Checkpoint
+		onCompleted func(workflows.StepResult[any])
+	)
+	if opts.Checkpoint {
+		store, err := deps.StateFactory(rc, opts.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("initialize installation state store: %w", err)
+		}
+
+		completed, err = prepareCheckpoints(ctx, store, opts)
+		if err != nil {
+			return nil, err
+		}
+		onCompleted = checkpointRecorder(ctx, store, opts)
+	}
+
+	wf, err := newWorkflow(rc, opts, deps, workflows.Opts{
+		Completed:       completed,
+		OnStepCompleted: onCompleted,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +128,73 @@ func ExecuteWorkflow(ctx context.Context, rc *rest.Config, opts ExecuteWorkflowO
 	}, nil
 }
 
-func newWorkflow(rc *rest.Config, namespace string, logger *ui.Logger, deps WorkflowDeps) (WorkflowRunner, error) {
+// prepareCheckpoints loads the checkpoints of the previous run when resuming,
+// otherwise it discards them so that the new run starts from scratch.
+func prepareCheckpoints(ctx context.Context, store state.Store, opts ExecuteWorkflowOptions) (map[string]workflows.Checkpoint, error) {
+	if !opts.Resume {
+		if err := store.ResetCheckpoints(ctx, opts.StateName); err != nil {
+			return nil, fmt.Errorf("reset installation checkpoints: %w", err)
+		}
+		return nil, nil
+	}
+
+	list, err := store.LoadCheckpoints(ctx, opts.StateName)
+	if err != nil {
+		return nil, fmt.Errorf("load installation checkpoints: %w", err)
+	}
+
+	completed := make(map[string]workflows.Checkpoint, len(list))
+	for _, cp := range list {
+		completed[cp.ID] = workflows.Checkpoint{Digest: cp.Digest, Result: cp.Result}
+	}
+	return completed, nil
+}
+
+// checkpointRecorder persists every completed step, with its sensitive values
+// masked (see steps.Redact). Failures are only logged: losing a checkpoint
+// means the step runs again on resume, not that it failed. The steps that
+// complete after an interrupt are recorded too, so the checkpoints are written
+// with a context that is not cancelled with ctx.
+func checkpointRecorder(ctx context.Context, store state.Store, opts ExecuteWorkflowOptions) func(workflows.StepResult[any]) {
+	ctx = context.WithoutCancel(ctx)
+	return func(res workflows.StepResult[any]) {
+		result, err := toResultMap(steps.Redact(res.Result()))
+		if err != nil {
+			opts.Logger.Warn("⚠ Unable to encode result of step %s: %v", res.ID(), err)
+		}
+
+		err = store.SaveCheckpoint(ctx, opts.StateName, state.Checkpoint{
+			ID:          res.ID(),
+			Digest:      res.Digest(),
+			Result:      result,
+			CompletedAt: metav1.Now(),
+		})
+		if err != nil {
+			opts.Logger.Warn("⚠ Unable to record checkpoint for step %s: %v", res.ID(), err)
+		}
+	}
+}
+
+func toResultMap(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// newWorkflow builds the workflow through the factories of deps. The run specific
+// settings, such as checkpoints or the operation, are taken from wo.
+func newWorkflow(rc *rest.Config, opts ExecuteWorkflowOptions, deps WorkflowDeps, wo workflows.Opts) (WorkflowRunner, error) {
 	g, err := deps.GetterFactory(rc)
 	if err != nil {
 		return nil, fmt.Errorf("initialize getter: %w", err)
@@ -111,14 +210,24 @@ func newWorkflow(rc *rest.Config, namespace string, logger *ui.Logger, deps Work
 		return nil, fmt.Errorf("initialize deletor: %w", err)
 	}
 
-	wf, err := deps.WorkflowFactory(workflows.Opts{
-		Getter:    g,
-		Applier:   a,
-		Deletor:   d,
-		Logger:    logger.Debug,
-		Cfg:       rc,
-		Namespace: namespace,
-	})
+	var healthChecks []types.HealthCheck
+	if opts.Result != nil {
+		healthChecks = opts.Result.Config.GetHealthChecks()
+	}
+
+	wo.Getter = g
+	wo.Applier = a
+	wo.Deletor = d
+	wo.Logger = opts.Logger.Debug
+	wo.Cfg = rc
+	wo.Namespace = opts.Namespace
+	wo.Parallelism = opts.Parallelism
+	wo.Progress = opts.StepProgress
+	wo.Atomic = opts.Atomic
+	wo.HealthChecks = healthChecks
+	wo.DryRun = opts.DryRun
+
+	wf, err := deps.WorkflowFactory(wo)
 	if err != nil {
 		return nil, fmt.Errorf("initialize workflow: %w", err)
 	}
@@ -134,10 +243,61 @@ func LogWorkflowResults(logger *ui.Logger, steps []*types.Step, results []workfl
 			logger.Info("[PEND] %s (%s) not executed", step.ID, step.Type)
 		case step.Skip:
 			logger.Info("[SKIP] %s (%s)", step.ID, step.Type)
+		case res.Resumed():
+			logger.Info("[DONE] %s (%s) completed in a previous run", step.ID, step.Type)
+		case res.SkipReason() != "":
+			logger.Info("[SKIP] %s (%s): %s", step.ID, step.Type, res.SkipReason())
+		case res.Interrupted():
+			logger.Warn("[INTERRUPTED] %s (%s): %v", step.ID, step.Type, res.Err())
+			logRollback(logger, step, res)
 		case res.Err() != nil:
-			logger.Error("%s (%s) failed: %v", step.ID, step.Type, res.Err())
+			if branch := res.Branch(); len(branch) > 1 {
+				logger.Error("%s (%s) failed in branch %s%s: %v", step.ID, step.Type, strings.Join(branch, " -> "), attemptsSuffix(res), res.Err())
+				logRollback(logger, step, res)
+				continue
+			}
+			logger.Error("%s (%s) failed%s: %v", step.ID, step.Type, attemptsSuffix(res), res.Err())
+			logRollback(logger, step, res)
 		default:
-			logger.Info("✓ %s (%s)", step.ID, step.Type)
+			logger.Info("✓ %s (%s)%s", step.ID, step.Type, attemptsSuffix(res))
+			logStepOutputs(logger, step.ID, res)
 		}
 	}
 }
+
+// logRollback reports the release of a failed atomic chart step that was reverted.
+func logRollback(logger *ui.Logger, step *types.Step, res workflows.StepResult[any]) {
+	chart, ok := res.Result().(*steps.ChartResult)
+	if !ok || chart == nil {
+		return
+	}
+
+	switch chart.Operation {
+	case charthandler.OperationRollback:
+		logger.Warn("[ROLLBACK] %s (%s) release %s rolled back, now at revision %d", step.ID, step.Type, chart.ReleaseName, chart.Revision)
+	case charthandler.OperationRollbackUninstall:
+		logger.Warn("[ROLLBACK] %s (%s) release %s uninstalled", step.ID, step.Type, chart.ReleaseName)
+	}
+}
+
+// logStepOutputs prints, at debug level, the ${steps.<id>.<field>} variables
+// published by a completed step. Sensitive var values are masked.
+func logStepOutputs(logger *ui.Logger, id string, res workflows.StepResult[any]) {
+	vars, err := workflows.StepOutputs(id, steps.Redact(res.Result()))
+	if err != nil {
+		return
+	}
+
+	keys := slices.Sorted(maps.Keys(vars))
+	for _, k := range keys {
+		logger.Debug("    ${%s} = %s", k, vars[k])
+	}
+}
+
+// attemptsSuffix describes the number of attempts of a retried step.
+func attemptsSuffix(res workflows.StepResult[any]) string {
+	if res.Attempts() <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" after %d attempts", res.Attempts())
+}
@@ -0,0 +1,183 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
+	"github.com/krateoplatformops/krateoctl/internal/install/lifecycle"
+	"github.com/krateoplatformops/krateoctl/internal/install/template"
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+	"github.com/krateoplatformops/krateoctl/internal/ui"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Command() subcommands.Command {
+	return &templateCmd{}
+}
+
+type templateCmd struct {
+	configFile     string
+	profile        string
+	namespace      string
+	installType    string
+	version        string
+	repository     string
+	varsFile       string
+	debug          bool
+	skipValidation bool
+	autoEnable     bool
+}
+
+func (c *templateCmd) Name() string     { return "template" }
+func (c *templateCmd) Synopsis() string { return "render the manifests produced by the configuration" }
+
+func (c *templateCmd) Usage() string {
+	wri := bytes.Buffer{}
+	fmt.Fprintf(&wri, "%s. Load the installation config, render every chart with its values and print the resulting objects as multi-document YAML, without talking to the cluster.\n\n", c.Synopsis())
+
+	fmt.Fprint(&wri, "USAGE:\n\n")
+	fmt.Fprint(&wri, "  krateoctl install template [FLAGS]\n\n")
+
+	fmt.Fprint(&wri, "FLAGS:\n\n")
+	fmt.Fprint(&wri, "  --version string\n")
+	fmt.Fprint(&wri, "        version/tag to fetch from the releases repository (enables remote mode)\n")
+	fmt.Fprint(&wri, "  --repository string\n")
+	fmt.Fprint(&wri, "        GitHub repository URL for releases (default \"https://github.com/krateoplatformops/releases\")\n")
+	fmt.Fprint(&wri, "  --config string\n")
+	fmt.Fprintf(&wri, "        path to local configuration file (default \"%s\", used when --version is not set)\n", shared.DefaultConfigPath)
+	fmt.Fprint(&wri, "  --profile string\n")
+	fmt.Fprint(&wri, "        optional profile name (e.g. dev, prod)\n")
+	fmt.Fprint(&wri, "  --namespace string\n")
+	fmt.Fprintf(&wri, "        namespace the charts and objects are rendered for (default \"%s\")\n", shared.DefaultNamespace)
+	fmt.Fprint(&wri, "  --type string\n")
+	fmt.Fprint(&wri, "        choose which file variant to use. Supported values: nodeport, loadbalancer, ingress. (default \"nodeport\")\n")
+	fmt.Fprint(&wri, "  --vars-file string\n")
+	fmt.Fprint(&wri, "        YAML file mapping variable names to values, for the var steps read from the cluster (valueFrom)\n")
+	fmt.Fprint(&wri, "  --skip-validation\n")
+	fmt.Fprint(&wri, "        skip configuration validation (useful for emergency recovery)\n")
+	fmt.Fprint(&wri, "  --auto-enable-dependencies\n")
+	fmt.Fprint(&wri, "        enable the components required by an enabled component\n")
+	fmt.Fprint(&wri, "  --debug\n")
+	fmt.Fprint(&wri, "        enable debug-level logging (can also use KRATEOCTL_DEBUG env var)\n\n")
+
+	fmt.Fprint(&wri, "CONVENTIONS:\n\n")
+	fmt.Fprint(&wri, "  - Configuration is loaded exactly as `krateoctl install plan` and `apply` do.\n")
+	fmt.Fprint(&wri, "  - Documents are written in apply order: pre-upgrade manifests, steps, post-upgrade manifests.\n")
+	fmt.Fprintf(&wri, "  - Every document is annotated with %s (the originating step) or\n", template.StepIDAnnotation)
+	fmt.Fprintf(&wri, "    %s (pre-upgrade or post-upgrade).\n", template.LifecyclePhaseAnnotation)
+	fmt.Fprint(&wri, "  - Var steps with valueFrom need a value in --vars-file; values in the file win over\n")
+	fmt.Fprint(&wri, "    the ones declared by the var steps.\n")
+	fmt.Fprint(&wri, "  - Wait, job and secret steps produce no document.\n\n")
+
+	fmt.Fprint(&wri, "EXAMPLES:\n\n")
+	fmt.Fprint(&wri, "  # Render a release version\n")
+	fmt.Fprint(&wri, "  krateoctl install template --version v1.0.0 > krateo.rendered.yaml\n\n")
+	fmt.Fprint(&wri, "  # Render the local config, feeding the variables read from the cluster\n")
+	fmt.Fprint(&wri, "  krateoctl install template --config ./krateo.yaml --vars-file ./vars.yaml\n\n")
+
+	return wri.String()
+}
+
+func (c *templateCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.version, "version", "", "version/tag to fetch from the releases repository")
+	f.StringVar(&c.repository, "repository", "", "GitHub repository URL for releases")
+	f.StringVar(&c.configFile, "config", shared.DefaultConfigPath, "path to local configuration file")
+	f.StringVar(&c.profile, "profile", "", "optional profile name")
+	f.StringVar(&c.namespace, "namespace", shared.DefaultNamespace, "kubernetes namespace the manifests are rendered for")
+	f.StringVar(&c.installType, "type", "nodeport", "choose which file variant to use: nodeport, loadbalancer, or ingress")
+	f.StringVar(&c.varsFile, "vars-file", "", "YAML file with the values of the variables read from the cluster")
+	f.BoolVar(&c.skipValidation, "skip-validation", false, "skip configuration validation")
+	f.BoolVar(&c.autoEnable, "auto-enable-dependencies", false, "enable the components required by an enabled component")
+	f.BoolVar(&c.debug, "debug", false, "enable debug-level logging")
+}
+
+func (c *templateCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	c.namespace = shared.EnsureNamespace(c.namespace)
+
+	// The YAML stream goes to stdout, so every log line is written to stderr.
+	l := shared.NewLogger(os.Stderr, c.debug || os.Getenv(shared.KRATEOCTL_DEBUG_ENV) != "")
+
+	var vars map[string]string
+	if c.varsFile != "" {
+		var err error
+		vars, err = template.LoadVarsFile(c.varsFile)
+		if err != nil {
+			l.Error("%v", err)
+			return subcommands.ExitFailure
+		}
+	}
+
+	result, err := shared.LoadConfigAndSteps(shared.NewLoadOptions(shared.LoadOptionsInput{
+		ConfigFile:             c.configFile,
+		Namespace:              c.namespace,
+		Profile:                c.profile,
+		Version:                c.version,
+		Repository:             c.repository,
+		InstallationType:       c.installType,
+		AutoEnableDependencies: c.autoEnable,
+	}), c.namespace, l.Info, c.skipValidation)
+	if err != nil {
+		l.Error("Failed to load configuration: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	lifecycleManager := lifecycle.NewManager(c.namespace, nil)
+	jobNameSuffix := time.Now().Format("20060102-150405")
+
+	pre, err := c.renderPhase(ctx, lifecycleManager, l, "pre-upgrade", jobNameSuffix, nil)
+	if err != nil {
+		l.Error("Failed to render pre-upgrade manifests: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	renderer := template.New(template.Options{
+		Namespace: c.namespace,
+		Vars:      vars,
+		Logger:    l.Debug,
+	})
+	objs, err := renderer.Render(ctx, result.Steps)
+	if err != nil {
+		l.Error("Failed to render steps: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	post, err := c.renderPhase(ctx, lifecycleManager, l, "post-upgrade", jobNameSuffix, renderer.Outputs())
+	if err != nil {
+		l.Error("Failed to render post-upgrade manifests: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	all := append(append(pre, objs...), post...)
+	if err := template.Write(os.Stdout, all); err != nil {
+		l.Error("✗ Failed to write manifests: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	l.Info("✓ Rendered %d documents from %d steps", len(all), len(result.Steps))
+	return subcommands.ExitSuccess
+}
+
+func (c *templateCmd) renderPhase(ctx context.Context, m *lifecycle.Manager, l *ui.Logger, phase, jobNameSuffix string, vars map[string]string) ([]*unstructured.Unstructured, error) {
+	objs, err := m.Render(ctx, l, lifecycle.ApplyOptions{
+		Phase:            phase,
+		Version:          c.version,
+		Repository:       c.repository,
+		ConfigFile:       c.configFile,
+		JobNameSuffix:    jobNameSuffix,
+		InstallationType: c.installType,
+		Vars:             vars,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range objs {
+		template.Annotate(obj, template.LifecyclePhaseAnnotation, phase)
+	}
+	return objs, nil
+}
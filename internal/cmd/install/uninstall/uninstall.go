@@ -0,0 +1,369 @@
+package uninstall
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/deletor"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/install/lifecycle"
+	"github.com/krateoplatformops/krateoctl/internal/install/state"
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+	"github.com/krateoplatformops/krateoctl/internal/ui"
+	"github.com/krateoplatformops/krateoctl/internal/util/kube"
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type restConfigProvider func() (*rest.Config, error)
+type getterFactory func(*rest.Config) (*getter.Getter, error)
+type applierFactory func(*rest.Config) (*applier.Applier, error)
+type deletorFactory func(*rest.Config) (*deletor.Deletor, error)
+type workflowFactory func(workflows.Opts) (shared.WorkflowRunner, error)
+type stateStoreFactory func(*rest.Config, string) (state.Store, error)
+type kubeClientFactory func(*rest.Config) (kubernetes.Interface, error)
+
+func Command() subcommands.Command {
+	return &uninstallCmd{}
+}
+
+type uninstallCmd struct {
+	configFile  string
+	namespace   string
+	version     string
+	repository  string
+	installType string
+	debug       bool
+	yes         bool // Do not ask for confirmation
+	purge       bool // Also delete the namespace and its PersistentVolumeClaims
+	parallelism int  // Maximum number of workflow steps executed concurrently
+
+	restConfigFn      restConfigProvider
+	getterFactory     getterFactory
+	applierFactory    applierFactory
+	deletorFactory    deletorFactory
+	workflowFactory   workflowFactory
+	errEvaluator      func([]workflows.StepResult[any]) error
+	stateFactory      stateStoreFactory
+	kubeClientFactory kubeClientFactory
+	in                io.Reader
+	out               io.Writer
+	stateName         string
+}
+
+func (c *uninstallCmd) ensureDeps() {
+	if c.restConfigFn == nil {
+		c.restConfigFn = kube.RestConfig
+	}
+	if c.getterFactory == nil {
+		c.getterFactory = getter.NewGetter
+	}
+	if c.applierFactory == nil {
+		c.applierFactory = applier.NewApplier
+	}
+	if c.deletorFactory == nil {
+		c.deletorFactory = deletor.NewDeletor
+	}
+	if c.workflowFactory == nil {
+		c.workflowFactory = func(opts workflows.Opts) (shared.WorkflowRunner, error) {
+			return workflows.New(opts)
+		}
+	}
+	if c.errEvaluator == nil {
+		c.errEvaluator = func(results []workflows.StepResult[any]) error {
+			return workflows.Err(results)
+		}
+	}
+	if c.stateFactory == nil {
+		c.stateFactory = shared.DefaultStateStoreFactory
+	}
+	if c.kubeClientFactory == nil {
+		c.kubeClientFactory = func(cfg *rest.Config) (kubernetes.Interface, error) {
+			return kubernetes.NewForConfig(cfg)
+		}
+	}
+	if c.in == nil {
+		c.in = os.Stdin
+	}
+	if c.out == nil {
+		c.out = os.Stdout
+	}
+	c.namespace = shared.EnsureNamespace(c.namespace)
+	c.stateName = shared.EnsureStateName(c.stateName)
+}
+
+func (c *uninstallCmd) Name() string     { return "uninstall" }
+func (c *uninstallCmd) Synopsis() string { return "remove an installation from the cluster" }
+
+func (c *uninstallCmd) Usage() string {
+	wri := strings.Builder{}
+	fmt.Fprintf(&wri, "%s. Load the stored installation snapshot and run its steps in delete mode.\n\n", c.Synopsis())
+	fmt.Fprint(&wri, "USAGE:\n  krateoctl install uninstall [FLAGS]\n\n")
+	fmt.Fprint(&wri, "FLAGS:\n")
+	fmt.Fprintf(&wri, "  --namespace string    namespace of the installation (default \"%s\")\n", shared.DefaultNamespace)
+	fmt.Fprint(&wri, "  --yes                 do not ask for confirmation\n")
+	fmt.Fprint(&wri, "  --purge               also delete the PersistentVolumeClaims of the namespace and the namespace itself\n")
+	fmt.Fprint(&wri, "  --parallelism int     maximum number of independent steps (see dependsOn) deleted concurrently (default 1)\n")
+	fmt.Fprint(&wri, "  --version string      version/tag whose pre-delete and post-delete manifests are fetched (default: the installed version)\n")
+	fmt.Fprint(&wri, "  --repository string   GitHub repository URL for releases (default \"https://github.com/krateoplatformops/releases\")\n")
+	fmt.Fprintf(&wri, "  --config string       local configuration file, whose directory holds the pre-delete and post-delete manifests (default \"%s\")\n", shared.DefaultConfigPath)
+	fmt.Fprint(&wri, "  --type string         choose which file variant to use. Supported values: nodeport, loadbalancer, ingress. (default \"nodeport\")\n")
+	fmt.Fprint(&wri, "  --debug               enable debug-level logging (can also use KRATEOCTL_DEBUG env var)\n\n")
+	fmt.Fprint(&wri, "STEPS:\n\n")
+	fmt.Fprint(&wri, "  1. Runs the pre-delete manifests, if any.\n")
+	fmt.Fprint(&wri, "  2. Deletes the objects and uninstalls the chart releases of the snapshot, in reverse order.\n")
+	fmt.Fprint(&wri, "  3. Runs the post-delete manifests, if any.\n")
+	fmt.Fprint(&wri, "  4. Removes the protect-state finalizer and deletes the Installation resource.\n")
+	fmt.Fprint(&wri, "  5. With --purge, deletes the PersistentVolumeClaims and the namespace.\n\n")
+	fmt.Fprint(&wri, "EXAMPLES:\n\n")
+	fmt.Fprint(&wri, "  # Uninstall after confirming interactively\n")
+	fmt.Fprint(&wri, "  krateoctl install uninstall\n\n")
+	fmt.Fprint(&wri, "  # Tear down a test cluster completely, without prompting\n")
+	fmt.Fprint(&wri, "  krateoctl install uninstall --yes --purge\n\n")
+	return wri.String()
+}
+
+func (c *uninstallCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.namespace, "namespace", shared.DefaultNamespace, "namespace of the installation")
+	f.BoolVar(&c.yes, "yes", false, "do not ask for confirmation")
+	f.BoolVar(&c.purge, "purge", false, "also delete the PersistentVolumeClaims and the namespace")
+	f.IntVar(&c.parallelism, "parallelism", 1, "maximum number of independent steps deleted concurrently")
+	f.StringVar(&c.version, "version", "", "version/tag whose lifecycle manifests are fetched from the releases repository")
+	f.StringVar(&c.repository, "repository", "", "GitHub repository URL for releases")
+	f.StringVar(&c.configFile, "config", shared.DefaultConfigPath, "path to local configuration file")
+	f.StringVar(&c.installType, "type", "nodeport", "choose which file variant to use: nodeport, loadbalancer, or ingress")
+	f.BoolVar(&c.debug, "debug", false, "enable debug-level logging")
+}
+
+func (c *uninstallCmd) Execute(ctx context.Context, fs *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	c.ensureDeps()
+
+	spin := ui.NewSpinner(c.out)
+	l := shared.NewLogger(spin, c.debug || os.Getenv(shared.KRATEOCTL_DEBUG_ENV) != "")
+	defer spin.Stop("")
+	lifecycleManager := lifecycle.NewManager(c.namespace, func(cfg *rest.Config) (*getter.Getter, error) {
+		return c.getterFactory(cfg)
+	})
+	jobNameSuffix := time.Now().Format("20060102-150405")
+
+	// 1. Load the installation snapshot
+	l.Info("\n📡 Connecting to Kubernetes cluster...")
+	rc, err := c.restConfigFn()
+	if err != nil {
+		l.Error("Failed to load kubeconfig: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	store, err := c.stateFactory(rc, c.namespace)
+	if err != nil {
+		l.Error("Failed to initialize installation state store: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	snapshot, err := store.Load(ctx, c.stateName)
+	if apierrors.IsNotFound(err) {
+		l.Error("Installation %q not found in namespace %q", c.stateName, c.namespace)
+		return subcommands.ExitFailure
+	}
+	if err != nil {
+		l.Error("Failed to read installation snapshot: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	list, err := snapshot.GetSteps()
+	if err != nil {
+		l.Error("Failed to decode installation snapshot: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	// 2. Confirm
+	c.printSummary(l, snapshot, list)
+	if !c.yes && !c.confirm() {
+		l.Info("ℹ Uninstall aborted")
+		return subcommands.ExitFailure
+	}
+
+	version := c.version
+	if version == "" && snapshot.InstallationVersion != "local" {
+		version = snapshot.InstallationVersion
+	}
+
+	a, err := c.applierFactory(rc)
+	if err != nil {
+		l.Error("Failed to initialize applier: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	// 3. Apply Pre-Delete Manifests (if they exist)
+	if err := lifecycleManager.Apply(ctx, a, l, lifecycle.ApplyOptions{
+		Phase:            "pre-delete",
+		Version:          version,
+		Repository:       c.repository,
+		ConfigFile:       c.configFile,
+		RestConfig:       rc,
+		JobNameSuffix:    jobNameSuffix,
+		InstallationType: c.installType,
+	}); err != nil {
+		l.Error("Failed to apply pre-delete manifests: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	// 4. Run the steps in delete mode
+	opts := shared.ExecuteWorkflowOptions{
+		Namespace:        c.namespace,
+		StateName:        c.stateName,
+		Logger:           l,
+		ProgressReporter: c.createProgressReporter(spin, l),
+		StepProgress:     c.createStepProgress(spin),
+		Parallelism:      c.parallelism,
+	}
+	deps := shared.WorkflowDeps{
+		GetterFactory:   shared.GetterFactory(c.getterFactory),
+		ApplierFactory:  shared.ApplierFactory(c.applierFactory),
+		DeletorFactory:  shared.DeletorFactory(c.deletorFactory),
+		WorkflowFactory: shared.WorkflowFactory(c.workflowFactory),
+		ErrEvaluator:    shared.ErrEvaluator(c.errEvaluator),
+		StateFactory:    shared.StateStoreFactory(c.stateFactory),
+	}
+
+	vars, err := shared.ResolveVars(ctx, rc, opts, deps, list)
+	if err != nil {
+		l.Warn("⚠ Unable to resolve every variable of the installation: %v", err)
+	}
+
+	l.Info("\n🗑  Deleting the installation from namespace '%s'...", c.namespace)
+	l.Info("═════════════════════════════════════════════════════════════")
+	spin.SetPrefix("🗑  ")
+	spin.Start()
+	result, err := shared.DeleteWorkflow(ctx, rc, opts, deps, list, vars)
+	spin.Stop("")
+
+	l.Info("═════════════════════════════════════════════════════════════")
+	if result != nil {
+		shared.LogWorkflowResults(l, result.Steps, result.Results)
+	}
+	if err != nil {
+		l.Error("\nUninstall completed with errors, the installation snapshot is kept: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	// 5. Apply Post-Delete Manifests (if they exist)
+	if err := lifecycleManager.Apply(ctx, a, l, lifecycle.ApplyOptions{
+		Phase:            "post-delete",
+		Version:          version,
+		Repository:       c.repository,
+		ConfigFile:       c.configFile,
+		RestConfig:       rc,
+		JobNameSuffix:    jobNameSuffix,
+		InstallationType: c.installType,
+		Vars:             vars,
+	}); err != nil {
+		l.Error("Failed to apply post-delete manifests: %v", err)
+		return subcommands.ExitFailure
+	}
+
+	// 6. Remove the installation state
+	if err := store.Delete(ctx, c.stateName); err != nil {
+		l.Error("Failed to delete installation %q: %v", c.stateName, err)
+		return subcommands.ExitFailure
+	}
+	l.Info("✓ Installation %q removed from namespace %q", c.stateName, c.namespace)
+
+	// 7. Purge the namespace
+	if c.purge {
+		if err := c.purgeNamespace(ctx, rc, l); err != nil {
+			l.Error("Failed to purge namespace %q: %v", c.namespace, err)
+			return subcommands.ExitFailure
+		}
+	}
+
+	l.Info("✓ Successfully uninstalled\n")
+	return subcommands.ExitSuccess
+}
+
+// printSummary lists what the uninstall removes.
+func (c *uninstallCmd) printSummary(l *ui.Logger, snapshot *state.Snapshot, list []*types.Step) {
+	l.Info("\n🗑  Installation %q (version %s) in namespace %q", c.stateName, snapshot.InstallationVersion, c.namespace)
+	for _, step := range list {
+		if step.Skip || step.Type == types.TypeVar {
+			continue
+		}
+		l.Info("  - %s (%s)", step.ID, step.Type)
+	}
+	if c.purge {
+		l.Warn("⚠ --purge also deletes the PersistentVolumeClaims of namespace %q and the namespace itself", c.namespace)
+	}
+}
+
+// confirm asks the user to proceed; anything but y or yes aborts.
+func (c *uninstallCmd) confirm() bool {
+	fmt.Fprint(c.out, "\nProceed with the uninstall? [y/N]: ")
+
+	answer, err := bufio.NewReader(c.in).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// purgeNamespace deletes the PersistentVolumeClaims left by the uninstalled
+// workloads, then the namespace itself.
+func (c *uninstallCmd) purgeNamespace(ctx context.Context, rc *rest.Config, l *ui.Logger) error {
+	cli, err := c.kubeClientFactory(rc)
+	if err != nil {
+		return fmt.Errorf("initialize kubernetes client: %w", err)
+	}
+
+	err = cli.CoreV1().PersistentVolumeClaims(c.namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete persistent volume claims: %w", err)
+	}
+	l.Info("✓ PersistentVolumeClaims of namespace %q deleted", c.namespace)
+
+	err = cli.CoreV1().Namespaces().Delete(ctx, c.namespace, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete namespace: %w", err)
+	}
+	l.Info("✓ Namespace %q deleted", c.namespace)
+	return nil
+}
+
+func (c *uninstallCmd) createProgressReporter(spin *ui.Spinner, l *ui.Logger) workflows.StepNotifier {
+	started := 0
+	return func(idx int, step *types.Step, skipped bool) {
+		started++
+		status := "deleting"
+		if skipped {
+			status = "skipped"
+		}
+		spin.SetSuffix(fmt.Sprintf("step %d - %s (%s)", started, step.ID, status))
+
+		l.V(ui.LevelDebug).Info("Deleting workflow step: index=%d id=%s type=%s",
+			idx+1, step.ID, step.Type)
+	}
+}
+
+// createStepProgress shows the status reported by long running steps in the spinner suffix.
+func (c *uninstallCmd) createStepProgress(spin *ui.Spinner) func(id, message string) {
+	return func(id, message string) {
+		spin.SetSuffix(fmt.Sprintf("%s (%s)", id, message))
+	}
+}
@@ -0,0 +1,190 @@
+package uninstall
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/cmd/install/shared"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/deletor"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/install/state"
+	"github.com/krateoplatformops/krateoctl/internal/subcommands"
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestUninstallExecute(t *testing.T) {
+	snapshot, err := state.BuildSnapshot(nil, []*types.Step{
+		{ID: "registry", Type: types.TypeVar, With: &map[string]any{"name": "REGISTRY", "value": "ghcr.io"}},
+		{ID: "install-authn", Type: types.TypeChart, With: &map[string]any{"releaseName": "authn"}},
+		{ID: "authn-config", Type: types.TypeObject, With: &map[string]any{"kind": "ConfigMap"}},
+	}, "local")
+	if err != nil {
+		t.Fatalf("BuildSnapshot() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		yes         bool
+		purge       bool
+		input       string
+		snapshot    *state.Snapshot
+		wantStatus  subcommands.ExitStatus
+		wantDeleted bool
+		wantPurged  bool
+	}{
+		{
+			name:        "deletes the steps and the installation after confirmation",
+			input:       "y\n",
+			snapshot:    snapshot,
+			wantStatus:  subcommands.ExitSuccess,
+			wantDeleted: true,
+		},
+		{
+			name:        "purges the namespace without prompting",
+			yes:         true,
+			purge:       true,
+			snapshot:    snapshot,
+			wantStatus:  subcommands.ExitSuccess,
+			wantDeleted: true,
+			wantPurged:  true,
+		},
+		{
+			name:       "aborts when the uninstall is not confirmed",
+			input:      "n\n",
+			snapshot:   snapshot,
+			wantStatus: subcommands.ExitFailure,
+		},
+		{
+			name:       "fails when the installation does not exist",
+			yes:        true,
+			wantStatus: subcommands.ExitFailure,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &stubStateStore{snapshot: tc.snapshot}
+			runner := &stubWorkflow{}
+			cli := fake.NewSimpleClientset(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}},
+				&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "test-ns"}},
+			)
+
+			var ops []steps.Op
+			cmd := &uninstallCmd{
+				configFile:   filepath.Join(t.TempDir(), "krateo.yaml"),
+				namespace:    "test-ns",
+				yes:          tc.yes,
+				purge:        tc.purge,
+				restConfigFn: func() (*rest.Config, error) { return &rest.Config{}, nil },
+				getterFactory: func(*rest.Config) (*getter.Getter, error) {
+					return &getter.Getter{}, nil
+				},
+				applierFactory: func(*rest.Config) (*applier.Applier, error) {
+					return &applier.Applier{}, nil
+				},
+				deletorFactory: func(*rest.Config) (*deletor.Deletor, error) {
+					return &deletor.Deletor{}, nil
+				},
+				workflowFactory: func(o workflows.Opts) (shared.WorkflowRunner, error) {
+					ops = append(ops, o.Op)
+					return runner, nil
+				},
+				stateFactory:      func(*rest.Config, string) (state.Store, error) { return store, nil },
+				kubeClientFactory: func(*rest.Config) (kubernetes.Interface, error) { return cli, nil },
+				in:                strings.NewReader(tc.input),
+				out:               &bytes.Buffer{},
+				stateName:         "test-install",
+			}
+
+			status := cmd.Execute(context.Background(), flag.NewFlagSet("uninstall", flag.ContinueOnError))
+			if status != tc.wantStatus {
+				t.Fatalf("Execute() = %v, want %v", status, tc.wantStatus)
+			}
+			if store.deleted != tc.wantDeleted {
+				t.Fatalf("installation deleted = %v, want %v", store.deleted, tc.wantDeleted)
+			}
+
+			_, err := cli.CoreV1().Namespaces().Get(context.Background(), "test-ns", metav1.GetOptions{})
+			if purged := apierrors.IsNotFound(err); purged != tc.wantPurged {
+				t.Fatalf("namespace purged = %v, want %v", purged, tc.wantPurged)
+			}
+
+			if !tc.wantDeleted {
+				if len(runner.runs) != 0 {
+					t.Fatalf("workflow ran %v, want nothing", runner.runs)
+				}
+				return
+			}
+
+			if !slices.Equal(ops, []steps.Op{0, steps.Delete}) {
+				t.Fatalf("workflow operations = %v, want a var run followed by a delete run", ops)
+			}
+			want := [][]string{{"registry"}, {"install-authn", "authn-config"}}
+			if len(runner.runs) != 2 || !slices.Equal(runner.runs[0], want[0]) || !slices.Equal(runner.runs[1], want[1]) {
+				t.Fatalf("workflow runs = %v, want %v", runner.runs, want)
+			}
+		})
+	}
+}
+
+type stubWorkflow struct {
+	runs [][]string
+}
+
+func (s *stubWorkflow) Run(_ context.Context, spec *types.Workflow, _ func(*types.Step) bool, _ workflows.StepNotifier) []workflows.StepResult[any] {
+	var ids []string
+	for _, step := range spec.Steps {
+		ids = append(ids, step.ID)
+	}
+	s.runs = append(s.runs, ids)
+	return make([]workflows.StepResult[any], len(spec.Steps))
+}
+
+type stubStateStore struct {
+	snapshot *state.Snapshot
+	deleted  bool
+}
+
+func (s *stubStateStore) Save(context.Context, string, *state.Snapshot) error {
+	return nil
+}
+
+func (s *stubStateStore) Load(_ context.Context, name string) (*state.Snapshot, error) {
+	if s.snapshot == nil {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: "krateo.io", Resource: "installations"}, name)
+	}
+	return s.snapshot, nil
+}
+
+func (s *stubStateStore) SaveCheckpoint(context.Context, string, state.Checkpoint) error {
+	return nil
+}
+
+func (s *stubStateStore) LoadCheckpoints(context.Context, string) ([]state.Checkpoint, error) {
+	return nil, nil
+}
+
+func (s *stubStateStore) ResetCheckpoints(context.Context, string) error {
+	return nil
+}
+
+func (s *stubStateStore) Delete(context.Context, string) error {
+	s.deleted = true
+	return nil
+}
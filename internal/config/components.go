@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ComponentState is the resolved enablement of a component.
+type ComponentState struct {
+	Enabled bool
+	// Reason explains a state set by the requires of another component, such as
+	// "enabled because frontend requires it". It is empty otherwise.
+	Reason string
+}
+
+// GetComponents returns the component definitions, including the components
+// only declared in the overrides.
+func (c *Config) GetComponents() map[string]ComponentConfig {
+	return c.componentDefinitions()
+}
+
+// GetEnabledComponents returns the state of every component. A component is
+// enabled unless its definition, or the overrides, set enabled to false. With
+// WithAutoEnableDependencies, the components required by an enabled one are
+// enabled too; otherwise an enabled component requiring a disabled one is kept
+// and reported by the Validator.
+func (c *Config) GetEnabledComponents() (map[string]ComponentState, error) {
+	definitions := c.componentDefinitions()
+	overrides := c.componentOverrides()
+
+	states := make(map[string]ComponentState)
+	if len(definitions) == 0 && len(overrides) == 0 {
+		return states, nil
+	}
+
+	for name, comp := range definitions {
+		val := true
+		if comp.Enabled != nil {
+			val = *comp.Enabled
+		}
+		if ov, ok := overrides[name]; ok && ov.Enabled != nil {
+			val = *ov.Enabled
+		}
+		states[name] = ComponentState{Enabled: val}
+	}
+
+	if c.autoEnable {
+		c.enableRequired(states)
+	}
+
+	return states, nil
+}
+
+// enableRequired enables, transitively, the components required by an enabled one.
+func (c *Config) enableRequired(states map[string]ComponentState) {
+	names := slices.Sorted(maps.Keys(states))
+	for changed := true; changed; {
+		changed = false
+		for _, name := range names {
+			if !states[name].Enabled {
+				continue
+			}
+			for _, dep := range c.componentRequires(name) {
+				if state, ok := states[dep]; ok && !state.Enabled {
+					states[dep] = ComponentState{Enabled: true, Reason: fmt.Sprintf("enabled because %s requires it", name)}
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+// componentRequires returns the requires of a component, from its definition
+// and the overrides.
+func (c *Config) componentRequires(name string) []string {
+	return c.componentRelations(name, func(comp ComponentConfig) []string { return comp.Requires })
+}
+
+// componentConflicts returns the conflicts of a component, from its definition
+// and the overrides.
+func (c *Config) componentConflicts(name string) []string {
+	return c.componentRelations(name, func(comp ComponentConfig) []string { return comp.Conflicts })
+}
+
+func (c *Config) componentRelations(name string, field func(ComponentConfig) []string) []string {
+	var out []string
+	if comp, ok := c.componentDefinitions()[name]; ok {
+		out = append(out, field(comp)...)
+	}
+	if comp, ok := c.componentOverrides()[name]; ok {
+		for _, dep := range field(comp) {
+			if !slices.Contains(out, dep) {
+				out = append(out, dep)
+			}
+		}
+	}
+	return out
+}
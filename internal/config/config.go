@@ -1,8 +1,10 @@
 package config
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
 	"gopkg.in/yaml.v3"
@@ -12,6 +14,8 @@ import (
 type Config struct {
 	data map[string]any
 	doc  *Document
+	// autoEnable enables the components required by the enabled ones.
+	autoEnable bool
 }
 
 // NewConfig creates a new Config from loaded data.
@@ -28,6 +32,14 @@ func NewConfig(data map[string]any) (*Config, error) {
 	return &Config{data: data, doc: doc}, nil
 }
 
+// WithAutoEnableDependencies enables, when auto is set, the disabled components
+// required by an enabled one instead of disabling or rejecting the dependents
+// (see GetEnabledComponents).
+func (c *Config) WithAutoEnableDependencies(auto bool) *Config {
+	c.autoEnable = auto
+	return c
+}
+
 // Document returns the typed configuration document backing this Config instance.
 func (c *Config) Document() *Document {
 	return c.doc
@@ -58,15 +70,35 @@ func (c *Config) GetModule(name string) (ModuleConfig, error) {
 	return mod, nil
 }
 
-// GetSteps returns the steps array from the configuration.
+// GetHealthChecks returns the user-defined readiness rules, keyed by kind.
+func (c *Config) GetHealthChecks() []types.HealthCheck {
+	if c == nil || c.doc == nil {
+		return nil
+	}
+	return c.doc.HealthChecks
+}
+
+// GetSteps returns the steps array from the configuration, followed by the chart
+// steps generated from the modules (see moduleSteps).
 // Steps represent sequential operations: chart installations, variable extractions, etc.
 func (c *Config) GetSteps() ([]*types.Step, error) {
-	if c.doc == nil || len(c.doc.Steps) == 0 {
+	if c.doc == nil || len(c.doc.Steps) == 0 && len(c.doc.Modules) == 0 {
 		return make([]*types.Step, 0), nil
 	}
 
-	steps := make([]*types.Step, 0, len(c.doc.Steps))
-	for i, def := range c.doc.Steps {
+	var (
+		defaultRetry   *types.RetryPolicy
+		defaultTimeout string
+	)
+	if c.doc.StepDefaults != nil {
+		defaultRetry = c.doc.StepDefaults.Retry
+		defaultTimeout = c.doc.StepDefaults.Timeout
+	}
+
+	defs := append(slices.Clone(c.doc.Steps), c.moduleSteps()...)
+
+	steps := make([]*types.Step, 0, len(defs))
+	for i, def := range defs {
 		if def.ID == "" {
 			return nil, fmt.Errorf("step at index %d missing id", i)
 		}
@@ -87,9 +119,13 @@ func (c *Config) GetSteps() ([]*types.Step, error) {
 		}
 
 		steps = append(steps, &types.Step{
-			ID:   def.ID,
-			Type: def.Type,
-			With: with,
+			ID:        def.ID,
+			Type:      def.Type,
+			With:      with,
+			DependsOn: slices.Clone(def.DependsOn),
+			When:      def.When,
+			Retry:     def.Retry.Merge(defaultRetry),
+			Timeout:   cmp.Or(def.Timeout, defaultTimeout),
 		})
 	}
 
@@ -226,43 +262,6 @@ func (c *Config) componentOverrides() map[string]ComponentConfig {
 	return c.doc.Components
 }
 
-// Add this method
-func (c *Config) GetEnabledComponents() (map[string]bool, error) {
-	definitions := c.componentDefinitions()
-	overrides := c.componentOverrides()
-
-	enabled := make(map[string]bool)
-	if len(definitions) == 0 && len(overrides) == 0 {
-		return enabled, nil
-	}
-
-	for name, comp := range definitions {
-		val := true
-		if comp.Enabled != nil {
-			val = *comp.Enabled
-		}
-		if overrides != nil {
-			if ov, ok := overrides[name]; ok && ov.Enabled != nil {
-				val = *ov.Enabled
-			}
-		}
-		enabled[name] = val
-	}
-
-	for name, ov := range overrides {
-		if _, ok := enabled[name]; ok {
-			continue
-		}
-		val := true
-		if ov.Enabled != nil {
-			val = *ov.Enabled
-		}
-		enabled[name] = val
-	}
-
-	return enabled, nil
-}
-
 // Add this method to get the component that owns a step
 func (c *Config) GetComponentForStep(stepID string) (string, error) {
 	definitions := c.componentDefinitions()
@@ -293,11 +292,16 @@ func (c *Config) GetActiveSteps() ([]*types.Step, error) {
 		return nil, err
 	}
 
-	// Mark steps based on component enablement
+	// Mark steps based on component and module enablement
 	for i, step := range steps {
+		if !c.moduleEnabled(step.ID) {
+			steps[i].Skip = true
+			continue
+		}
+
 		componentName, _ := c.GetComponentForStep(step.ID)
 		if componentName != "" {
-			if enabled, exists := enabledComponents[componentName]; exists && !enabled {
+			if state, exists := enabledComponents[componentName]; exists && !state.Enabled {
 				steps[i].Skip = true
 			}
 		}
@@ -450,6 +450,234 @@ func TestValidateStepConfigValid(t *testing.T) {
 	}
 }
 
+func TestValidateStepDependenciesUnknownStep(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"componentsDefinition": map[string]any{
+			"backend": map[string]any{
+				"steps": []interface{}{"install-backend"},
+			},
+		},
+		"steps": []interface{}{
+			map[string]any{
+				"id":        "install-backend",
+				"type":      "chart",
+				"dependsOn": []interface{}{"install-crds"},
+			},
+		},
+	})
+
+	err := NewValidator(cfg).Validate()
+	if err == nil {
+		t.Fatalf("expected error for unknown dependency, got nil")
+	}
+
+	if !contains(err.Error(), `depends on unknown step "install-crds"`) {
+		t.Fatalf("expected error about unknown dependency, got: %v", err)
+	}
+}
+
+func TestValidateStepDependenciesCycle(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"componentsDefinition": map[string]any{
+			"backend": map[string]any{
+				"steps": []interface{}{"install-db", "install-backend"},
+			},
+		},
+		"steps": []interface{}{
+			map[string]any{
+				"id":        "install-db",
+				"type":      "chart",
+				"dependsOn": []interface{}{"install-backend"},
+			},
+			map[string]any{
+				"id":        "install-backend",
+				"type":      "chart",
+				"dependsOn": []interface{}{"install-db"},
+			},
+		},
+	})
+
+	err := NewValidator(cfg).Validate()
+	if err == nil {
+		t.Fatalf("expected error for dependency cycle, got nil")
+	}
+
+	if !contains(err.Error(), "install-db -> install-backend -> install-db") {
+		t.Fatalf("expected error describing the cycle, got: %v", err)
+	}
+}
+
+func TestValidateStepConditionsInvalid(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"componentsDefinition": map[string]any{
+			"frontend": map[string]any{
+				"steps": []interface{}{"install-ingress"},
+			},
+		},
+		"steps": []interface{}{
+			map[string]any{
+				"id":   "install-ingress",
+				"type": "chart",
+				"when": ".env.[",
+			},
+		},
+	})
+
+	err := NewValidator(cfg).Validate()
+	if err == nil {
+		t.Fatalf("expected error for invalid when condition, got nil")
+	}
+
+	if !contains(err.Error(), "invalid when condition in step install-ingress") {
+		t.Fatalf("expected error about invalid when condition, got: %v", err)
+	}
+}
+
+func TestGetStepsAppliesDefaultRetry(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"stepDefaults": map[string]any{
+			"retry": map[string]any{
+				"attempts": 3,
+				"backoff":  "2s",
+			},
+		},
+		"steps": []interface{}{
+			map[string]any{
+				"id":   "install-authn",
+				"type": "chart",
+			},
+			map[string]any{
+				"id":   "install-db",
+				"type": "chart",
+				"retry": map[string]any{
+					"attempts": 10,
+					"retryOn":  []interface{}{"webhook"},
+				},
+			},
+		},
+	})
+
+	steps, err := cfg.GetSteps()
+	if err != nil {
+		t.Fatalf("GetSteps() error = %v", err)
+	}
+
+	if got := steps[0].Retry; got == nil || got.Attempts != 3 || got.Backoff != "2s" {
+		t.Fatalf("steps[0].Retry = %+v, want the default policy", got)
+	}
+	if got := steps[1].Retry; got == nil || got.Attempts != 10 || got.Backoff != "2s" || len(got.RetryOn) != 1 {
+		t.Fatalf("steps[1].Retry = %+v, want the step policy merged with the default", got)
+	}
+}
+
+func TestGetStepsAppliesDefaultTimeout(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"stepDefaults": map[string]any{
+			"timeout": "10m",
+		},
+		"steps": []interface{}{
+			map[string]any{
+				"id":   "install-authn",
+				"type": "chart",
+			},
+			map[string]any{
+				"id":      "wait-db",
+				"type":    "wait",
+				"timeout": "30s",
+			},
+		},
+	})
+
+	steps, err := cfg.GetSteps()
+	if err != nil {
+		t.Fatalf("GetSteps() error = %v", err)
+	}
+
+	if steps[0].Timeout != "10m" || steps[1].Timeout != "30s" {
+		t.Fatalf("timeouts = %q, %q, want the default and the step one", steps[0].Timeout, steps[1].Timeout)
+	}
+}
+
+func TestValidateStepTimeoutInvalid(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"componentsDefinition": map[string]any{
+			"backend": map[string]any{
+				"steps": []interface{}{"install-db"},
+			},
+		},
+		"steps": []interface{}{
+			map[string]any{
+				"id":      "install-db",
+				"type":    "chart",
+				"timeout": "-1m",
+			},
+		},
+	})
+
+	err := NewValidator(cfg).Validate()
+	if err == nil {
+		t.Fatalf("expected error for invalid timeout, got nil")
+	}
+
+	if !contains(err.Error(), "invalid timeout in step install-db") {
+		t.Fatalf("expected error about the timeout, got: %v", err)
+	}
+}
+
+func TestValidateRetryPolicyInvalid(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"componentsDefinition": map[string]any{
+			"backend": map[string]any{
+				"steps": []interface{}{"install-db"},
+			},
+		},
+		"steps": []interface{}{
+			map[string]any{
+				"id":   "install-db",
+				"type": "chart",
+				"retry": map[string]any{
+					"retryOn": []interface{}{"always"},
+				},
+			},
+		},
+	})
+
+	err := NewValidator(cfg).Validate()
+	if err == nil {
+		t.Fatalf("expected error for invalid retry policy, got nil")
+	}
+
+	if !contains(err.Error(), "invalid retry policy in step install-db") {
+		t.Fatalf("expected error about the retry policy, got: %v", err)
+	}
+}
+
+func TestValidateHealthChecks(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"healthChecks": []interface{}{
+			map[string]any{
+				"apiVersion": "postgresql.cnpg.io/v1",
+				"kind":       "Cluster",
+				"expression": ".status.readyInstances == .spec.instances",
+			},
+			map[string]any{
+				"apiVersion": "core.krateo.io/v1alpha1",
+				"kind":       "CompositionDefinition",
+				"expression": ".status.conditions[] | select(.type ==",
+			},
+		},
+	})
+
+	if got := cfg.GetHealthChecks(); len(got) != 2 || got[0].Kind != "Cluster" {
+		t.Fatalf("GetHealthChecks() = %+v", got)
+	}
+
+	err := NewValidator(cfg).Validate()
+	if err == nil || !contains(err.Error(), "invalid healthChecks[1]") {
+		t.Fatalf("expected error about the health check expression, got: %v", err)
+	}
+}
+
 func contains(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
 		if s[i:i+len(substr)] == substr {
@@ -458,3 +686,259 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestGetActiveStepsGeneratesModuleSteps(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"componentsDefinition": map[string]any{
+			"core": map[string]any{
+				"steps": []interface{}{"install-authn"},
+			},
+		},
+		"steps": []interface{}{
+			map[string]any{
+				"id":   "install-authn",
+				"type": "chart",
+			},
+		},
+		"modules": map[string]any{
+			"finops": map[string]any{
+				"dependsOn": []interface{}{"install-authn"},
+				"chart": map[string]any{
+					"repository": "https://charts.krateo.io",
+					"name":       "finops-moving-window-microservice",
+					"version":    "0.1.0",
+					"namespace":  "finops",
+				},
+				"values": map[string]any{"replicaCount": 2},
+			},
+			"composition-dynamic-controller": map[string]any{
+				"enabled": false,
+				"chart": map[string]any{
+					"url": "oci://registry.example.com/charts/cdc",
+				},
+			},
+			"docs": map[string]any{
+				"enabled": true,
+			},
+		},
+	})
+
+	if err := NewValidator(cfg).Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	steps, err := cfg.GetActiveSteps()
+	if err != nil {
+		t.Fatalf("GetActiveSteps() error = %v", err)
+	}
+
+	var ids []string
+	for _, step := range steps {
+		ids = append(ids, step.ID)
+	}
+	if got := fmt.Sprint(ids); got != "[install-authn composition-dynamic-controller finops]" {
+		t.Fatalf("step ids = %s, want the regular steps followed by the modules with a chart", got)
+	}
+
+	cdc, finops := steps[1], steps[2]
+	if !cdc.Skip || finops.Skip {
+		t.Fatalf("skip = %v, %v, want only the disabled module skipped", cdc.Skip, finops.Skip)
+	}
+	if finops.Type != "chart" || fmt.Sprint(finops.DependsOn) != "[install-authn]" {
+		t.Fatalf("finops step = %+v, want a chart step depending on install-authn", finops)
+	}
+
+	with := *finops.With
+	want := map[string]any{
+		"url":         "https://charts.krateo.io",
+		"repo":        "finops-moving-window-microservice",
+		"version":     "0.1.0",
+		"namespace":   "finops",
+		"releaseName": "finops",
+	}
+	for k, v := range want {
+		if with[k] != v {
+			t.Fatalf("with.%s = %v, want %v", k, with[k], v)
+		}
+	}
+	if values, _ := with["values"].(map[string]any); fmt.Sprint(values["replicaCount"]) != "2" {
+		t.Fatalf("with.values = %v, want the module values", with["values"])
+	}
+}
+
+func TestValidateModuleNameUsedByStep(t *testing.T) {
+	cfg := mustNewConfig(t, map[string]any{
+		"componentsDefinition": map[string]any{
+			"core": map[string]any{
+				"steps": []interface{}{"finops"},
+			},
+		},
+		"steps": []interface{}{
+			map[string]any{
+				"id":   "finops",
+				"type": "chart",
+			},
+		},
+		"modules": map[string]any{
+			"finops": map[string]any{
+				"chart": map[string]any{"url": "oci://registry.example.com/charts/finops"},
+			},
+		},
+	})
+
+	err := NewValidator(cfg).Validate()
+	if err == nil || !contains(err.Error(), "module finops: name is already used by a step") {
+		t.Fatalf("Validate() error = %v, want the name clash reported", err)
+	}
+}
+
+func componentRequiresConfig(overrides map[string]any) map[string]any {
+	data := map[string]any{
+		"componentsDefinition": map[string]any{
+			"authn": map[string]any{
+				"steps": []interface{}{"install-authn"},
+			},
+			"frontend": map[string]any{
+				"requires": []interface{}{"authn"},
+				"steps":    []interface{}{"install-frontend"},
+			},
+			"portal": map[string]any{
+				"requires": []interface{}{"frontend"},
+				"steps":    []interface{}{"install-portal"},
+			},
+		},
+		"steps": []interface{}{
+			map[string]any{"id": "install-authn", "type": "chart"},
+			map[string]any{"id": "install-frontend", "type": "chart"},
+			map[string]any{"id": "install-portal", "type": "chart"},
+		},
+	}
+	if overrides != nil {
+		data["components"] = overrides
+	}
+	return data
+}
+
+func TestGetEnabledComponentsRequires(t *testing.T) {
+	disabled := map[string]any{"enabled": false}
+	enabled := map[string]any{"enabled": true}
+
+	tests := []struct {
+		name       string
+		overrides  map[string]any
+		autoEnable bool
+		want       map[string]ComponentState
+	}{
+		{
+			name:      "keeps the dependents of a disabled component enabled",
+			overrides: map[string]any{"authn": disabled},
+			want: map[string]ComponentState{
+				"authn":    {},
+				"frontend": {Enabled: true},
+				"portal":   {Enabled: true},
+			},
+		},
+		{
+			name:      "keeps the dependents enabled in the overrides",
+			overrides: map[string]any{"authn": disabled, "frontend": enabled},
+			want: map[string]ComponentState{
+				"authn":    {},
+				"frontend": {Enabled: true},
+				"portal":   {Enabled: true},
+			},
+		},
+		{
+			name:       "enables the required components",
+			overrides:  map[string]any{"authn": disabled, "frontend": disabled, "portal": enabled},
+			autoEnable: true,
+			want: map[string]ComponentState{
+				"authn":    {Enabled: true, Reason: "enabled because frontend requires it"},
+				"frontend": {Enabled: true, Reason: "enabled because portal requires it"},
+				"portal":   {Enabled: true},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := mustNewConfig(t, componentRequiresConfig(tc.overrides)).WithAutoEnableDependencies(tc.autoEnable)
+
+			got, err := cfg.GetEnabledComponents()
+			if err != nil {
+				t.Fatalf("GetEnabledComponents() error = %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("GetEnabledComponents() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateComponentRelations(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]any
+		edit      func(defs map[string]any)
+		wantErr   string
+	}{
+		{
+			name:      "enabled component requiring a disabled one",
+			overrides: map[string]any{"authn": map[string]any{"enabled": false}, "frontend": map[string]any{"enabled": true}},
+			wantErr:   `component "frontend" requires "authn", which is disabled`,
+		},
+		{
+			name:      "component enabled by its definition requiring a disabled one",
+			overrides: map[string]any{"authn": map[string]any{"enabled": false}},
+			wantErr:   `component "frontend" requires "authn", which is disabled`,
+		},
+		{
+			name: "dependents disabled with the required component",
+			overrides: map[string]any{
+				"authn":    map[string]any{"enabled": false},
+				"frontend": map[string]any{"enabled": false},
+				"portal":   map[string]any{"enabled": false},
+			},
+		},
+		{
+			name: "requires cycle",
+			edit: func(defs map[string]any) {
+				defs["authn"].(map[string]any)["requires"] = []interface{}{"portal"}
+			},
+			wantErr: "component requires cycle detected: authn -> portal -> frontend -> authn",
+		},
+		{
+			name: "unknown required component",
+			edit: func(defs map[string]any) {
+				defs["authn"].(map[string]any)["requires"] = []interface{}{"events"}
+			},
+			wantErr: `component "authn" requires "events" which is not defined`,
+		},
+		{
+			name: "enabled conflicting components",
+			edit: func(defs map[string]any) {
+				defs["portal"].(map[string]any)["conflicts"] = []interface{}{"authn"}
+			},
+			wantErr: `component "portal" conflicts with "authn"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data := componentRequiresConfig(tc.overrides)
+			if tc.edit != nil {
+				tc.edit(data["componentsDefinition"].(map[string]any))
+			}
+
+			err := NewValidator(mustNewConfig(t, data)).Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
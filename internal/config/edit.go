@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetFileValue sets value at the path of keys in the YAML file, such as
+// components.frontend.enabled in krateo-overrides.yaml. The file and the missing
+// mappings are created as needed; the comments and the order of the existing
+// keys are kept.
+func SetFileValue(path string, keys []string, value any) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("path cannot be empty")
+	}
+
+	mode := fs.FileMode(0o644)
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if fi, err := os.Stat(path); err == nil {
+			mode = fi.Mode().Perm()
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if len(bytes.TrimSpace(content)) > 0 {
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return fmt.Errorf("failed to parse YAML from %s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{newMappingNode()}}
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+	if err := setNode(doc.Content[0], keys, &node); err != nil {
+		return fmt.Errorf("failed to set %s in %s: %w", strings.Join(keys, "."), path, err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	return os.WriteFile(path, buf.Bytes(), mode)
+}
+
+// setNode walks the mappings of node along keys, creating the missing ones, and
+// replaces the value found at the end. Empty values, such as "components:",
+// are turned into mappings.
+func setNode(node *yaml.Node, keys []string, value *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		*node = *newMappingNode()
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a mapping", keys[0])
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != keys[0] {
+			continue
+		}
+		if len(keys) == 1 {
+			value.LineComment = node.Content[i+1].LineComment
+			node.Content[i+1] = value
+			return nil
+		}
+		return setNode(node.Content[i+1], keys[1:], value)
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: keys[0]}
+	if len(keys) == 1 {
+		node.Content = append(node.Content, key, value)
+		return nil
+	}
+
+	child := newMappingNode()
+	node.Content = append(node.Content, key, child)
+	return setNode(child, keys[1:], value)
+}
+
+func newMappingNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
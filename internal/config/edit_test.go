@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetFileValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "krateo-overrides.yaml")
+	writeTestFile(t, path, `# Local overrides
+components:
+  # Keep the portal
+  frontend:
+    enabled: true # default
+  authn:
+    helmDefaults:
+      replicaCount: 2
+`)
+
+	if err := SetFileValue(path, []string{"components", "frontend", "enabled"}, false); err != nil {
+		t.Fatalf("SetFileValue() error = %v", err)
+	}
+	if err := SetFileValue(path, []string{"components", "authn", "enabled"}, false); err != nil {
+		t.Fatalf("SetFileValue() error = %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	want := `# Local overrides
+components:
+  # Keep the portal
+  frontend:
+    enabled: false # default
+  authn:
+    helmDefaults:
+      replicaCount: 2
+    enabled: false
+`
+	if string(got) != want {
+		t.Fatalf("file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSetFileValueCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "krateo-overrides.dev.yaml")
+
+	if err := SetFileValue(path, []string{"components", "finops", "enabled"}, true); err != nil {
+		t.Fatalf("SetFileValue() error = %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := "components:\n  finops:\n    enabled: true\n"; string(got) != want {
+		t.Fatalf("file =\n%s\nwant\n%s", got, want)
+	}
+}
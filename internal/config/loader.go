@@ -27,6 +27,9 @@ type LoadOptions struct {
 	// InstallationType is the deployment type (nodeport, loadbalancer, ingress)
 	// Used to select type-specific config files
 	InstallationType string
+	// AutoEnableDependencies enables the components required by the enabled
+	// ones (see Config.WithAutoEnableDependencies). The Loader does not use it.
+	AutoEnableDependencies bool
 }
 
 // Loader handles loading configuration from files.
@@ -47,29 +50,71 @@ func (l *Loader) Load() (map[string]any, error) {
 		return l.loadRemote()
 	}
 
+	sources, err := l.Sources()
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge order:
+	//   base config <- profile overrides <- base krateo-overrides.yaml
+	// so that krateo-overrides.yaml always has the last word.
+	config := sources[0].Data
+	for _, src := range sources[1:] {
+		if len(src.Data) > 0 {
+			if config, err = mergeOverrides(config, src.Data); err != nil {
+				return nil, fmt.Errorf("failed to merge %s: %w", src.Path, err)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// SourceKind tells which layer of the local configuration a Source belongs to.
+type SourceKind string
+
+const (
+	// SourceBase is krateo.yaml, or its installation type variant.
+	SourceBase SourceKind = "base"
+	// SourceProfile is a krateo-overrides.<profile>.yaml file, or a profile
+	// defined under the profiles section of krateo-overrides.yaml.
+	SourceProfile SourceKind = "profile"
+	// SourceOverride is krateo-overrides.yaml.
+	SourceOverride SourceKind = "override"
+)
+
+// Source is one of the documents merged by Load in local mode.
+type Source struct {
+	Kind SourceKind
+	// Path of the file. The in-file profiles report the overrides file.
+	Path string
+	// Profile is the name of a SourceProfile.
+	Profile string
+	// InFile is set for the profiles defined in the overrides file.
+	InFile bool
+	Data   map[string]any
+}
+
+// Sources returns, in merge order, the documents that Load merges in local mode:
+// the base configuration, the selected profiles and the user overrides, without
+// their profile and profiles keys.
+func (l *Loader) Sources() ([]Source, error) {
 	// Local mode: Load main config file from filesystem
 	// Try type-specific file first (e.g., krateo.nodeport.yaml), then fallback to generic krateo.yaml
-	config, err := l.loadConfigWithType(l.opts.ConfigPath, l.opts.InstallationType)
+	config, configPath, err := l.loadConfigWithType(l.opts.ConfigPath, l.opts.InstallationType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	sources := []Source{{Kind: SourceBase, Path: configPath, Data: config}}
 
 	// If no overrides path is configured, we're done.
 	if l.opts.UserOverridesPath == "" {
-		return config, nil
+		return sources, nil
 	}
 
-	// Load base overrides file if it exists. It's optional, but its directory
-	// is also used as the anchor for profile-specific override files
-	// (krateo-overrides.<profile>.yaml).
-	baseOverrides := make(map[string]any)
-	if fi, err := os.Stat(l.opts.UserOverridesPath); err == nil && !fi.IsDir() {
-		baseOverrides, err = l.loadFile(l.opts.UserOverridesPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load overrides from %s: %w", l.opts.UserOverridesPath, err)
-		}
-	} else if err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("failed to stat overrides file %s: %w", l.opts.UserOverridesPath, err)
+	baseOverrides, profilesMap, err := l.loadOverrides()
+	if err != nil {
+		return nil, err
 	}
 
 	// Determine effective profile list: CLI flag wins, otherwise fall back to
@@ -87,79 +132,68 @@ func (l *Loader) Load() (map[string]any, error) {
 	// before finally applying the base krateo-overrides.yaml. This ensures
 	// that krateo-overrides.yaml is applied *after* all profiles, so that
 	// top-level overrides win over any profile.
-	profileOverrides := make(map[string]any)
-	foundProfiles := make(map[string]bool)
-
-	if len(profiles) > 0 {
-		dir := filepath.Dir(l.opts.UserOverridesPath)
-		base := filepath.Base(l.opts.UserOverridesPath)
-		ext := filepath.Ext(base)
-		name := strings.TrimSuffix(base, ext)
-
-		var profilesMap map[string]any
-		if profilesRaw, ok := baseOverrides["profiles"]; ok {
-			var ok2 bool
-			profilesMap, ok2 = profilesRaw.(map[string]any)
-			if !ok2 {
-				return nil, fmt.Errorf("profiles must be a mapping, got %T", profilesRaw)
-			}
-		}
-
-		// 1) Profile-specific override files: krateo-overrides.<profile>.yaml
-		for _, p := range profiles {
-			profFile := fmt.Sprintf("%s.%s%s", name, p, ext)
-			profPath := filepath.Join(dir, profFile)
-
-			if fi, err := os.Stat(profPath); err == nil && !fi.IsDir() {
-				profData, err := l.loadFile(profPath)
-				if err != nil {
-					return nil, fmt.Errorf("failed to load profile overrides from %s: %w", profPath, err)
-				}
-				profileOverrides = mergeConfigs(profileOverrides, profData)
-				foundProfiles[p] = true
-			} else if err != nil && !os.IsNotExist(err) {
-				return nil, fmt.Errorf("failed to stat profile overrides file %s: %w", profPath, err)
-			}
-		}
-
-		// 2) In-file profiles defined inside base overrides (if any)
-		if profilesMap != nil {
-			for _, p := range profiles {
-				if entryRaw, ok := profilesMap[p]; ok {
-					entryMap, ok := entryRaw.(map[string]any)
-					if !ok {
-						return nil, fmt.Errorf("profile %q must be a mapping, got %T", p, entryRaw)
-					}
-					profileOverrides = mergeConfigs(profileOverrides, entryMap)
-					foundProfiles[p] = true
-				}
-			}
-		}
-
-		// 3) Validate that all requested profiles were found
-		for _, p := range profiles {
-			if !foundProfiles[p] {
-				return nil, l.profileNotFoundError(p)
-			}
-		}
+	profileSources, err := l.resolveProfiles(profiles, l.readLocalProfile, profilesMap)
+	if err != nil {
+		return nil, err
 	}
+	sources = append(sources, profileSources...)
 
 	// Remove profile metadata from the base overrides so it doesn't leak into
 	// the final configuration.
 	delete(baseOverrides, "profiles")
 	delete(baseOverrides, "profile")
 
-	// Merge order:
-	//   base config <- profile overrides <- base krateo-overrides.yaml
-	// so that krateo-overrides.yaml always has the last word.
-	if len(profileOverrides) > 0 {
-		config = mergeConfigs(config, profileOverrides)
+	return append(sources, Source{Kind: SourceOverride, Path: l.opts.UserOverridesPath, Data: baseOverrides}), nil
+}
+
+// loadOverrides reads krateo-overrides.yaml, when it exists, and returns it with
+// its in-file profiles. The file is optional, but its directory is also used as
+// the anchor for profile-specific override files (krateo-overrides.<profile>.yaml).
+func (l *Loader) loadOverrides() (map[string]any, map[string]any, error) {
+	baseOverrides := make(map[string]any)
+	if fi, err := os.Stat(l.opts.UserOverridesPath); err == nil && !fi.IsDir() {
+		baseOverrides, err = l.loadFile(l.opts.UserOverridesPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load overrides from %s: %w", l.opts.UserOverridesPath, err)
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to stat overrides file %s: %w", l.opts.UserOverridesPath, err)
 	}
-	if len(baseOverrides) > 0 {
-		config = mergeConfigs(config, baseOverrides)
+
+	profilesMap, err := inFileProfiles(baseOverrides)
+	if err != nil {
+		return nil, nil, err
 	}
+	return baseOverrides, profilesMap, nil
+}
 
-	return config, nil
+// readLocalProfile reads the krateo-overrides.<profile>.yaml file next to the
+// overrides file. It returns no data when the file does not exist.
+func (l *Loader) readLocalProfile(profile string) (map[string]any, string, error) {
+	profPath := ProfilePath(l.opts.UserOverridesPath, profile)
+
+	fi, err := os.Stat(profPath)
+	switch {
+	case err == nil && !fi.IsDir():
+		profData, err := l.loadFile(profPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load profile overrides from %s: %w", profPath, err)
+		}
+		return profData, profPath, nil
+	case err != nil && !os.IsNotExist(err):
+		return nil, "", fmt.Errorf("failed to stat profile overrides file %s: %w", profPath, err)
+	default:
+		return nil, "", nil
+	}
+}
+
+// ProfilePath returns the path of the krateo-overrides.<profile>.yaml file
+// next to the overrides file.
+func ProfilePath(overridesPath, profile string) string {
+	base := filepath.Base(overridesPath)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	return filepath.Join(filepath.Dir(overridesPath), fmt.Sprintf("%s.%s%s", name, profile, ext))
 }
 
 // loadRemote fetches configuration from a remote GitHub repository.
@@ -200,68 +234,39 @@ func (l *Loader) loadRemote() (map[string]any, error) {
 	}
 	profiles := parseProfiles(profileStr)
 
-	// Collect profile-specific overrides
-	profileOverrides := make(map[string]any)
-	foundProfiles := make(map[string]bool)
-
-	if len(profiles) > 0 {
-		var profilesMap map[string]any
-		if profilesRaw, ok := baseOverrides["profiles"]; ok {
-			var ok2 bool
-			profilesMap, ok2 = profilesRaw.(map[string]any)
-			if !ok2 {
-				return nil, fmt.Errorf("profiles must be a mapping, got %T", profilesRaw)
-			}
-		}
-
-		// Try to fetch profile-specific override files, fallback to local if not found remotely
-		for _, p := range profiles {
-			profFile := fmt.Sprintf("krateo-overrides.%s.yaml", p)
+	profilesMap, err := inFileProfiles(baseOverrides)
+	if err != nil {
+		return nil, err
+	}
 
-			// Try remote first
-			profData, err := l.loadRemoteFile(repo, l.opts.Version, profFile)
-			if err == nil {
-				profileOverrides = mergeConfigs(profileOverrides, profData)
-				foundProfiles[p] = true
-				continue
-			}
+	// Fetch the profile-specific override files, falling back to local files
+	// when they are not found remotely
+	readProfile := func(p string) (map[string]any, string, error) {
+		profFile := fmt.Sprintf("krateo-overrides.%s.yaml", p)
 
-			// Fallback to local if UserOverridesPath is specified
-			if l.opts.UserOverridesPath != "" {
-				dir := filepath.Dir(l.opts.UserOverridesPath)
-				localPath := filepath.Join(dir, profFile)
-
-				if fi, err := os.Stat(localPath); err == nil && !fi.IsDir() {
-					profData, err = l.loadFile(localPath)
-					if err != nil {
-						return nil, fmt.Errorf("failed to load local profile overrides from %s: %w", localPath, err)
-					}
-					profileOverrides = mergeConfigs(profileOverrides, profData)
-					foundProfiles[p] = true
-				}
-			}
+		// Try remote first
+		if profData, err := l.loadRemoteFile(repo, l.opts.Version, profFile); err == nil {
+			return profData, profFile, nil
 		}
 
-		// Check for in-file profiles defined inside base overrides
-		if profilesMap != nil {
-			for _, p := range profiles {
-				if entryRaw, ok := profilesMap[p]; ok {
-					entryMap, ok := entryRaw.(map[string]any)
-					if !ok {
-						return nil, fmt.Errorf("profile %q must be a mapping, got %T", p, entryRaw)
-					}
-					profileOverrides = mergeConfigs(profileOverrides, entryMap)
-					foundProfiles[p] = true
-				}
-			}
+		// Fallback to local if UserOverridesPath is specified
+		if l.opts.UserOverridesPath == "" {
+			return nil, "", nil
 		}
-
-		// Validate that all requested profiles were found
-		for _, p := range profiles {
-			if !foundProfiles[p] {
-				return nil, l.profileNotFoundError(p)
+		localPath := filepath.Join(filepath.Dir(l.opts.UserOverridesPath), profFile)
+		if fi, err := os.Stat(localPath); err == nil && !fi.IsDir() {
+			profData, err := l.loadFile(localPath)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to load local profile overrides from %s: %w", localPath, err)
 			}
+			return profData, localPath, nil
 		}
+		return nil, "", nil
+	}
+
+	profileSources, err := l.resolveProfiles(profiles, readProfile, profilesMap)
+	if err != nil {
+		return nil, err
 	}
 
 	// Remove profile metadata
@@ -269,11 +274,15 @@ func (l *Loader) loadRemote() (map[string]any, error) {
 	delete(baseOverrides, "profile")
 
 	// Merge configurations
-	if len(profileOverrides) > 0 {
-		config = mergeConfigs(config, profileOverrides)
+	for _, src := range profileSources {
+		if config, err = mergeOverrides(config, src.Data); err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %w", src.Path, err)
+		}
 	}
 	if len(baseOverrides) > 0 {
-		config = mergeConfigs(config, baseOverrides)
+		if config, err = mergeOverrides(config, baseOverrides); err != nil {
+			return nil, fmt.Errorf("failed to merge krateo-overrides.yaml: %w", err)
+		}
 	}
 
 	return config, nil
@@ -302,21 +311,23 @@ func (l *Loader) loadRemoteFile(repo, version, filename string) (map[string]any,
 
 // loadConfigWithType attempts to load type-specific config file first, then falls back to generic krateo.yaml
 // For example, if installType is "nodeport", it tries krateo.nodeport.yaml first, then krateo.yaml
-func (l *Loader) loadConfigWithType(basePath string, installType string) (map[string]any, error) {
+// The path of the file read is returned with its content.
+func (l *Loader) loadConfigWithType(basePath string, installType string) (map[string]any, string, error) {
 	if basePath == "" {
-		return make(map[string]any), nil
+		return make(map[string]any), "", nil
 	}
 
 	// Try type-specific variants first.
 	for _, candidate := range installationTypeCandidates(installType) {
 		typeSpecificPath := strings.TrimSuffix(basePath, filepath.Ext(basePath)) + "." + candidate + filepath.Ext(basePath)
 		if data, err := l.loadFile(typeSpecificPath); err == nil {
-			return data, nil
+			return data, typeSpecificPath, nil
 		}
 	}
 
 	// Fallback to generic krateo.yaml
-	return l.loadFile(basePath)
+	data, err := l.loadFile(basePath)
+	return data, basePath, err
 }
 
 // loadRemoteConfigWithType attempts to fetch type-specific config file first, then falls back to generic krateo.yaml
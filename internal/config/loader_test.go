@@ -234,6 +234,157 @@ steps:
 	}
 }
 
+func TestLoaderResolvesProfileExtends(t *testing.T) {
+	tests := []struct {
+		name            string
+		profiles        map[string]string
+		overrides       string
+		profile         string
+		want            map[string]any
+		wantErrContains string
+	}{
+		{
+			name: "applies extended profiles transitively before the profile",
+			profiles: map[string]string{
+				"base": "components:\n  a:\n    enabled: true\n  b:\n    enabled: true\n",
+				"prod": "extends: base\ncomponents:\n  b:\n    enabled: false\n",
+			},
+			overrides: `
+profiles:
+  prod-eu:
+    extends: [prod]
+    components:
+      c:
+        enabled: true
+`,
+			profile: "prod-eu",
+			want:    map[string]any{"a": true, "b": false, "c": true},
+		},
+		{
+			name: "applies an in-file parent before a file child",
+			profiles: map[string]string{
+				"prod-eu": "extends: [prod]\ncomponents:\n  finops:\n    enabled: true\n",
+			},
+			overrides: `
+profiles:
+  prod:
+    components:
+      finops:
+        enabled: false
+`,
+			profile: "prod-eu",
+			want:    map[string]any{"finops": true},
+		},
+		{
+			name: "applies the in-file profiles listed with --profile after the profile files",
+			profiles: map[string]string{
+				"b": "components:\n  finops:\n    enabled: false\n",
+			},
+			overrides: `
+profiles:
+  a:
+    components:
+      finops:
+        enabled: true
+`,
+			profile: "a,b",
+			want:    map[string]any{"finops": true},
+		},
+		{
+			name: "fails on an extends cycle",
+			profiles: map[string]string{
+				"a": "extends: [b]\n",
+				"b": "extends: [a]\n",
+			},
+			profile:         "a",
+			wantErrContains: "profile extends cycle detected: a -> b -> a",
+		},
+		{
+			name: "fails when an extended profile does not exist",
+			profiles: map[string]string{
+				"prod": "extends: [missing]\n",
+			},
+			profile:         "prod",
+			wantErrContains: "krateo-overrides.missing.yaml",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			configPath := filepath.Join(tmpDir, "krateo.yaml")
+			overridesPath := filepath.Join(tmpDir, "krateo-overrides.yaml")
+
+			writeTestFile(t, configPath, testLoaderConfig)
+			writeTestFile(t, overridesPath, tc.overrides)
+			for name, data := range tc.profiles {
+				writeTestFile(t, ProfilePath(overridesPath, name), data)
+			}
+
+			data, err := NewLoader(LoadOptions{
+				ConfigPath:        configPath,
+				UserOverridesPath: overridesPath,
+				Profile:           tc.profile,
+			}).Load()
+			if tc.wantErrContains != "" {
+				if err == nil || !contains(err.Error(), tc.wantErrContains) {
+					t.Fatalf("Load() error = %v, want %q", err, tc.wantErrContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+			if _, ok := data["extends"]; ok {
+				t.Fatalf("Load() kept extends in the configuration")
+			}
+
+			components := data["components"].(map[string]any)
+			for name, want := range tc.want {
+				if got := components[name].(map[string]any)["enabled"]; got != want {
+					t.Fatalf("components.%s.enabled = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeSourcesTracksProvenance(t *testing.T) {
+	tmpDir := t.TempDir()
+	overridesPath := filepath.Join(tmpDir, "krateo-overrides.yaml")
+	writeTestFile(t, overridesPath, "")
+	writeTestFile(t, ProfilePath(overridesPath, "base"), "components:\n  a:\n    enabled: true\n  b:\n    enabled: true\n")
+	writeTestFile(t, ProfilePath(overridesPath, "prod"), "extends: base\ncomponents:\n  b:\n    enabled: false\n")
+
+	loader := NewLoader(LoadOptions{UserOverridesPath: overridesPath})
+	sources, err := loader.ProfileSources("prod")
+	if err != nil {
+		t.Fatalf("ProfileSources() error = %v", err)
+	}
+	if len(sources) != 2 || sources[0].Profile != "base" || sources[1].Profile != "prod" {
+		t.Fatalf("ProfileSources() = %+v, want base then prod", sources)
+	}
+
+	_, provenance, err := MergeSources(sources)
+	if err != nil {
+		t.Fatalf("MergeSources() error = %v", err)
+	}
+	if got := provenance.Of("components", "a", "enabled"); got == nil || got.Profile != "base" {
+		t.Fatalf("components.a.enabled set by %+v, want base", got)
+	}
+	if got := provenance.Of("components", "b", "enabled"); got == nil || got.Profile != "prod" {
+		t.Fatalf("components.b.enabled set by %+v, want prod", got)
+	}
+
+	profiles, err := loader.Profiles()
+	if err != nil {
+		t.Fatalf("Profiles() error = %v", err)
+	}
+	if len(profiles) != 2 || profiles[1].Name != "prod" || len(profiles[1].Extends) != 1 || profiles[1].Extends[0] != "base" {
+		t.Fatalf("Profiles() = %+v, want base and prod extending base", profiles)
+	}
+}
+
 func writeTestFile(t *testing.T, path string, data string) {
 	t.Helper()
 
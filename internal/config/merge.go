@@ -0,0 +1,270 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	// patchKey tells how an override step applies to the step with its id.
+	patchKey = "$patch"
+
+	patchMerge   = "merge"
+	patchReplace = "replace"
+	patchDelete  = "delete"
+)
+
+// mergeOverrides merges an override document into the configuration. It is
+// mergeConfigs, except for the steps, which are merged by id with mergeSteps.
+func mergeOverrides(base, override map[string]any) (map[string]any, error) {
+	patches, ok := override["steps"].([]any)
+	if !ok {
+		return mergeConfigs(base, override), nil
+	}
+	baseSteps, _ := base["steps"].([]any)
+
+	steps, err := mergeSteps(baseSteps, patches)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := make(map[string]any, len(override))
+	for k, v := range override {
+		if k != "steps" {
+			rest[k] = v
+		}
+	}
+	base = mergeConfigs(base, rest)
+	base["steps"] = steps
+	return base, nil
+}
+
+// mergeSteps applies the steps of an override to the base steps by id, so that
+// an override only lists the steps it changes:
+//
+//	steps:
+//	  - id: install-authn     # merged into the base step
+//	    with:
+//	      values: {replicas: 2}
+//	  - id: install-finops    # removed
+//	    $patch: delete
+//	  - id: seed-data         # added right after install-authn
+//	    after: install-authn
+//	    type: object
+//
+// A step whose id is not in the base is appended, or inserted before or after
+// the step named by before or after, which also move an existing step. It must
+// have a type, so that a mistyped id fails instead of adding a step. The
+// $patch field of a step is merge (the default), replace or delete. A list
+// holding a single {$patch: replace} entry replaces the base steps altogether.
+func mergeSteps(base, patches []any) ([]any, error) {
+	if slices.ContainsFunc(patches, isReplaceAll) {
+		var steps []any
+		for _, raw := range patches {
+			if patch, ok := raw.(map[string]any); ok && !isReplaceAll(raw) {
+				steps = append(steps, stripDirectives(patch))
+			} else if !ok {
+				steps = append(steps, raw)
+			}
+		}
+		return steps, nil
+	}
+
+	steps := slices.Clone(base)
+	seen := make(map[string]bool, len(patches))
+	for i, raw := range patches {
+		patch, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("steps[%d] must be a mapping, got %T", i, raw)
+		}
+		id, _ := patch["id"].(string)
+		if id == "" {
+			return nil, fmt.Errorf("steps[%d]: id is required to merge the step into the base steps", i)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("step %q is listed more than once in the same override", id)
+		}
+		seen[id] = true
+
+		op, anchor, after, err := stepDirectives(id, patch)
+		if err != nil {
+			return nil, err
+		}
+
+		pos := stepIndex(steps, id)
+		if op == patchDelete {
+			if pos < 0 {
+				return nil, fmt.Errorf("step %q: cannot delete it, no base step has this id", id)
+			}
+			steps = slices.Delete(steps, pos, pos+1)
+			continue
+		}
+
+		step := stripDirectives(patch)
+		if pos >= 0 && op == patchMerge {
+			step = mergeConfigs(copyAnyValue(steps[pos]).(map[string]any), step)
+		}
+
+		if pos >= 0 && anchor == "" {
+			steps[pos] = step
+			continue
+		}
+		if pos >= 0 {
+			steps = slices.Delete(steps, pos, pos+1)
+		}
+
+		at := len(steps)
+		if anchor != "" {
+			if anchor == id {
+				return nil, fmt.Errorf("step %q cannot be positioned relative to itself", id)
+			}
+			if at = stepIndex(steps, anchor); at < 0 {
+				return nil, fmt.Errorf("step %q: no step %q to insert it %s", id, anchor, position(after))
+			}
+			if after {
+				at++
+			}
+		}
+		if _, ok := patch["type"]; pos < 0 && !ok {
+			return nil, fmt.Errorf("step %q: no base step has this id (a new step needs a type)", id)
+		}
+		steps = slices.Insert(steps, at, any(step))
+	}
+	return steps, nil
+}
+
+// mergeStepPatches combines the steps of two overrides, without a base to
+// apply them to, into the steps of a single override with the same effect.
+// The ids are checked against the steps of the first override when it replaces
+// the base steps; otherwise they may be base steps and are left to Load.
+func mergeStepPatches(first, second []any) ([]any, error) {
+	if slices.ContainsFunc(second, isReplaceAll) {
+		return slices.Clone(second), nil
+	}
+	if slices.ContainsFunc(first, isReplaceAll) {
+		// The first override sets the steps, so the second one applies to them
+		// as to base steps.
+		steps, err := mergeSteps(nil, first)
+		if err != nil {
+			return nil, err
+		}
+		merged, err := mergeSteps(steps, second)
+		if err != nil {
+			return nil, err
+		}
+		return append([]any{map[string]any{patchKey: patchReplace}}, merged...), nil
+	}
+
+	out := slices.Clone(first)
+	for _, raw := range second {
+		patch, ok := raw.(map[string]any)
+		if !ok {
+			out = append(out, raw)
+			continue
+		}
+		id, _ := patch["id"].(string)
+		pos := stepIndex(out, id)
+		if id == "" || pos < 0 {
+			out = append(out, patch)
+			continue
+		}
+		out[pos] = composeStepPatch(out[pos].(map[string]any), patch)
+	}
+	return out, nil
+}
+
+// composeStepPatch combines two patches of the same step. The directives of the
+// later patch replace the ones of the earlier patch: a delete drops it, a merge
+// into a deleted step recreates the step from the later patch alone, and a
+// before or after moves the step wherever the earlier patch put it.
+func composeStepPatch(earlier, later map[string]any) map[string]any {
+	if later[patchKey] == patchDelete {
+		return later
+	}
+
+	var out map[string]any
+	switch {
+	case later[patchKey] == patchReplace || earlier[patchKey] == patchDelete:
+		out = stripDirectives(later)
+		out[patchKey] = patchReplace
+	default:
+		out = mergeConfigs(copyAnyValue(stripDirectives(earlier)).(map[string]any), stripDirectives(later))
+		if earlier[patchKey] == patchReplace {
+			out[patchKey] = patchReplace
+		}
+	}
+
+	anchors := earlier
+	if _, ok := later["before"]; ok {
+		anchors = later
+	} else if _, ok := later["after"]; ok {
+		anchors = later
+	}
+	for _, key := range []string{"before", "after"} {
+		if v, ok := anchors[key]; ok {
+			out[key] = v
+		}
+	}
+	return out
+}
+
+// stepDirectives validates the merge fields of an override step and returns
+// its $patch and the step it is positioned before or after, if any.
+func stepDirectives(id string, patch map[string]any) (op, anchor string, after bool, err error) {
+	op = patchMerge
+	if v, ok := patch[patchKey]; ok {
+		switch v {
+		case patchMerge, patchReplace, patchDelete:
+			op = v.(string)
+		default:
+			return "", "", false, fmt.Errorf("step %q: unknown %s %v (expected: merge, replace or delete)", id, patchKey, v)
+		}
+	}
+
+	before, hasBefore := patch["before"]
+	afterRaw, hasAfter := patch["after"]
+	switch {
+	case hasBefore && hasAfter:
+		return "", "", false, fmt.Errorf("step %q: before and after are mutually exclusive", id)
+	case (hasBefore || hasAfter) && op == patchDelete:
+		return "", "", false, fmt.Errorf("step %q: a deleted step cannot be positioned", id)
+	case hasBefore:
+		anchor, _ = before.(string)
+	case hasAfter:
+		anchor, _ = afterRaw.(string)
+		after = true
+	}
+	if (hasBefore || hasAfter) && anchor == "" {
+		return "", "", false, fmt.Errorf("step %q: %s must be a step id", id, position(after))
+	}
+	return op, anchor, after, nil
+}
+
+func stripDirectives(patch map[string]any) map[string]any {
+	out := make(map[string]any, len(patch))
+	for k, v := range patch {
+		if k != patchKey && k != "before" && k != "after" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func isReplaceAll(raw any) bool {
+	m, ok := raw.(map[string]any)
+	return ok && len(m) == 1 && m[patchKey] == patchReplace
+}
+
+func stepIndex(steps []any, id string) int {
+	return slices.IndexFunc(steps, func(raw any) bool {
+		m, ok := raw.(map[string]any)
+		return ok && m["id"] == id
+	})
+}
+
+func position(after bool) string {
+	if after {
+		return "after"
+	}
+	return "before"
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testMergeBase = `
+steps:
+  - id: install-authn
+    type: chart
+    with:
+      releaseName: authn
+      values:
+        replicas: 1
+  - id: install-frontend
+    type: chart
+  - id: install-finops
+    type: chart
+`
+
+func TestMergeOverridesMergesStepsByID(t *testing.T) {
+	tests := []struct {
+		name            string
+		override        string
+		wantIDs         []string
+		wantErrContains string
+	}{
+		{
+			name: "patches a step in place and appends a new one",
+			override: `
+steps:
+  - id: install-authn
+    with:
+      values:
+        replicas: 2
+  - id: seed-data
+    type: object
+`,
+			wantIDs: []string{"install-authn", "install-frontend", "install-finops", "seed-data"},
+		},
+		{
+			name: "deletes a step",
+			override: `
+steps:
+  - id: install-finops
+    $patch: delete
+`,
+			wantIDs: []string{"install-authn", "install-frontend"},
+		},
+		{
+			name: "inserts and moves steps before and after others",
+			override: `
+steps:
+  - id: seed-data
+    type: object
+    after: install-authn
+  - id: install-finops
+    before: install-authn
+`,
+			wantIDs: []string{"install-finops", "install-authn", "seed-data", "install-frontend"},
+		},
+		{
+			name: "replaces the steps altogether",
+			override: `
+steps:
+  - $patch: replace
+  - id: only
+    type: var
+`,
+			wantIDs: []string{"only"},
+		},
+		{
+			name: "fails to delete an unknown step",
+			override: `
+steps:
+  - id: missing
+    $patch: delete
+`,
+			wantErrContains: `step "missing": cannot delete it, no base step has this id`,
+		},
+		{
+			name: "fails to patch an unknown step",
+			override: `
+steps:
+  - id: install-authm
+    with:
+      values:
+        replicas: 2
+`,
+			wantErrContains: `step "install-authm": no base step has this id`,
+		},
+		{
+			name: "fails to insert after an unknown step",
+			override: `
+steps:
+  - id: seed-data
+    after: missing
+`,
+			wantErrContains: `step "seed-data": no step "missing" to insert it after`,
+		},
+		{
+			name: "fails when a step is listed twice",
+			override: `
+steps:
+  - id: install-authn
+  - id: install-authn
+    $patch: delete
+`,
+			wantErrContains: `step "install-authn" is listed more than once in the same override`,
+		},
+		{
+			name: "fails when a step is both before and after others",
+			override: `
+steps:
+  - id: seed-data
+    before: install-authn
+    after: install-frontend
+`,
+			wantErrContains: `step "seed-data": before and after are mutually exclusive`,
+		},
+		{
+			name: "fails on a step without an id",
+			override: `
+steps:
+  - type: var
+`,
+			wantErrContains: "steps[0]: id is required",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var base, override map[string]any
+			if err := yaml.Unmarshal([]byte(testMergeBase), &base); err != nil {
+				t.Fatalf("Unmarshal(base) error = %v", err)
+			}
+			if err := yaml.Unmarshal([]byte(tc.override), &override); err != nil {
+				t.Fatalf("Unmarshal(override) error = %v", err)
+			}
+
+			merged, err := mergeOverrides(base, override)
+			if tc.wantErrContains != "" {
+				if err == nil || !contains(err.Error(), tc.wantErrContains) {
+					t.Fatalf("mergeOverrides() error = %v, want %q", err, tc.wantErrContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeOverrides() error = %v", err)
+			}
+
+			var ids []string
+			for _, raw := range merged["steps"].([]any) {
+				step := raw.(map[string]any)
+				for _, key := range []string{"$patch", "before", "after"} {
+					if _, ok := step[key]; ok {
+						t.Fatalf("step %v kept the %s merge field", step["id"], key)
+					}
+				}
+				ids = append(ids, step["id"].(string))
+			}
+			if !slices.Equal(ids, tc.wantIDs) {
+				t.Fatalf("step ids = %v, want %v", ids, tc.wantIDs)
+			}
+		})
+	}
+}
+
+func TestMergeOverridesKeepsBaseStepFields(t *testing.T) {
+	var base, override map[string]any
+	if err := yaml.Unmarshal([]byte(testMergeBase), &base); err != nil {
+		t.Fatalf("Unmarshal(base) error = %v", err)
+	}
+	if err := yaml.Unmarshal([]byte("steps:\n  - id: install-authn\n    with:\n      values:\n        replicas: 2\n"), &override); err != nil {
+		t.Fatalf("Unmarshal(override) error = %v", err)
+	}
+
+	merged, err := mergeOverrides(base, override)
+	if err != nil {
+		t.Fatalf("mergeOverrides() error = %v", err)
+	}
+
+	step := merged["steps"].([]any)[0].(map[string]any)
+	with := step["with"].(map[string]any)
+	if step["type"] != "chart" || with["releaseName"] != "authn" || with["values"].(map[string]any)["replicas"] != 2 {
+		t.Fatalf("merged step = %v, want the base chart step with 2 replicas", step)
+	}
+}
+
+func TestMergeStepPatchesReplacesEarlierDirectives(t *testing.T) {
+	tests := []struct {
+		name            string
+		first           string
+		second          string
+		want            string
+		wantErrContains string
+	}{
+		{
+			name:   "merges patches of the same step",
+			first:  "- id: install-authn\n  with: {values: {replicas: 2}}\n",
+			second: "- id: install-authn\n  with: {releaseName: authn}\n",
+			want:   "- id: install-authn\n  with: {releaseName: authn, values: {replicas: 2}}\n",
+		},
+		{
+			name:   "recreates a deleted step from the later patch",
+			first:  "- id: install-finops\n  $patch: delete\n",
+			second: "- id: install-finops\n  type: object\n",
+			want:   "- id: install-finops\n  type: object\n  $patch: replace\n",
+		},
+		{
+			name:   "deletes a patched step",
+			first:  "- id: install-authn\n  after: install-frontend\n  with: {values: {replicas: 2}}\n",
+			second: "- id: install-authn\n  $patch: delete\n",
+			want:   "- id: install-authn\n  $patch: delete\n",
+		},
+		{
+			name:   "moves the step where the later patch puts it",
+			first:  "- id: seed-data\n  after: install-authn\n  type: object\n",
+			second: "- id: seed-data\n  before: install-finops\n",
+			want:   "- id: seed-data\n  before: install-finops\n  type: object\n",
+		},
+		{
+			name:   "keeps the position of the earlier patch",
+			first:  "- id: seed-data\n  after: install-authn\n  type: object\n",
+			second: "- id: seed-data\n  with: {name: seed}\n",
+			want:   "- id: seed-data\n  after: install-authn\n  type: object\n  with: {name: seed}\n",
+		},
+		{
+			name:   "applies the later patches to replaced steps",
+			first:  "- $patch: replace\n- id: only\n  type: var\n- id: other\n  type: var\n",
+			second: "- id: other\n  $patch: delete\n- id: only\n  with: {name: ONLY}\n",
+			want:   "- $patch: replace\n- id: only\n  type: var\n  with: {name: ONLY}\n",
+		},
+		{
+			name:            "fails to patch a step missing from the replaced steps",
+			first:           "- $patch: replace\n- id: only\n  type: var\n",
+			second:          "- id: onyl\n  with: {name: ONLY}\n",
+			wantErrContains: `step "onyl": no base step has this id`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var first, second, want []any
+			for _, doc := range []struct {
+				data string
+				out  *[]any
+			}{{tc.first, &first}, {tc.second, &second}, {tc.want, &want}} {
+				if err := yaml.Unmarshal([]byte(doc.data), doc.out); err != nil {
+					t.Fatalf("Unmarshal(%q) error = %v", doc.data, err)
+				}
+			}
+
+			got, err := mergeStepPatches(first, second)
+			if tc.wantErrContains != "" {
+				if err == nil || !contains(err.Error(), tc.wantErrContains) {
+					t.Fatalf("mergeStepPatches() error = %v, want %q", err, tc.wantErrContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeStepPatches() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("mergeStepPatches() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+)
+
+// moduleSteps turns every module with a chart into a chart step named after the
+// module. The steps are sorted by module name so that, like the regular steps,
+// they keep a stable order when no dependsOn is declared.
+//
+//	modules:
+//	  finops:
+//	    dependsOn: [install-authn]
+//	    chart:
+//	      repository: https://charts.krateo.io
+//	      name: finops-moving-window-microservice
+//	      version: 0.1.0
+//	      namespace: krateo-system
+//	    values:
+//	      replicaCount: 2
+//
+// The disabled modules are generated too and skipped by GetActiveSteps, as the
+// steps of a disabled component.
+func (c *Config) moduleSteps() []StepDefinition {
+	if c.doc == nil || len(c.doc.Modules) == 0 {
+		return nil
+	}
+
+	var defs []StepDefinition
+	for _, name := range slices.Sorted(maps.Keys(c.doc.Modules)) {
+		mod := c.doc.Modules[name]
+		if mod.Chart == nil {
+			continue
+		}
+
+		with := map[string]any{
+			"url":         cmp.Or(mod.Chart.Repository, mod.Chart.URL),
+			"releaseName": cmp.Or(mod.Chart.ReleaseName, name),
+		}
+		if chart := cmp.Or(mod.Chart.Chart, mod.Chart.Name); chart != "" {
+			with["repo"] = chart
+		}
+		if mod.Chart.Version != "" {
+			with["version"] = mod.Chart.Version
+		}
+		if mod.Chart.Namespace != "" {
+			with["namespace"] = mod.Chart.Namespace
+		}
+		if len(mod.Values) > 0 {
+			with["values"] = mod.Values
+		}
+
+		defs = append(defs, StepDefinition{
+			ID:        name,
+			Type:      types.TypeChart,
+			With:      with,
+			DependsOn: mod.DependsOn,
+		})
+	}
+
+	return defs
+}
+
+// moduleEnabled reports whether the step is not generated by a disabled module.
+func (c *Config) moduleEnabled(stepID string) bool {
+	if c.doc == nil {
+		return true
+	}
+	mod, ok := c.doc.Modules[stepID]
+	if !ok || mod.Chart == nil || mod.Enabled == nil {
+		return true
+	}
+	return *mod.Enabled
+}
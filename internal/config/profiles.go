@@ -0,0 +1,326 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// profileReader returns the krateo-overrides.<profile>.yaml file of a profile
+// with its path, or no data when the file does not exist.
+type profileReader func(profile string) (map[string]any, string, error)
+
+// profileDefinition is what a profile is made of: its file, its entry in the
+// profiles section of the overrides file, or both.
+type profileDefinition struct {
+	file     map[string]any
+	filePath string
+	inFile   map[string]any
+	extends  []string
+}
+
+// inFileProfiles returns the profiles section of the overrides.
+func inFileProfiles(overrides map[string]any) (map[string]any, error) {
+	profilesRaw, ok := overrides["profiles"]
+	if !ok || profilesRaw == nil {
+		return nil, nil
+	}
+	profilesMap, ok := profilesRaw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("profiles must be a mapping, got %T", profilesRaw)
+	}
+	return profilesMap, nil
+}
+
+// resolveProfiles returns the sources of the profiles, each one preceded by the
+// profiles it extends, transitively:
+//
+//	# krateo-overrides.prod-eu.yaml
+//	extends: [prod]
+//	components:
+//	  ...
+//
+// A profile is applied once, where it first appears. The extended profiles come
+// first, each one as its file followed by its in-file entry, so that a profile
+// always wins over the profiles it extends. Then, as for profiles listed with
+// --profile, the profile files come first and the in-file profiles after them.
+func (l *Loader) resolveProfiles(profiles []string, read profileReader, profilesMap map[string]any) ([]Source, error) {
+	const (
+		visiting = iota + 1
+		done
+	)
+
+	defs := make(map[string]*profileDefinition)
+	marks := make(map[string]int)
+	extended := make(map[string]bool)
+	var order, path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch marks[name] {
+		case done:
+			return nil
+		case visiting:
+			cycle := append(slices.Clone(path[slices.Index(path, name):]), name)
+			return fmt.Errorf("profile extends cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+		marks[name] = visiting
+		path = append(path, name)
+
+		def, err := l.profileDefinition(name, read, profilesMap)
+		if err != nil {
+			return err
+		}
+		defs[name] = def
+
+		for _, parent := range def.extends {
+			extended[parent] = true
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		marks[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, p := range profiles {
+		if err := visit(p); err != nil {
+			return nil, err
+		}
+	}
+
+	var sources []Source
+	fileSource := func(p string) {
+		if def := defs[p]; def.file != nil {
+			sources = append(sources, Source{Kind: SourceProfile, Path: def.filePath, Profile: p, Data: def.file})
+		}
+	}
+	inFileSource := func(p string) {
+		if def := defs[p]; def.inFile != nil {
+			sources = append(sources, Source{Kind: SourceProfile, Path: l.opts.UserOverridesPath, Profile: p, InFile: true, Data: def.inFile})
+		}
+	}
+
+	// 1) Extended profiles, in extends order, each one whole
+	for _, p := range order {
+		if extended[p] {
+			fileSource(p)
+			inFileSource(p)
+		}
+	}
+	// 2) Profile-specific override files: krateo-overrides.<profile>.yaml
+	for _, p := range order {
+		if !extended[p] {
+			fileSource(p)
+		}
+	}
+	// 3) In-file profiles defined inside base overrides (if any)
+	for _, p := range order {
+		if !extended[p] {
+			inFileSource(p)
+		}
+	}
+	return sources, nil
+}
+
+// profileDefinition reads the file and the in-file entry of a profile, and
+// removes their extends from the data merged into the configuration.
+func (l *Loader) profileDefinition(name string, read profileReader, profilesMap map[string]any) (*profileDefinition, error) {
+	def := &profileDefinition{}
+
+	data, path, err := read(name)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		def.file, def.filePath = data, path
+	}
+
+	if entryRaw, ok := profilesMap[name]; ok {
+		entryMap, ok := entryRaw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("profile %q must be a mapping, got %T", name, entryRaw)
+		}
+		def.inFile = maps.Clone(entryMap)
+	}
+
+	if def.file == nil && def.inFile == nil {
+		return nil, l.profileNotFoundError(name)
+	}
+
+	for _, data := range []map[string]any{def.file, def.inFile} {
+		extends, err := profileExtends(name, data)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range extends {
+			if !slices.Contains(def.extends, parent) {
+				def.extends = append(def.extends, parent)
+			}
+		}
+		delete(data, "extends")
+	}
+
+	return def, nil
+}
+
+// profileExtends returns the extends of a profile, a profile name or a list of them.
+func profileExtends(name string, data map[string]any) ([]string, error) {
+	switch v := data["extends"].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return parseProfiles(v), nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			parent, ok := item.(string)
+			if !ok || strings.TrimSpace(parent) == "" {
+				return nil, fmt.Errorf("profile %q: extends must list profile names, got %v", name, item)
+			}
+			out = append(out, strings.TrimSpace(parent))
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("profile %q: extends must be a profile name or a list of them, got %T", name, v)
+	}
+}
+
+// Profile describes a profile available in local mode.
+type Profile struct {
+	Name string
+	// Paths of the profile file and of the overrides file defining it in-file.
+	Paths   []string
+	Extends []string
+}
+
+// Profiles lists, sorted by name, the profiles defined next to the overrides
+// file, as krateo-overrides.<profile>.yaml files or in its profiles section.
+func (l *Loader) Profiles() ([]Profile, error) {
+	if l.opts.UserOverridesPath == "" {
+		return nil, nil
+	}
+
+	_, profilesMap, err := l.loadOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Base(l.opts.UserOverridesPath)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "."
+
+	names := slices.Collect(maps.Keys(profilesMap))
+	entries, err := os.ReadDir(filepath.Dir(l.opts.UserOverridesPath))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list profile files: %w", err)
+	}
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || len(file) <= len(prefix)+len(ext) || !strings.HasPrefix(file, prefix) || !strings.HasSuffix(file, ext) {
+			continue
+		}
+		if name := file[len(prefix) : len(file)-len(ext)]; !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	out := make([]Profile, 0, len(names))
+	for _, name := range names {
+		def, err := l.profileDefinition(name, l.readLocalProfile, profilesMap)
+		if err != nil {
+			return nil, err
+		}
+		profile := Profile{Name: name, Extends: def.extends}
+		if def.file != nil {
+			profile.Paths = append(profile.Paths, def.filePath)
+		}
+		if def.inFile != nil {
+			profile.Paths = append(profile.Paths, l.opts.UserOverridesPath)
+		}
+		out = append(out, profile)
+	}
+	return out, nil
+}
+
+// ProfileSources returns, in merge order, the sources the profile is made of in
+// local mode: the profiles it extends, transitively, and its own.
+func (l *Loader) ProfileSources(name string) ([]Source, error) {
+	_, profilesMap, err := l.loadOverrides()
+	if err != nil {
+		return nil, err
+	}
+	return l.resolveProfiles([]string{name}, l.readLocalProfile, profilesMap)
+}
+
+// Provenance maps the path of every merged value to the source that set it.
+type Provenance map[string]*Source
+
+// Of returns the source of the value at the path of keys, or nil.
+func (p Provenance) Of(keys ...string) *Source {
+	return p[strings.Join(keys, "\x00")]
+}
+
+// MergeSources merges the sources as Load does, keeping the steps as a single
+// override of the base steps, and tracks, for every value that is not a mapping,
+// the source it comes from.
+func MergeSources(sources []Source) (map[string]any, Provenance, error) {
+	merged := make(map[string]any)
+	provenance := make(Provenance)
+	for i := range sources {
+		trackProvenance(provenance, nil, sources[i].Data, &sources[i])
+		data := copyAnyValue(sources[i].Data).(map[string]any)
+		if steps, ok := data["steps"].([]any); ok {
+			prev, _ := merged["steps"].([]any)
+			combined, err := mergeStepPatches(prev, steps)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", sources[i].Path, err)
+			}
+			data["steps"] = combined
+		}
+		merged = mergeConfigs(merged, data)
+	}
+	return merged, provenance, nil
+}
+
+func trackProvenance(provenance Provenance, path []string, data map[string]any, src *Source) {
+	for key, val := range data {
+		keys := append(slices.Clone(path), key)
+		prefix := strings.Join(keys, "\x00")
+
+		if m, ok := val.(map[string]any); ok {
+			delete(provenance, prefix)
+			trackProvenance(provenance, keys, m, src)
+			continue
+		}
+
+		// A value that is not a mapping replaces whatever was set below it.
+		for k := range provenance {
+			if strings.HasPrefix(k, prefix+"\x00") {
+				delete(provenance, k)
+			}
+		}
+		provenance[prefix] = src
+	}
+}
+
+// copyAnyValue deep copies the maps of a decoded document, so that merging does
+// not alter the sources.
+func copyAnyValue(value any) any {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = copyAnyValue(v)
+	}
+	return out
+}
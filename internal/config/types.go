@@ -8,35 +8,60 @@ type Document struct {
 	ComponentsDefinition map[string]ComponentConfig `json:"componentsDefinition,omitempty" yaml:"componentsDefinition,omitempty"`
 	Components           map[string]ComponentConfig `json:"components,omitempty" yaml:"components,omitempty"`
 	Steps                []StepDefinition           `json:"steps,omitempty" yaml:"steps,omitempty"`
+	StepDefaults         *StepDefaults              `json:"stepDefaults,omitempty" yaml:"stepDefaults,omitempty"`
+	HealthChecks         []types.HealthCheck        `json:"healthChecks,omitempty" yaml:"healthChecks,omitempty"`
 }
 
-// ModuleConfig describes a single module entry in the configuration.
+// StepDefaults holds settings applied to every step that does not define its own.
+type StepDefaults struct {
+	Retry   *types.RetryPolicy `json:"retry,omitempty" yaml:"retry,omitempty"`
+	Timeout string             `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// ModuleConfig describes a single module entry in the configuration. A module
+// with a chart is installed by a chart step whose ID is the module name.
 type ModuleConfig struct {
 	Enabled *bool        `json:"enabled,omitempty" yaml:"enabled,omitempty"`
 	Chart   *ModuleChart `json:"chart,omitempty" yaml:"chart,omitempty"`
+	// Values are the Helm values of the release.
+	Values map[string]interface{} `json:"values,omitempty" yaml:"values,omitempty"`
+	// DependsOn lists the steps, or other modules, installed before the module.
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
 }
 
 // ModuleChart contains the chart coordinates for a module.
 type ModuleChart struct {
 	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
 	URL        string `json:"url,omitempty" yaml:"url,omitempty"`
-	Name       string `json:"name,omitempty" yaml:"name,omitempty"`
-	Chart      string `json:"chart,omitempty" yaml:"chart,omitempty"`
-	Namespace  string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Name and Chart are alternative spellings of the chart name.
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	Chart     string `json:"chart,omitempty" yaml:"chart,omitempty"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// ReleaseName defaults to the module name.
+	ReleaseName string `json:"releaseName,omitempty" yaml:"releaseName,omitempty"`
 }
 
 // ComponentConfig captures the metadata and overrides for a logical component.
 type ComponentConfig struct {
-	Description  string                            `json:"description,omitempty" yaml:"description,omitempty"`
-	Enabled      *bool                             `json:"enabled,omitempty" yaml:"enabled,omitempty"`
-	Steps        []string                          `json:"steps,omitempty" yaml:"steps,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Enabled     *bool    `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Steps       []string `json:"steps,omitempty" yaml:"steps,omitempty"`
+	// Requires lists the components that must be enabled with this one.
+	Requires []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	// Conflicts lists the components that cannot be enabled with this one.
+	Conflicts    []string                          `json:"conflicts,omitempty" yaml:"conflicts,omitempty"`
 	HelmDefaults map[string]interface{}            `json:"helmDefaults,omitempty" yaml:"helmDefaults,omitempty"`
 	StepConfig   map[string]map[string]interface{} `json:"stepConfig,omitempty" yaml:"stepConfig,omitempty"`
 }
 
 // StepDefinition represents a single workflow step as defined in krateo.yaml.
 type StepDefinition struct {
-	ID   string                 `json:"id" yaml:"id"`
-	Type types.StepType         `json:"type" yaml:"type"`
-	With map[string]interface{} `json:"with,omitempty" yaml:"with,omitempty"`
+	ID        string                 `json:"id" yaml:"id"`
+	Type      types.StepType         `json:"type" yaml:"type"`
+	With      map[string]interface{} `json:"with,omitempty" yaml:"with,omitempty"`
+	DependsOn []string               `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	When      string                 `json:"when,omitempty" yaml:"when,omitempty"`
+	Retry     *types.RetryPolicy     `json:"retry,omitempty" yaml:"retry,omitempty"`
+	Timeout   string                 `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
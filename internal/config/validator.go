@@ -2,6 +2,12 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/itchyny/gojq"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
 )
 
 // Validator performs validation on the configuration.
@@ -38,6 +44,11 @@ func (v *Validator) Validate() error {
 		return err
 	}
 
+	// Validate component requires and conflicts against the resolved enablement
+	if err := v.validateComponentRelations(); err != nil {
+		return err
+	}
+
 	// Validate that StepConfig keys reference valid steps
 	if err := v.validateStepConfigReferences(); err != nil {
 		return err
@@ -48,6 +59,31 @@ func (v *Validator) Validate() error {
 		return err
 	}
 
+	// Validate dependsOn references and make sure the step graph has no cycles
+	if err := v.validateStepDependencies(); err != nil {
+		return err
+	}
+
+	// Validate that when conditions are well formed jq expressions
+	if err := v.validateStepConditions(); err != nil {
+		return err
+	}
+
+	// Validate retry policies, both the global default and the per-step ones
+	if err := v.validateRetryPolicies(); err != nil {
+		return err
+	}
+
+	// Validate step timeouts, both the global default and the per-step ones
+	if err := v.validateStepTimeouts(); err != nil {
+		return err
+	}
+
+	// Validate the user-defined health checks
+	if err := v.validateHealthChecks(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -68,20 +104,31 @@ func (v *Validator) validateModule(name string, mod ModuleConfig) error {
 		return fmt.Errorf("module name cannot be empty")
 	}
 
-	// Check if module has chart config
-	if mod.Chart != nil {
-		hasRepo := mod.Chart.Repository != ""
-		hasURL := mod.Chart.URL != ""
-		if !hasRepo && !hasURL {
-			return fmt.Errorf("module %s: chart must have repository or url", name)
-		}
-		if hasRepo {
-			if mod.Chart.Name == "" && mod.Chart.Chart == "" {
-				return fmt.Errorf("module %s: chart name is required when repository is specified", name)
+	if mod.Chart == nil {
+		v.logWarning("module %s has no chart and installs nothing", name)
+		return nil
+	}
+
+	// The module is installed by a chart step named after it
+	if v.config.doc != nil {
+		for _, step := range v.config.doc.Steps {
+			if step.ID == name {
+				return fmt.Errorf("module %s: name is already used by a step", name)
 			}
 		}
 	}
 
+	hasRepo := mod.Chart.Repository != ""
+	hasURL := mod.Chart.URL != ""
+	if !hasRepo && !hasURL {
+		return fmt.Errorf("module %s: chart must have repository or url", name)
+	}
+	if hasRepo {
+		if mod.Chart.Name == "" && mod.Chart.Chart == "" {
+			return fmt.Errorf("module %s: chart name is required when repository is specified", name)
+		}
+	}
+
 	return nil
 }
 
@@ -159,6 +206,102 @@ func (v *Validator) validateComponentSteps() error {
 	return nil
 }
 
+// validateComponentRelations ensures that requires and conflicts reference
+// defined components, that the requires are acyclic, and that no enabled
+// component requires a disabled one or conflicts with an enabled one.
+func (v *Validator) validateComponentRelations() error {
+	definitions := v.config.componentDefinitions()
+	if len(definitions) == 0 {
+		return nil
+	}
+
+	names := slices.Sorted(maps.Keys(definitions))
+	for _, name := range names {
+		for _, dep := range v.config.componentRequires(name) {
+			if _, ok := definitions[dep]; !ok {
+				return fmt.Errorf("component %q requires %q which is not defined", name, dep)
+			}
+			if dep == name {
+				return fmt.Errorf("component %q cannot require itself", name)
+			}
+		}
+		for _, other := range v.config.componentConflicts(name) {
+			if _, ok := definitions[other]; !ok {
+				return fmt.Errorf("component %q conflicts with %q which is not defined", name, other)
+			}
+		}
+	}
+
+	if cycle := v.findRequiresCycle(names); len(cycle) > 0 {
+		return fmt.Errorf("component requires cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	states, err := v.config.GetEnabledComponents()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if !states[name].Enabled {
+			continue
+		}
+		for _, dep := range v.config.componentRequires(name) {
+			if !states[dep].Enabled {
+				return fmt.Errorf("component %q requires %q, which is disabled: enable it or use --auto-enable-dependencies", name, dep)
+			}
+		}
+		for _, other := range v.config.componentConflicts(name) {
+			if states[other].Enabled {
+				return fmt.Errorf("component %q conflicts with %q and they cannot be enabled together", name, other)
+			}
+		}
+	}
+
+	return nil
+}
+
+// findRequiresCycle returns the first requires cycle found, as the list of the
+// components involved with the first one repeated at the end.
+func (v *Validator) findRequiresCycle(names []string) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	marks := make(map[string]int, len(names))
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		marks[name] = visiting
+		path = append(path, name)
+		for _, dep := range v.config.componentRequires(name) {
+			switch marks[dep] {
+			case visiting:
+				start := slices.Index(path, dep)
+				return append(slices.Clone(path[start:]), dep)
+			case unvisited:
+				if cycle := visit(dep); len(cycle) > 0 {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		marks[name] = done
+		return nil
+	}
+
+	for _, name := range names {
+		if marks[name] == unvisited {
+			if cycle := visit(name); len(cycle) > 0 {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
 // validateStepConfigReferences validates that all keys in StepConfig reference actual steps
 // that belong to the component. Returns an error if any step config keys don't correspond
 // to steps defined in the component's Steps array.
@@ -323,6 +466,116 @@ func (v *Validator) validateOrphanedSteps() error {
 	return nil
 }
 
+// validateStepDependencies ensures that every dependsOn entry references an existing
+// step and that the resulting dependency graph is acyclic.
+func (v *Validator) validateStepDependencies() error {
+	steps, err := v.config.GetSteps()
+	if err != nil {
+		return err
+	}
+
+	if _, err := types.ResolveDependencies(steps, false); err != nil {
+		return fmt.Errorf("invalid step dependencies: %w", err)
+	}
+
+	return nil
+}
+
+// validateStepConditions ensures that every when condition can be parsed as a jq expression.
+func (v *Validator) validateStepConditions() error {
+	steps, err := v.config.GetSteps()
+	if err != nil {
+		return err
+	}
+
+	var invalid []string
+	for _, step := range steps {
+		if step.When == "" {
+			continue
+		}
+		if _, err := gojq.Parse(step.When); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", step.ID, err))
+		}
+	}
+
+	if len(invalid) > 0 {
+		if len(invalid) == 1 {
+			return fmt.Errorf("invalid when condition in step %s", invalid[0])
+		}
+
+		errMsg := "the following steps have an invalid when condition:\n"
+		for _, entry := range invalid {
+			errMsg += fmt.Sprintf("  - %s\n", entry)
+		}
+		return fmt.Errorf("%s", errMsg)
+	}
+
+	return nil
+}
+
+// validateRetryPolicies ensures that the default and per-step retry policies are well formed.
+func (v *Validator) validateRetryPolicies() error {
+	if v.config.doc == nil {
+		return nil
+	}
+
+	if defaults := v.config.doc.StepDefaults; defaults != nil {
+		if err := defaults.Retry.Validate(); err != nil {
+			return fmt.Errorf("invalid stepDefaults.retry: %w", err)
+		}
+	}
+
+	for _, step := range v.config.doc.Steps {
+		if err := step.Retry.Validate(); err != nil {
+			return fmt.Errorf("invalid retry policy in step %s: %w", step.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// validateStepTimeouts ensures that the default and per-step timeouts are positive durations.
+func (v *Validator) validateStepTimeouts() error {
+	if v.config.doc == nil {
+		return nil
+	}
+
+	if defaults := v.config.doc.StepDefaults; defaults != nil {
+		if err := types.ValidateTimeout(defaults.Timeout); err != nil {
+			return fmt.Errorf("invalid stepDefaults.timeout: %w", err)
+		}
+	}
+
+	for _, step := range v.config.doc.Steps {
+		if err := types.ValidateTimeout(step.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout in step %s: %w", step.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// validateHealthChecks ensures that every health check names a kind once and has a valid jq expression.
+func (v *Validator) validateHealthChecks() error {
+	if v.config.doc == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for i, check := range v.config.doc.HealthChecks {
+		if err := check.Validate(); err != nil {
+			return fmt.Errorf("invalid healthChecks[%d]: %w", i, err)
+		}
+		key := check.APIVersion + "/" + check.Kind
+		if seen[key] {
+			return fmt.Errorf("duplicate health check for %s %s", check.APIVersion, check.Kind)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
 // logWarning logs a warning message if a logger is available.
 func (v *Validator) logWarning(msg string, args ...any) {
 	if v.logger != nil {
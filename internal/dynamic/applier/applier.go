@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -52,11 +53,20 @@ type ApplyOptions struct {
 	GVK       schema.GroupVersionKind
 	Namespace string
 	Name      string
+	// DryRun asks the API server to validate and admit the object, running the
+	// admission webhooks, without persisting it.
+	DryRun bool
 }
 
 func (a *Applier) Apply(ctx context.Context, content map[string]any, opts ApplyOptions) error {
+	_, err := a.ApplyObject(ctx, content, opts)
+	return err
+}
+
+// ApplyObject is like Apply but also returns the object as stored by the API server.
+func (a *Applier) ApplyObject(ctx context.Context, content map[string]any, opts ApplyOptions) (*unstructured.Unstructured, error) {
 	if len(content) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	obj := unstructured.Unstructured{}
@@ -65,29 +75,77 @@ func (a *Applier) Apply(ctx context.Context, content map[string]any, opts ApplyO
 	obj.SetNamespace(opts.Namespace)
 	obj.SetName(opts.Name)
 
-	restMapping, err := a.mapper.RESTMapping(opts.GVK.GroupKind(), opts.GVK.Version)
+	ri, err := a.resource(opts.GVK, opts.Namespace)
 	if err != nil {
-		return err
-	}
-
-	var ri dynamic.ResourceInterface
-	if restMapping.Scope.Name() == meta.RESTScopeNameRoot {
-		ri = a.dynamicClient.Resource(restMapping.Resource)
-	} else {
-		ri = a.dynamicClient.Resource(restMapping.Resource).
-			Namespace(opts.Namespace)
+		return nil, err
 	}
 
 	data, err := json.Marshal(&obj)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// create or Update the object with SSA (types.ApplyPatchType indicates SSA).
-	_, err = ri.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+	patchOpts := metav1.PatchOptions{
 		FieldManager: InstalledByValue,
 		Force:        ptr.To(true),
-	})
+	}
+	if opts.DryRun {
+		patchOpts.DryRun = []string{metav1.DryRunAll}
+	}
 
-	return err
+	// create or Update the object with SSA (types.ApplyPatchType indicates SSA).
+	return ri.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, patchOpts)
+}
+
+// DryRun applies the object in server side dry-run mode and reports whether the
+// apply would create it, change it or leave it as it is.
+func (a *Applier) DryRun(ctx context.Context, content map[string]any, opts ApplyOptions) (Change, error) {
+	ri, err := a.resource(opts.GVK, opts.Namespace)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := ri.Get(ctx, opts.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		current, err = nil, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	opts.DryRun = true
+	applied, err := a.ApplyObject(ctx, content, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return changeOf(current, applied), nil
+}
+
+func (a *Applier) resource(gvk schema.GroupVersionKind, namespace string) (dynamic.ResourceInterface, error) {
+	restMapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if restMapping.Scope.Name() == meta.RESTScopeNameRoot {
+		return a.dynamicClient.Resource(restMapping.Resource), nil
+	}
+	return a.dynamicClient.Resource(restMapping.Resource).Namespace(namespace), nil
+}
+
+// IsNamespaced reports whether the given kind is namespace scoped, according to
+// the discovery information of the cluster.
+func (a *Applier) IsNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	restMapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// The kind may belong to a CRD applied earlier in the same workflow.
+		a.mapper.Reset()
+		restMapping, err = a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return restMapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
 }
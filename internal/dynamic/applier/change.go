@@ -0,0 +1,55 @@
+package applier
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Change is what applying an object would do to the cluster, as reported by
+// a server side dry run.
+type Change string
+
+const (
+	ChangeCreate    Change = "create"
+	ChangeUpdate    Change = "update"
+	ChangeUnchanged Change = "unchanged"
+)
+
+// volatileFields are bumped by the API server on every write, or by the dry
+// run itself, and say nothing about the content of the object.
+var volatileFields = [][]string{
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+}
+
+// changeOf compares the object stored in the cluster, nil when it does not
+// exist yet, with the one returned by the dry-run apply.
+func changeOf(current, applied *unstructured.Unstructured) Change {
+	if current == nil {
+		return ChangeCreate
+	}
+	if applied == nil {
+		return ChangeUnchanged
+	}
+
+	before, after := current.DeepCopy(), applied.DeepCopy()
+	for _, fields := range volatileFields {
+		unstructured.RemoveNestedField(before.Object, fields...)
+		unstructured.RemoveNestedField(after.Object, fields...)
+	}
+
+	if equality.Semantic.DeepEqual(before.Object, after.Object) {
+		return ChangeUnchanged
+	}
+	return ChangeUpdate
+}
+
+// IsUnverifiable reports whether a dry-run error comes from a missing kind or
+// namespace rather than from a rejection of the object: a real run would create
+// them in an earlier step, a dry run cannot.
+func IsUnverifiable(err error) bool {
+	return meta.IsNoMatchError(err) || apierrors.IsNotFound(err)
+}
@@ -0,0 +1,56 @@
+package applier
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestChangeOf(t *testing.T) {
+	configMap := func(resourceVersion, value string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":            "settings",
+				"namespace":       "krateo-system",
+				"resourceVersion": resourceVersion,
+				"managedFields":   []any{map[string]any{"manager": "krateo", "time": resourceVersion}},
+			},
+			"data": map[string]any{"mode": value},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		current *unstructured.Unstructured
+		applied *unstructured.Unstructured
+		want    Change
+	}{
+		{
+			name:    "missing object is created",
+			applied: configMap("1", "fast"),
+			want:    ChangeCreate,
+		},
+		{
+			name:    "same content is unchanged",
+			current: configMap("1", "fast"),
+			applied: configMap("2", "fast"),
+			want:    ChangeUnchanged,
+		},
+		{
+			name:    "different content is updated",
+			current: configMap("1", "fast"),
+			applied: configMap("2", "safe"),
+			want:    ChangeUpdate,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := changeOf(tc.current, tc.applied); got != tc.want {
+				t.Fatalf("changeOf() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -18,6 +18,9 @@ type DeleteOptions struct {
 	GVK       schema.GroupVersionKind
 	Namespace string
 	Name      string
+	// DryRun asks the API server to validate the deletion, running the
+	// admission webhooks, without removing the object.
+	DryRun bool
 }
 
 func NewDeletor(rc *rest.Config) (*Deletor, error) {
@@ -60,7 +63,12 @@ func (g *Deletor) Delete(ctx context.Context, opts DeleteOptions) error {
 			Namespace(opts.Namespace)
 	}
 
-	return ri.Delete(ctx, opts.Name, metav1.DeleteOptions{
+	deleteOpts := metav1.DeleteOptions{
 		PropagationPolicy: ptr.To(metav1.DeletePropagationForeground),
-	})
+	}
+	if opts.DryRun {
+		deleteOpts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	return ri.Delete(ctx, opts.Name, deleteOpts)
 }
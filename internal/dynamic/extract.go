@@ -44,3 +44,12 @@ func Extract(ctx context.Context, obj *unstructured.Unstructured, filter string)
 	err = json.NewDecoder(buf).Decode(&xxx)
 	return xxx, err
 }
+
+// Truthy reports whether a value returned by Extract satisfies a jq condition:
+// everything except false and null is true.
+func Truthy(v any) bool {
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return v != nil
+}
@@ -48,6 +48,11 @@ type Getter struct {
 
 func (g *Getter) Get(ctx context.Context, opts GetOptions) (*unstructured.Unstructured, error) {
 	restMapping, err := g.mapper.RESTMapping(opts.GVK.GroupKind(), opts.GVK.Version)
+	if meta.IsNoMatchError(err) {
+		// The kind may belong to a CRD registered after discovery was cached.
+		g.mapper.Reset()
+		restMapping, err = g.mapper.RESTMapping(opts.GVK.GroupKind(), opts.GVK.Version)
+	}
 	if err != nil {
 		return nil, err
 	}
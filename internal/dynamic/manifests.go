@@ -0,0 +1,34 @@
+package dynamic
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ParseManifests decodes a stream of YAML or JSON documents. Empty documents and
+// documents without a kind are skipped; source is only used in error messages.
+func ParseManifests(content []byte, source string) ([]*unstructured.Unstructured, error) {
+	var manifests []*unstructured.Unstructured
+	decoder := kyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+
+	for {
+		obj := &unstructured.Unstructured{}
+		err := decoder.Decode(obj)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("parse YAML in %s: %w", source, err)
+		}
+		if obj.GetKind() == "" {
+			continue
+		}
+		manifests = append(manifests, obj)
+	}
+
+	return manifests, nil
+}
@@ -79,3 +79,27 @@ func variableName(s string) (string, int) {
 	}
 	return s[:i], i
 }
+
+// ExpandKnown replaces the $name and ${name} placeholders of s whose name is
+// found by lookup. The other placeholders are kept as written.
+func ExpandKnown(s string, lookup func(string) (string, bool)) string {
+	buf := make([]byte, 0, 2*len(s))
+	i := 0
+	for j := 0; j < len(s); j++ {
+		if s[j] != '$' || j+1 >= len(s) {
+			continue
+		}
+		name, w := variableName(s[j+1:])
+		value, ok := "", false
+		if name != "" {
+			value, ok = lookup(name)
+		}
+		if ok {
+			buf = append(buf, s[i:j]...)
+			buf = append(buf, value...)
+			i = j + w + 1
+		}
+		j += w
+	}
+	return string(buf) + s[i:]
+}
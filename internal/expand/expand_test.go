@@ -36,3 +36,32 @@ func TestExpand(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandKnown(t *testing.T) {
+	table := []struct {
+		in   string
+		want string
+	}{
+		{in: "https://$HOST:${PORT}", want: "https://domain.com:8080"},
+		{in: "https://${HOST_2}:$PORT", want: "https://${HOST_2}:8080"},
+		{in: "echo ${HOME:-/root} $1 $(id -u) $", want: "echo ${HOME:-/root} $1 $(id -u) $"},
+		{in: `.items | map(select(.name == $name)) ${unterminated`, want: `.items | map(select(.name == $name)) ${unterminated`},
+		{in: "${steps.crds.revision}-$HOST", want: "3-domain.com"},
+	}
+
+	env := map[string]string{
+		"HOST":                "domain.com",
+		"PORT":                "8080",
+		"steps.crds.revision": "3",
+	}
+	lookup := func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+
+	for i, tc := range table {
+		if got := ExpandKnown(tc.in, lookup); got != tc.want {
+			t.Fatalf("[tc: %d] - got: %v, expected: %v", i, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package health tells whether Kubernetes objects are ready, beyond what
+// Helm's wait covers: workloads, Jobs, CRDs, CloudNativePG clusters and any
+// custom resource reporting a Ready condition.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/krateoplatformops/krateoctl/internal/dynamic"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Status is the outcome of a health evaluation.
+type Status struct {
+	Ready bool
+	// Failed is true when the object will not become ready without an
+	// intervention, such as a failed Job.
+	Failed bool
+	// Message explains why the object is not ready.
+	Message string
+}
+
+var (
+	deploymentKind  = schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	statefulSetKind = schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
+	jobKind         = schema.GroupKind{Group: "batch", Kind: "Job"}
+	crdKind         = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+	cnpgClusterKind = schema.GroupKind{Group: "postgresql.cnpg.io", Kind: "Cluster"}
+)
+
+// cnpgHealthyPhase is the phase of a CloudNativePG cluster serving all its instances.
+const cnpgHealthyPhase = "Cluster in healthy state"
+
+// Checker evaluates the built-in rules and the user-defined health checks,
+// which take precedence over the built-in rule of the same kind.
+type Checker struct {
+	custom map[schema.GroupVersionKind]string
+}
+
+// NewChecker returns a Checker using the given user-defined health checks.
+func NewChecker(checks []types.HealthCheck) *Checker {
+	c := &Checker{custom: map[schema.GroupVersionKind]string{}}
+	for _, check := range checks {
+		c.custom[schema.FromAPIVersionAndKind(check.APIVersion, check.Kind)] = check.Expression
+	}
+	return c
+}
+
+// Evaluate returns the health of the object.
+func (c *Checker) Evaluate(ctx context.Context, obj *unstructured.Unstructured) (Status, error) {
+	gvk := obj.GroupVersionKind()
+	if expr, ok := c.custom[gvk]; ok {
+		return evalExpression(ctx, obj, expr)
+	}
+
+	if observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); found && observed < obj.GetGeneration() {
+		return Status{Message: fmt.Sprintf("generation %d not yet observed (observed %d)", obj.GetGeneration(), observed)}, nil
+	}
+
+	switch gvk.GroupKind() {
+	case deploymentKind:
+		return deploymentStatus(obj), nil
+	case statefulSetKind:
+		return statefulSetStatus(obj), nil
+	case jobKind:
+		return jobStatus(obj), nil
+	case crdKind:
+		return crdStatus(obj), nil
+	case cnpgClusterKind:
+		return cnpgClusterStatus(obj), nil
+	default:
+		return readyConditionStatus(obj), nil
+	}
+}
+
+func evalExpression(ctx context.Context, obj *unstructured.Unstructured, expr string) (Status, error) {
+	val, err := dynamic.Extract(ctx, obj, expr)
+	if errors.Is(err, io.EOF) {
+		return Status{Message: fmt.Sprintf("health expression %q produced no value", expr)}, nil
+	}
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to evaluate health expression %q: %w", expr, err)
+	}
+	if !dynamic.Truthy(val) {
+		return Status{Message: fmt.Sprintf("health expression %q is %v", expr, val)}, nil
+	}
+	return Status{Ready: true}, nil
+}
+
+func deploymentStatus(obj *unstructured.Unstructured) Status {
+	if cond, ok := condition(obj, "Progressing"); ok && cond.status == "False" && cond.reason == "ProgressDeadlineExceeded" {
+		return Status{Failed: true, Message: cond.describe("Progressing")}
+	}
+
+	replicas := specReplicas(obj)
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	total, _, _ := unstructured.NestedInt64(obj.Object, "status", "replicas")
+
+	switch {
+	case updated < replicas:
+		return Status{Message: fmt.Sprintf("%d of %d replicas updated", updated, replicas)}
+	case total > updated:
+		return Status{Message: fmt.Sprintf("%d old replicas pending termination", total-updated)}
+	case available < replicas:
+		return Status{Message: fmt.Sprintf("%d of %d updated replicas available", available, replicas)}
+	}
+	return Status{Ready: true}
+}
+
+func statefulSetStatus(obj *unstructured.Unstructured) Status {
+	replicas := specReplicas(obj)
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	if ready < replicas {
+		return Status{Message: fmt.Sprintf("%d of %d replicas ready", ready, replicas)}
+	}
+
+	strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy", "type")
+	if strategy == "OnDelete" {
+		return Status{Ready: true}
+	}
+
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	if updated < replicas {
+		return Status{Message: fmt.Sprintf("%d of %d replicas updated", updated, replicas)}
+	}
+	return Status{Ready: true}
+}
+
+func jobStatus(obj *unstructured.Unstructured) Status {
+	if cond, ok := condition(obj, "Failed"); ok && cond.status == "True" {
+		return Status{Failed: true, Message: fmt.Sprintf("job failed: %s", cond.describe("Failed"))}
+	}
+	if cond, ok := condition(obj, "Complete"); ok && cond.status == "True" {
+		return Status{Ready: true}
+	}
+	return Status{Message: "job not completed yet"}
+}
+
+func crdStatus(obj *unstructured.Unstructured) Status {
+	if cond, ok := condition(obj, "NamesAccepted"); ok && cond.status == "False" {
+		return Status{Failed: true, Message: cond.describe("NamesAccepted")}
+	}
+	if cond, ok := condition(obj, "Established"); ok && cond.status == "True" {
+		return Status{Ready: true}
+	}
+	return Status{Message: "CRD not established yet"}
+}
+
+func cnpgClusterStatus(obj *unstructured.Unstructured) Status {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	if phase == cnpgHealthyPhase {
+		return Status{Ready: true}
+	}
+
+	instances, _, _ := unstructured.NestedInt64(obj.Object, "spec", "instances")
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyInstances")
+	if phase == "" {
+		phase = "unknown phase"
+	}
+	return Status{Message: fmt.Sprintf("%s, %d of %d instances ready", phase, ready, instances)}
+}
+
+// readyConditionStatus applies to any other kind: objects reporting a Ready
+// condition must have it True, the others are ready once they exist.
+func readyConditionStatus(obj *unstructured.Unstructured) Status {
+	cond, ok := condition(obj, "Ready")
+	if !ok || cond.status == "True" {
+		return Status{Ready: true}
+	}
+	return Status{Message: cond.describe("Ready")}
+}
+
+// specReplicas returns spec.replicas, which defaults to 1.
+func specReplicas(obj *unstructured.Unstructured) int64 {
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		return 1
+	}
+	return replicas
+}
+
+type objCondition struct {
+	status  string
+	reason  string
+	message string
+}
+
+// describe returns the message of the condition, falling back to its reason.
+func (c objCondition) describe(condType string) string {
+	switch {
+	case c.message != "":
+		return c.message
+	case c.reason != "":
+		return fmt.Sprintf("%s condition is %s: %s", condType, c.status, c.reason)
+	}
+	return fmt.Sprintf("%s condition is %s", condType, c.status)
+}
+
+// condition returns the status.conditions entry of the given type.
+func condition(obj *unstructured.Unstructured, condType string) (objCondition, bool) {
+	list, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok || m["type"] != condType {
+			continue
+		}
+		status, _ := m["status"].(string)
+		reason, _ := m["reason"].(string)
+		message, _ := m["message"].(string)
+		return objCondition{status: status, reason: reason, message: message}, true
+	}
+	return objCondition{}, false
+}
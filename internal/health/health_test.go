@@ -0,0 +1,185 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func object(apiVersion, kind string, fields map[string]any) *unstructured.Unstructured {
+	obj := map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"name": "test", "namespace": "krateo-system", "generation": int64(2)},
+	}
+	for k, v := range fields {
+		obj[k] = v
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func conditions(list ...map[string]any) []any {
+	out := make([]any, 0, len(list))
+	for _, c := range list {
+		out = append(out, c)
+	}
+	return out
+}
+
+func TestCheckerEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		obj        *unstructured.Unstructured
+		wantReady  bool
+		wantFailed bool
+	}{
+		{
+			name: "deployment rolled out",
+			obj: object("apps/v1", "Deployment", map[string]any{
+				"spec":   map[string]any{"replicas": int64(2)},
+				"status": map[string]any{"observedGeneration": int64(2), "replicas": int64(2), "updatedReplicas": int64(2), "availableReplicas": int64(2)},
+			}),
+			wantReady: true,
+		},
+		{
+			name: "deployment with old replicas",
+			obj: object("apps/v1", "Deployment", map[string]any{
+				"spec":   map[string]any{"replicas": int64(2)},
+				"status": map[string]any{"observedGeneration": int64(2), "replicas": int64(3), "updatedReplicas": int64(2), "availableReplicas": int64(2)},
+			}),
+		},
+		{
+			name: "deployment generation not observed",
+			obj: object("apps/v1", "Deployment", map[string]any{
+				"status": map[string]any{"observedGeneration": int64(1), "replicas": int64(1), "updatedReplicas": int64(1), "availableReplicas": int64(1)},
+			}),
+		},
+		{
+			name: "deployment past progress deadline",
+			obj: object("apps/v1", "Deployment", map[string]any{
+				"status": map[string]any{"conditions": conditions(map[string]any{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"})},
+			}),
+			wantFailed: true,
+		},
+		{
+			name: "statefulset not updated",
+			obj: object("apps/v1", "StatefulSet", map[string]any{
+				"spec":   map[string]any{"replicas": int64(3)},
+				"status": map[string]any{"readyReplicas": int64(3), "updatedReplicas": int64(1)},
+			}),
+		},
+		{
+			name: "statefulset with OnDelete strategy",
+			obj: object("apps/v1", "StatefulSet", map[string]any{
+				"spec":   map[string]any{"replicas": int64(3), "updateStrategy": map[string]any{"type": "OnDelete"}},
+				"status": map[string]any{"readyReplicas": int64(3), "updatedReplicas": int64(1)},
+			}),
+			wantReady: true,
+		},
+		{
+			name: "job complete",
+			obj: object("batch/v1", "Job", map[string]any{
+				"status": map[string]any{"conditions": conditions(map[string]any{"type": "Complete", "status": "True"})},
+			}),
+			wantReady: true,
+		},
+		{
+			name: "job failed",
+			obj: object("batch/v1", "Job", map[string]any{
+				"status": map[string]any{"conditions": conditions(map[string]any{"type": "Failed", "status": "True", "message": "BackoffLimitExceeded"})},
+			}),
+			wantFailed: true,
+		},
+		{
+			name: "crd established",
+			obj: object("apiextensions.k8s.io/v1", "CustomResourceDefinition", map[string]any{
+				"status": map[string]any{"conditions": conditions(map[string]any{"type": "Established", "status": "True"})},
+			}),
+			wantReady: true,
+		},
+		{
+			name: "crd names rejected",
+			obj: object("apiextensions.k8s.io/v1", "CustomResourceDefinition", map[string]any{
+				"status": map[string]any{"conditions": conditions(map[string]any{"type": "NamesAccepted", "status": "False"})},
+			}),
+			wantFailed: true,
+		},
+		{
+			name: "cnpg cluster healthy",
+			obj: object("postgresql.cnpg.io/v1", "Cluster", map[string]any{
+				"status": map[string]any{"phase": "Cluster in healthy state"},
+			}),
+			wantReady: true,
+		},
+		{
+			name: "cnpg cluster creating",
+			obj: object("postgresql.cnpg.io/v1", "Cluster", map[string]any{
+				"spec":   map[string]any{"instances": int64(3)},
+				"status": map[string]any{"phase": "Setting up primary", "readyInstances": int64(1)},
+			}),
+		},
+		{
+			name: "custom resource not ready",
+			obj: object("core.krateo.io/v1alpha1", "CompositionDefinition", map[string]any{
+				"status": map[string]any{"conditions": conditions(map[string]any{"type": "Ready", "status": "False", "reason": "Creating"})},
+			}),
+		},
+		{
+			name:      "object without conditions",
+			obj:       object("v1", "ConfigMap", nil),
+			wantReady: true,
+		},
+	}
+
+	checker := NewChecker(nil)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, err := checker.Evaluate(context.Background(), tc.obj)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if status.Ready != tc.wantReady || status.Failed != tc.wantFailed {
+				t.Fatalf("Evaluate() = %+v, want ready=%v failed=%v", status, tc.wantReady, tc.wantFailed)
+			}
+			if !status.Ready && status.Message == "" {
+				t.Fatalf("Evaluate() must explain why the object is not ready")
+			}
+		})
+	}
+}
+
+func TestCheckerEvaluateCustomExpression(t *testing.T) {
+	checker := NewChecker([]types.HealthCheck{{
+		APIVersion: "postgresql.cnpg.io/v1",
+		Kind:       "Cluster",
+		Expression: ".status.readyInstances == .spec.instances",
+	}})
+
+	obj := object("postgresql.cnpg.io/v1", "Cluster", map[string]any{
+		"spec":   map[string]any{"instances": int64(2)},
+		"status": map[string]any{"phase": "Cluster in healthy state", "readyInstances": int64(1)},
+	})
+
+	status, err := checker.Evaluate(context.Background(), obj)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if status.Ready {
+		t.Fatalf("the custom expression must take precedence over the built-in rule")
+	}
+	if !strings.Contains(status.Message, "readyInstances") {
+		t.Fatalf("Message = %q, want it to name the expression", status.Message)
+	}
+
+	obj.Object["status"].(map[string]any)["readyInstances"] = int64(2)
+	status, err = checker.Evaluate(context.Background(), obj)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if !status.Ready {
+		t.Fatalf("Evaluate() = %+v, want ready", status)
+	}
+}
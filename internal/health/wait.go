@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultPollInterval is the delay between two readiness checks.
+const DefaultPollInterval = 2 * time.Second
+
+// Waiter polls objects until they are ready.
+type Waiter struct {
+	checker  *Checker
+	get      func(context.Context, getter.GetOptions) (*unstructured.Unstructured, error)
+	interval time.Duration
+	// progress is notified after every poll with the objects still pending.
+	progress func(id, message string)
+}
+
+// NewWaiter returns a Waiter reading objects through g. progress may be nil.
+func NewWaiter(g *getter.Getter, checks []types.HealthCheck, progress func(id, message string)) *Waiter {
+	return &Waiter{
+		checker: NewChecker(checks),
+		get: func(ctx context.Context, o getter.GetOptions) (*unstructured.Unstructured, error) {
+			return g.Get(ctx, o)
+		},
+		interval: DefaultPollInterval,
+		progress: progress,
+	}
+}
+
+// WaitForReady blocks until every object is ready. It fails as soon as an object
+// is reported as failed, or when the timeout expires, naming the first object
+// that is still not ready. Objects without namespace are looked up in namespace
+// when their kind is namespaced.
+func (w *Waiter) WaitForReady(ctx context.Context, id string, objs []*unstructured.Unstructured, namespace string, timeout time.Duration) error {
+	pending := make([]getter.GetOptions, 0, len(objs))
+	for _, obj := range objs {
+		ns := obj.GetNamespace()
+		if ns == "" {
+			ns = namespace
+		}
+		pending = append(pending, getter.GetOptions{
+			GVK:       obj.GroupVersionKind(),
+			Namespace: ns,
+			Name:      obj.GetName(),
+		})
+	}
+
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		var reason string
+		for len(pending) > 0 {
+			status, err := w.check(ctx, pending[0])
+			if err != nil {
+				return err
+			}
+			if status.Failed {
+				return fmt.Errorf("%s is not healthy: %s", describe(pending[0]), status.Message)
+			}
+			if !status.Ready {
+				reason = fmt.Sprintf("%s: %s", describe(pending[0]), status.Message)
+				break
+			}
+			pending = pending[1:]
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("timed out after %s waiting for %d object(s) to become ready, %s: %w",
+				timeout, len(pending), reason, context.DeadlineExceeded)
+		}
+
+		if w.progress != nil {
+			w.progress(id, fmt.Sprintf("waiting for %d object(s) to become ready, %s left", len(pending), remaining.Round(time.Second)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Waiter) check(ctx context.Context, opts getter.GetOptions) (Status, error) {
+	obj, err := w.get(ctx, opts)
+	switch {
+	case apierrors.IsNotFound(err):
+		return Status{Message: "object not found"}, nil
+	case meta.IsNoMatchError(err):
+		// The CRD may not be registered yet.
+		return Status{Message: err.Error()}, nil
+	case err != nil:
+		return Status{}, fmt.Errorf("failed to get %s: %w", describe(opts), err)
+	}
+
+	return w.checker.Evaluate(ctx, obj)
+}
+
+func describe(opts getter.GetOptions) string {
+	if opts.Namespace == "" {
+		return fmt.Sprintf("%s %s", opts.GVK.Kind, opts.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", opts.GVK.Kind, opts.Namespace, opts.Name)
+}
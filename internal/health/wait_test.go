@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWaiterWaitForReady(t *testing.T) {
+	job := object("batch/v1", "Job", nil)
+	job.SetNamespace("")
+
+	polls := 0
+	w := &Waiter{
+		checker:  NewChecker(nil),
+		interval: time.Millisecond,
+		get: func(_ context.Context, opts getter.GetOptions) (*unstructured.Unstructured, error) {
+			if opts.Namespace != "krateo-system" {
+				t.Fatalf("namespace = %q, want the default namespace", opts.Namespace)
+			}
+			polls++
+			switch polls {
+			case 1:
+				return nil, apierrors.NewNotFound(schema.GroupResource{Group: "batch", Resource: "jobs"}, opts.Name)
+			case 2:
+				return object("batch/v1", "Job", nil), nil
+			}
+			return object("batch/v1", "Job", map[string]any{
+				"status": map[string]any{"conditions": conditions(map[string]any{"type": "Complete", "status": "True"})},
+			}), nil
+		},
+	}
+
+	var messages []string
+	w.progress = func(_ string, msg string) { messages = append(messages, msg) }
+
+	if err := w.WaitForReady(context.Background(), "migrate", []*unstructured.Unstructured{job}, "krateo-system", time.Minute); err != nil {
+		t.Fatalf("WaitForReady() error = %v", err)
+	}
+	if polls != 3 || len(messages) != 2 {
+		t.Fatalf("polls = %d, progress messages = %d, want 3 and 2", polls, len(messages))
+	}
+}
+
+func TestWaiterWaitForReadyFailures(t *testing.T) {
+	failed := object("batch/v1", "Job", map[string]any{
+		"status": map[string]any{"conditions": conditions(map[string]any{"type": "Failed", "status": "True", "message": "BackoffLimitExceeded"})},
+	})
+	pending := object("batch/v1", "Job", nil)
+
+	newWaiter := func(obj *unstructured.Unstructured) *Waiter {
+		return &Waiter{
+			checker:  NewChecker(nil),
+			interval: time.Millisecond,
+			get: func(context.Context, getter.GetOptions) (*unstructured.Unstructured, error) {
+				return obj, nil
+			},
+		}
+	}
+
+	err := newWaiter(failed).WaitForReady(context.Background(), "migrate", []*unstructured.Unstructured{failed}, "", time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "BackoffLimitExceeded") {
+		t.Fatalf("WaitForReady() error = %v, want the failure reason", err)
+	}
+
+	err = newWaiter(pending).WaitForReady(context.Background(), "migrate", []*unstructured.Unstructured{pending}, "", 5*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitForReady() error = %v, want a timeout", err)
+	}
+	if !strings.Contains(err.Error(), "Job krateo-system/test") {
+		t.Fatalf("WaitForReady() error = %q, want it to name the pending object", err)
+	}
+}
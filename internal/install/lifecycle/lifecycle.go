@@ -1,21 +1,21 @@
 package lifecycle
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/krateoplatformops/krateoctl/internal/dynamic"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
 	"github.com/krateoplatformops/krateoctl/internal/ui"
 	"github.com/krateoplatformops/krateoctl/internal/util/kube"
 	"github.com/krateoplatformops/krateoctl/internal/util/remote"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	kyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/rest"
 )
 
@@ -29,8 +29,17 @@ type ApplyOptions struct {
 	RestConfig       *rest.Config
 	JobNameSuffix    string
 	InstallationType string
+	// Vars resolves the $name and ${name} placeholders in the manifests, such as
+	// the ${steps.<id>.<field>} outputs of the workflow, as the step handlers do.
+	// Unknown names are left as is.
+	Vars map[string]string
+	// JobTimeout is the maximum time to wait for every Job of the phase. Defaults to 5m.
+	JobTimeout time.Duration
 }
 
+// DefaultJobTimeout is the time allowed to every Job of a phase when ApplyOptions.JobTimeout is not set.
+const DefaultJobTimeout = 5 * time.Minute
+
 type loadOptions struct {
 	phase            string
 	version          string
@@ -52,13 +61,7 @@ func NewManager(namespace string, getterFactory GetterFactory) *Manager {
 }
 
 func (m *Manager) Apply(ctx context.Context, applierClient *applier.Applier, logger *ui.Logger, opts ApplyOptions) error {
-	manifests, err := m.loadManifests(ctx, logger, loadOptions{
-		phase:            opts.Phase,
-		version:          opts.Version,
-		repository:       opts.Repository,
-		configFile:       opts.ConfigFile,
-		installationType: opts.InstallationType,
-	})
+	manifests, err := m.Render(ctx, logger, opts)
 	if err != nil {
 		return err
 	}
@@ -71,12 +74,6 @@ func (m *Manager) Apply(ctx context.Context, applierClient *applier.Applier, log
 
 	var jobsToWait []*unstructured.Unstructured
 	for _, manifest := range manifests {
-		substituteTemplateVariables(manifest.UnstructuredContent(), m.namespace, opts.JobNameSuffix)
-
-		if manifest.GetNamespace() == "" && !isClusterScoped(manifest.GetKind()) {
-			manifest.SetNamespace(m.namespace)
-		}
-
 		opts := applier.ApplyOptions{
 			GVK:       manifest.GroupVersionKind(),
 			Namespace: manifest.GetNamespace(),
@@ -97,7 +94,38 @@ func (m *Manager) Apply(ctx context.Context, applierClient *applier.Applier, log
 		return nil
 	}
 
-	return m.waitForJobs(ctx, logger, jobsToWait, opts.RestConfig)
+	timeout := opts.JobTimeout
+	if timeout <= 0 {
+		timeout = DefaultJobTimeout
+	}
+	return m.waitForJobs(ctx, logger, jobsToWait, opts.RestConfig, timeout)
+}
+
+// Render loads the manifests of the phase and resolves them as Apply does: the
+// template variables and the ${name} placeholders are replaced and namespaced
+// objects default to the manager namespace. Nothing is sent to the cluster.
+func (m *Manager) Render(ctx context.Context, logger *ui.Logger, opts ApplyOptions) ([]*unstructured.Unstructured, error) {
+	manifests, err := m.loadManifests(ctx, logger, loadOptions{
+		phase:            opts.Phase,
+		version:          opts.Version,
+		repository:       opts.Repository,
+		configFile:       opts.ConfigFile,
+		installationType: opts.InstallationType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, manifest := range manifests {
+		substituteTemplateVariables(manifest.UnstructuredContent(), m.namespace, opts.JobNameSuffix)
+		steps.MapVars(opts.Vars).ExpandValues(manifest.UnstructuredContent())
+
+		if manifest.GetNamespace() == "" && !isClusterScoped(manifest.GetKind()) {
+			manifest.SetNamespace(m.namespace)
+		}
+	}
+
+	return manifests, nil
 }
 
 func (m *Manager) loadManifests(ctx context.Context, logger *ui.Logger, opts loadOptions) ([]*unstructured.Unstructured, error) {
@@ -135,7 +163,7 @@ func loadLocalManifests(configDir, phase, installationType string) ([]*unstructu
 	for _, candidate := range installationTypeCandidates(installationType) {
 		typeSpecificPath := filepath.Join(configDir, fmt.Sprintf("%s.%s.yaml", phase, candidate))
 		if content, err := os.ReadFile(typeSpecificPath); err == nil {
-			return parseManifests(content, typeSpecificPath)
+			return dynamic.ParseManifests(content, typeSpecificPath)
 		}
 	}
 
@@ -149,7 +177,7 @@ func loadLocalManifests(configDir, phase, installationType string) ([]*unstructu
 		return nil, fmt.Errorf("read manifest file %s: %w", filePath, err)
 	}
 
-	return parseManifests(content, filePath)
+	return dynamic.ParseManifests(content, filePath)
 }
 
 func loadRemoteManifests(ctx context.Context, repository, version, phase, installationType string) ([]*unstructured.Unstructured, error) {
@@ -164,7 +192,7 @@ func loadRemoteManifests(ctx context.Context, repository, version, phase, instal
 			Timeout:    remote.DefaultTimeout,
 		})
 		if err == nil && len(content) > 0 {
-			return parseManifests(content, fmt.Sprintf("%s/%s", version, filename))
+			return dynamic.ParseManifests(content, fmt.Sprintf("%s/%s", version, filename))
 		}
 	}
 
@@ -181,7 +209,7 @@ func loadRemoteManifests(ctx context.Context, repository, version, phase, instal
 	}
 
 	_ = ctx
-	return parseManifests(content, fmt.Sprintf("%s/%s", version, filename))
+	return dynamic.ParseManifests(content, fmt.Sprintf("%s/%s", version, filename))
 }
 
 func installationTypeCandidates(installType string) []string {
@@ -208,14 +236,14 @@ func installationTypeCandidates(installType string) []string {
 	}
 }
 
-func (m *Manager) waitForJobs(ctx context.Context, logger *ui.Logger, jobs []*unstructured.Unstructured, rc *rest.Config) error {
+func (m *Manager) waitForJobs(ctx context.Context, logger *ui.Logger, jobs []*unstructured.Unstructured, rc *rest.Config, timeout time.Duration) error {
 	g, err := m.getterFactory(rc)
 	if err != nil {
 		return fmt.Errorf("initialize getter for Job monitoring: %w", err)
 	}
 
-	waiter := kube.NewJobWaiter(g)
-	logger.Info("\n⏳ Waiting for %d Job(s) to complete (max 5 minutes)...", len(jobs))
+	waiter := kube.NewJobWaiter(g).WithTimeout(timeout)
+	logger.Info("\n⏳ Waiting for %d Job(s) to complete (max %s each)...", len(jobs), timeout)
 
 	for _, job := range jobs {
 		if err := waiter.Wait(ctx, job.GetNamespace(), job.GetName()); err != nil {
@@ -265,25 +293,3 @@ func isClusterScoped(kind string) bool {
 	}
 	return clusterScopedKinds[kind]
 }
-
-func parseManifests(content []byte, source string) ([]*unstructured.Unstructured, error) {
-	var manifests []*unstructured.Unstructured
-	decoder := kyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
-
-	for {
-		obj := &unstructured.Unstructured{}
-		err := decoder.Decode(obj)
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, fmt.Errorf("parse YAML in %s: %w", source, err)
-		}
-		if obj.GetKind() == "" {
-			continue
-		}
-		manifests = append(manifests, obj)
-	}
-
-	return manifests, nil
-}
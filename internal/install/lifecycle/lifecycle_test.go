@@ -1,9 +1,15 @@
 package lifecycle
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/ui"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestLoadLocalManifestsTypeVariants(t *testing.T) {
@@ -59,3 +65,37 @@ func writeLifecycleManifest(t *testing.T, path, name string) {
 		t.Fatalf("failed to write %s: %v", path, err)
 	}
 }
+
+func TestRenderExpandsVars(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: notify
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          args: ["--revision=${steps.install-authn.revision}", "$REGISTRY/notify", "${UNKNOWN}", "${HOME:-/root}"]
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "post-upgrade.yaml"), []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	manifests, err := NewManager("krateo-system", nil).Render(context.Background(), ui.NewLogger(io.Discard, ui.LevelInfo), ApplyOptions{
+		Phase:      "post-upgrade",
+		ConfigFile: filepath.Join(tmpDir, "krateo.yaml"),
+		Vars:       map[string]string{"steps.install-authn.revision": "3", "REGISTRY": "ghcr.io"},
+	})
+	if err != nil || len(manifests) != 1 {
+		t.Fatalf("Render() = %d manifests, %v, want 1", len(manifests), err)
+	}
+
+	containers, _, _ := unstructured.NestedSlice(manifests[0].Object, "spec", "template", "spec", "containers")
+	args := containers[0].(map[string]any)["args"].([]any)
+	want := []any{"--revision=3", "ghcr.io/notify", "${UNKNOWN}", "${HOME:-/root}"}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+}
@@ -0,0 +1,43 @@
+package state
+
+import (
+	"slices"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+)
+
+// prunableTypes are the step types owning cluster resources that can be removed
+// through the delete path of their handler.
+var prunableTypes = []types.StepType{types.TypeObject, types.TypeChart, types.TypeManifests}
+
+// PruneSteps compares the stored snapshot with the steps of the new plan and
+// returns, in snapshot order, the steps whose resources are no longer managed:
+// the ones removed from the configuration and the ones now skipped, typically
+// because their component was disabled. Steps already skipped in the snapshot
+// never created anything and are left out.
+//
+// The dependsOn entries are kept only when they point to another pruned step,
+// so the result can be run as a delete workflow on its own.
+func PruneSteps(snapshot *Snapshot, steps []*types.Step) ([]*types.Step, error) {
+	if snapshot == nil || len(snapshot.Steps) == 0 {
+		return nil, nil
+	}
+
+	previous, err := snapshot.GetSteps()
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]*types.Step, len(steps))
+	for _, step := range steps {
+		current[step.ID] = step
+	}
+
+	return types.Subset(previous, func(step *types.Step) bool {
+		if step.Skip || !slices.Contains(prunableTypes, step.Type) {
+			return false
+		}
+		next, ok := current[step.ID]
+		return !ok || next.Skip || next.Type != step.Type
+	}), nil
+}
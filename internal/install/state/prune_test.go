@@ -0,0 +1,57 @@
+package state
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+)
+
+func TestPruneSteps(t *testing.T) {
+	previous := []*types.Step{
+		{ID: "registry", Type: types.TypeVar, With: &map[string]any{"name": "REGISTRY", "value": "ghcr.io"}},
+		{ID: "install-authn", Type: types.TypeChart, With: &map[string]any{"releaseName": "authn"}},
+		{ID: "authn-config", Type: types.TypeObject, DependsOn: []string{"install-authn", "registry"}, With: &map[string]any{"kind": "ConfigMap"}},
+		{ID: "install-backend", Type: types.TypeChart, With: &map[string]any{"releaseName": "backend"}},
+		{ID: "install-frontend", Type: types.TypeChart, With: &map[string]any{"releaseName": "frontend"}},
+		{ID: "install-legacy", Type: types.TypeChart, Skip: true, With: &map[string]any{"releaseName": "legacy"}},
+		{ID: "wait-backend", Type: types.TypeWait, With: &map[string]any{"kind": "Deployment"}},
+	}
+	snapshot, err := BuildSnapshot(nil, previous, "v1")
+	if err != nil {
+		t.Fatalf("BuildSnapshot() error = %v", err)
+	}
+
+	current := []*types.Step{
+		{ID: "install-backend", Type: types.TypeChart, With: &map[string]any{"releaseName": "backend"}},
+		{ID: "install-frontend", Type: types.TypeChart, Skip: true, With: &map[string]any{"releaseName": "frontend"}},
+	}
+
+	got, err := PruneSteps(snapshot, current)
+	if err != nil {
+		t.Fatalf("PruneSteps() error = %v", err)
+	}
+
+	var ids []string
+	for _, step := range got {
+		ids = append(ids, step.ID)
+	}
+	want := []string{"install-authn", "authn-config", "install-frontend"}
+	if !slices.Equal(ids, want) {
+		t.Fatalf("PruneSteps() = %v, want %v", ids, want)
+	}
+
+	if deps := got[1].DependsOn; !slices.Equal(deps, []string{"install-authn"}) {
+		t.Fatalf("authn-config dependsOn = %v, want only the pruned steps", deps)
+	}
+	if name := (*got[0].With)["releaseName"]; name != "authn" {
+		t.Fatalf("install-authn releaseName = %v, want the stored configuration", name)
+	}
+}
+
+func TestPruneStepsWithoutSnapshot(t *testing.T) {
+	got, err := PruneSteps(nil, []*types.Step{{ID: "install-authn", Type: types.TypeChart}})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("PruneSteps(nil) = %v, %v, want nothing to prune", got, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	"github.com/krateoplatformops/krateoctl/internal/config"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
@@ -14,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
 )
 
 const (
@@ -36,11 +38,44 @@ type Snapshot struct {
 	InstallationVersion  string           `json:"installationVersion,omitempty" yaml:"installationVersion,omitempty"`
 }
 
+// GetSteps decodes the stored steps.
+func (s *Snapshot) GetSteps() ([]*types.Step, error) {
+	if s == nil || len(s.Steps) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(s.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("marshal snapshot steps: %w", err)
+	}
+
+	var out []*types.Step
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot steps: %w", err)
+	}
+
+	return out, nil
+}
+
+// Checkpoint records a workflow step that completed successfully.
+type Checkpoint struct {
+	ID          string         `json:"id" yaml:"id"`
+	Digest      string         `json:"digest" yaml:"digest"`
+	Result      map[string]any `json:"result,omitempty" yaml:"result,omitempty"`
+	CompletedAt metav1.Time    `json:"completedAt" yaml:"completedAt"`
+}
+
 // Installation is the CR representation persisted to the cluster.
 type Installation struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Spec              InstallationSpec `json:"spec"`
+	Spec              InstallationSpec   `json:"spec"`
+	Status            InstallationStatus `json:"status,omitempty"`
+}
+
+// InstallationStatus tracks the progress of the last workflow run (status subresource).
+type InstallationStatus struct {
+	Checkpoints []Checkpoint `json:"checkpoints,omitempty"`
 }
 
 // InstallationSpec mirrors the CRD layout (spec.spec).
@@ -52,6 +87,14 @@ type InstallationSpec struct {
 type Store interface {
 	Save(ctx context.Context, name string, snapshot *Snapshot) error
 	Load(ctx context.Context, name string) (*Snapshot, error)
+	// SaveCheckpoint records a completed step, replacing any previous checkpoint with the same ID.
+	SaveCheckpoint(ctx context.Context, name string, checkpoint Checkpoint) error
+	// LoadCheckpoints returns the checkpoints of the last run; it is empty when the installation does not exist.
+	LoadCheckpoints(ctx context.Context, name string) ([]Checkpoint, error)
+	// ResetCheckpoints discards the checkpoints of the last run.
+	ResetCheckpoints(ctx context.Context, name string) error
+	// Delete removes the protect-state finalizer and deletes the installation; a missing one is not an error.
+	Delete(ctx context.Context, name string) error
 }
 
 type manager struct {
@@ -195,6 +238,140 @@ func (m *manager) Load(ctx context.Context, name string) (*Snapshot, error) {
 	return &snap, nil
 }
 
+// SaveCheckpoint stores the checkpoint in the status of the Installation resource.
+// When the resource does not exist yet it is created with an empty snapshot, which
+// is replaced by the real one once the workflow completes.
+func (m *manager) SaveCheckpoint(ctx context.Context, name string, checkpoint Checkpoint) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		u, err := m.resource().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			u, err = m.createEmpty(ctx, name)
+		}
+		if err != nil {
+			return fmt.Errorf("get installation: %w", err)
+		}
+
+		return m.updateCheckpoints(ctx, u, func(list []Checkpoint) []Checkpoint {
+			return upsertCheckpoint(list, checkpoint)
+		})
+	})
+}
+
+// LoadCheckpoints returns the checkpoints stored in the status of the Installation resource.
+func (m *manager) LoadCheckpoints(ctx context.Context, name string) ([]Checkpoint, error) {
+	u, err := m.resource().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get installation: %w", err)
+	}
+
+	var inst Installation
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &inst); err != nil {
+		return nil, fmt.Errorf("decode installation: %w", err)
+	}
+
+	return inst.Status.Checkpoints, nil
+}
+
+// ResetCheckpoints removes every checkpoint from the status of the Installation resource.
+func (m *manager) ResetCheckpoints(ctx context.Context, name string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		u, err := m.resource().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("get installation: %w", err)
+		}
+
+		return m.updateCheckpoints(ctx, u, func([]Checkpoint) []Checkpoint {
+			return nil
+		})
+	})
+}
+
+// Delete removes the protect-state finalizer from the Installation resource and deletes it.
+func (m *manager) Delete(ctx context.Context, name string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		u, err := m.resource().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		finalizers := u.GetFinalizers()
+		kept := slices.DeleteFunc(slices.Clone(finalizers), func(f string) bool {
+			return f == InstallationFinalizer
+		})
+		if len(kept) == len(finalizers) {
+			return nil
+		}
+		u.SetFinalizers(kept)
+
+		_, err = m.resource().Update(ctx, u, metav1.UpdateOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("remove installation finalizer: %w", err)
+	}
+
+	err = m.resource().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete installation: %w", err)
+	}
+	return nil
+}
+
+func (m *manager) createEmpty(ctx context.Context, name string) (*unstructured.Unstructured, error) {
+	u, err := installationToUnstructured(&Installation{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Installation",
+			APIVersion: "krateo.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  m.namespace,
+			Finalizers: []string{InstallationFinalizer},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.resource().Create(ctx, u, metav1.CreateOptions{})
+}
+
+func (m *manager) updateCheckpoints(ctx context.Context, u *unstructured.Unstructured, mutate func([]Checkpoint) []Checkpoint) error {
+	var inst Installation
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &inst); err != nil {
+		return fmt.Errorf("decode installation: %w", err)
+	}
+
+	status := InstallationStatus{Checkpoints: mutate(inst.Status.Checkpoints)}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return fmt.Errorf("convert installation status: %w", err)
+	}
+	u.Object["status"] = obj
+
+	_, err = m.resource().UpdateStatus(ctx, u, metav1.UpdateOptions{})
+	return err
+}
+
+func upsertCheckpoint(list []Checkpoint, checkpoint Checkpoint) []Checkpoint {
+	for i := range list {
+		if list[i].ID == checkpoint.ID {
+			list[i] = checkpoint
+			return list
+		}
+	}
+	return append(list, checkpoint)
+}
+
 // BuildSnapshot converts the resolved config and steps into a Snapshot object suitable for persistence.
 func BuildSnapshot(cfg *config.Config, steps []*types.Step, version string) (*Snapshot, error) {
 	snap := &Snapshot{
@@ -211,7 +388,7 @@ func BuildSnapshot(cfg *config.Config, steps []*types.Step, version string) (*Sn
 		}
 	}
 
-	convertedSteps, err := copySteps(steps)
+	convertedSteps, err := copySteps(types.RedactSteps(steps))
 	if err != nil {
 		return nil, err
 	}
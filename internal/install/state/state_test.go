@@ -1,8 +1,19 @@
 package state
 
 import (
+	"context"
 	"reflect"
 	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestMergeFinalizers(t *testing.T) {
@@ -39,3 +50,72 @@ func TestMergeFinalizers(t *testing.T) {
 		})
 	}
 }
+
+func TestUpsertCheckpoint(t *testing.T) {
+	list := []Checkpoint{{ID: "crds", Digest: "a"}, {ID: "backend", Digest: "b"}}
+
+	list = upsertCheckpoint(list, Checkpoint{ID: "backend", Digest: "c"})
+	list = upsertCheckpoint(list, Checkpoint{ID: "frontend", Digest: "d"})
+
+	want := []Checkpoint{{ID: "crds", Digest: "a"}, {ID: "backend", Digest: "c"}, {ID: "frontend", Digest: "d"}}
+	if !reflect.DeepEqual(list, want) {
+		t.Fatalf("upsertCheckpoint() = %v, want %v", list, want)
+	}
+}
+
+func TestManagerDelete(t *testing.T) {
+	inst := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "krateo.io/v1",
+		"kind":       "Installation",
+		"metadata": map[string]any{
+			"name":       "krateoctl",
+			"namespace":  "krateo-system",
+			"finalizers": []any{"alpha", InstallationFinalizer},
+		},
+	}}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{installationGVR: "InstallationList"}, inst)
+	m := &manager{client: client, namespace: "krateo-system"}
+
+	if err := m.Delete(context.Background(), "krateoctl"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	var updated []string
+	for _, action := range client.Actions() {
+		if update, ok := action.(k8stesting.UpdateAction); ok {
+			updated = update.GetObject().(*unstructured.Unstructured).GetFinalizers()
+		}
+	}
+	if !reflect.DeepEqual(updated, []string{"alpha"}) {
+		t.Fatalf("finalizers before delete = %v, want the protect-state one removed", updated)
+	}
+
+	if _, err := m.resource().Get(context.Background(), "krateoctl", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("Get() after Delete() error = %v, want not found", err)
+	}
+	if err := m.Delete(context.Background(), "krateoctl"); err != nil {
+		t.Fatalf("Delete() of a missing installation error = %v", err)
+	}
+}
+
+func TestBuildSnapshotRedactsSensitiveValues(t *testing.T) {
+	snapshot, err := BuildSnapshot(nil, []*types.Step{
+		{ID: "db-password", Type: types.TypeVar, With: &map[string]any{"name": "DB_PASSWORD", "value": "s3cr3t", "sensitive": true}},
+		{ID: "registry", Type: types.TypeVar, With: &map[string]any{"name": "REGISTRY", "value": "ghcr.io"}},
+	}, "local")
+	if err != nil {
+		t.Fatalf("BuildSnapshot() error = %v", err)
+	}
+
+	list, err := snapshot.GetSteps()
+	if err != nil {
+		t.Fatalf("GetSteps() error = %v", err)
+	}
+	if got := (*list[0].With)["value"]; got != redact.Mask {
+		t.Fatalf("sensitive value = %v, want %q", got, redact.Mask)
+	}
+	if got := (*list[1].With)["value"]; got != "ghcr.io" {
+		t.Fatalf("value = %v, want ghcr.io", got)
+	}
+}
@@ -0,0 +1,305 @@
+// Package template renders the workflow steps into Kubernetes manifests
+// without contacting the cluster, as `krateoctl install template` does.
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic"
+	"github.com/krateoplatformops/krateoctl/internal/workflows"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	charthandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/chart"
+	manifestshandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/manifests"
+	objecthandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/object"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// StepIDAnnotation records the ID of the step that produced a rendered object.
+	StepIDAnnotation = "krateoctl.krateo.io/step-id"
+	// LifecyclePhaseAnnotation records the lifecycle phase (pre-upgrade or
+	// post-upgrade) of a rendered lifecycle manifest.
+	LifecyclePhaseAnnotation = "krateoctl.krateo.io/lifecycle-phase"
+)
+
+type Options struct {
+	Namespace string
+	// Vars provides the variables that cannot be resolved offline, such as the
+	// ones of var steps with valueFrom. They win over the values of the var steps.
+	Vars   map[string]string
+	Logger func(string, ...any)
+}
+
+// Renderer renders object, manifests and chart steps. Var steps feed the
+// variables expanded in the following steps; wait, job and secret steps
+// produce no manifest and are left out.
+type Renderer struct {
+	ns      string
+	vars    map[string]string
+	logger  func(string, ...any)
+	env     *cache.Cache[string, string]
+	results map[string]any
+	chart   steps.Handler[*steps.ChartResult]
+}
+
+func New(opts Options) *Renderer {
+	if opts.Logger == nil {
+		opts.Logger = func(string, ...any) {}
+	}
+
+	r := &Renderer{
+		ns:      opts.Namespace,
+		vars:    opts.Vars,
+		logger:  opts.Logger,
+		env:     cache.New[string, string](),
+		results: map[string]any{},
+	}
+	for k, v := range opts.Vars {
+		r.env.Set(k, v)
+	}
+
+	r.chart = charthandler.ChartHandler(charthandler.ChartHandlerOptions{
+		Env:    r.env,
+		Logger: opts.Logger,
+		Render: true,
+	})
+
+	return r
+}
+
+// Render renders the steps in execution order and returns the objects they
+// produce, each one annotated with the ID of its step. Skipped steps and steps
+// whose when condition is false are left out.
+func (r *Renderer) Render(ctx context.Context, list []*types.Step) ([]*unstructured.Unstructured, error) {
+	order, err := executionOrder(list)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*unstructured.Unstructured
+	for _, idx := range order {
+		step := list[idx]
+		if step.Skip {
+			continue
+		}
+
+		if step.When != "" {
+			ok, err := workflows.EvalCondition(ctx, step.When, r.Vars(), r.results)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid when condition: %w", step.ID, err)
+			}
+			if !ok {
+				r.logger(fmt.Sprintf("[%s:%s]: skipped, when condition %q is false", step.Type, step.ID, step.When))
+				continue
+			}
+		}
+
+		objs, res, err := r.renderStep(ctx, step)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", step.ID, err)
+		}
+
+		if res != nil {
+			r.results[step.ID] = res
+			vars, err := workflows.StepOutputs(step.ID, res)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", step.ID, err)
+			}
+			for k, v := range vars {
+				r.env.Set(k, v)
+			}
+		}
+
+		for _, obj := range objs {
+			Annotate(obj, StepIDAnnotation, step.ID)
+		}
+		out = append(out, objs...)
+	}
+
+	return out, nil
+}
+
+// Vars returns a copy of the variables collected so far, step outputs included.
+func (r *Renderer) Vars() map[string]string {
+	out := map[string]string{}
+	r.env.ForEach(func(k, v string) bool {
+		out[k] = v
+		return true
+	})
+	return out
+}
+
+// Outputs returns the steps.<id>.<field> variables published by the rendered
+// steps, as expanded in the post-upgrade manifests.
+func (r *Renderer) Outputs() map[string]string {
+	out := map[string]string{}
+	for k, v := range r.Vars() {
+		if strings.HasPrefix(k, workflows.StepOutputsPrefix) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func (r *Renderer) renderStep(ctx context.Context, step *types.Step) ([]*unstructured.Unstructured, any, error) {
+	switch step.Type {
+	case types.TypeVar:
+		res, err := r.renderVar(step)
+		return nil, res, err
+	case types.TypeObject:
+		obj, err := objecthandler.Render(step.ID, step.With, r.ns, r.env, r.logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		res := &steps.ObjectResult{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+			Namespace:  obj.GetNamespace(),
+			Operation:  "template",
+		}
+		return []*unstructured.Unstructured{obj}, res, nil
+	case types.TypeManifests:
+		objs, err := manifestshandler.Render(ctx, step.ID, step.With, r.env)
+		if err != nil {
+			return nil, nil, err
+		}
+		res := &steps.ManifestsResult{Operation: "template"}
+		for _, obj := range objs {
+			res.Objects = append(res.Objects, steps.ObjectResult{
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       obj.GetKind(),
+				Name:       obj.GetName(),
+				Namespace:  obj.GetNamespace(),
+				Operation:  "template",
+			})
+		}
+		return objs, res, nil
+	case types.TypeChart:
+		res, err := r.chart.Handle(ctx, step.ID, step.With, steps.HandleOptions{
+			Namespace: r.ns,
+			Op:        steps.Create,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		objs, err := dynamic.ParseManifests([]byte(res.Manifest), "chart step "+step.ID)
+		return objs, res, err
+	case types.TypeWait, types.TypeJob, types.TypeSecret:
+		r.logger(fmt.Sprintf("[%s:%s]: nothing to render", step.Type, step.ID))
+		return nil, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("handler for step of type %q not found", step.Type)
+	}
+}
+
+// renderVar sets the variable of a var step. Values read from the cluster
+// must be provided through Options.Vars.
+func (r *Renderer) renderVar(step *types.Step) (*steps.VarResult, error) {
+	spec := types.Var{}
+	data, err := json.Marshal(step.With)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal var step input: %w", err)
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal var step input: %w", err)
+	}
+
+	if val, ok := r.vars[spec.Name]; ok {
+		return &steps.VarResult{Name: spec.Name, Value: val}, nil
+	}
+	if spec.ValueFrom != nil {
+		return nil, fmt.Errorf("variable %s is read from the cluster, provide its value in the vars file", spec.Name)
+	}
+
+	val := steps.EnvVars(r.env).Expand(spec.Value)
+	r.env.Set(spec.Name, val)
+
+	return &steps.VarResult{Name: spec.Name, Value: val}, nil
+}
+
+// executionOrder sorts the steps so that each one follows its dependencies,
+// keeping the declaration order among the steps that are ready.
+func executionOrder(list []*types.Step) ([]int, error) {
+	deps, err := types.ResolveDependencies(list, false)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]bool, len(list))
+	order := make([]int, 0, len(list))
+	for len(order) < len(list) {
+		next := -1
+		for i := range list {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for _, d := range deps[i] {
+				ready = ready && done[d]
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("unable to order the steps")
+		}
+		done[next] = true
+		order = append(order, next)
+	}
+
+	return order, nil
+}
+
+// Annotate sets an annotation on the object, keeping the existing ones.
+func Annotate(obj *unstructured.Unstructured, key, value string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
+}
+
+// Write writes the objects as a multi-document YAML stream.
+func Write(w io.Writer, objs []*unstructured.Unstructured) error {
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadVarsFile reads a YAML map of variable names to values.
+func LoadVarsFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vars file: %w", err)
+	}
+
+	raw := map[string]any{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse vars file %s: %w", path, err)
+	}
+
+	out := make(map[string]string, len(raw))
+	for k, v := range raw {
+		out[k] = steps.Strval(v)
+	}
+	return out, nil
+}
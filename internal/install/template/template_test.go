@@ -0,0 +1,124 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+)
+
+func TestRendererRender(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "crds.yaml")
+	content := "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: ${PREFIX}-sa\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n"
+	if err := os.WriteFile(manifest, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	list := []*types.Step{
+		{ID: "prefix", Type: types.TypeVar, With: &map[string]any{"name": "PREFIX", "value": "krateo"}},
+		{ID: "host", Type: types.TypeVar, With: &map[string]any{
+			"name": "HOST",
+			"valueFrom": map[string]any{
+				"apiVersion": "v1", "kind": "ConfigMap",
+				"metadata": map[string]any{"name": "cluster-info"},
+				"selector": ".data.host",
+			},
+		}},
+		{ID: "manifests", Type: types.TypeManifests, With: &map[string]any{"source": map[string]any{"file": manifest}}},
+		{ID: "config", Type: types.TypeObject, With: &map[string]any{
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]any{"name": "${PREFIX}-config"},
+			"data":     map[string]any{"host": "${HOST}", "sa": "${steps.manifests.objects.0.name}"},
+		}},
+		{ID: "disabled", Type: types.TypeObject, Skip: true, With: &map[string]any{"apiVersion": "v1", "kind": "ConfigMap"}},
+		{ID: "conditional", Type: types.TypeObject, When: `.env.PREFIX == "other"`, With: &map[string]any{"apiVersion": "v1", "kind": "ConfigMap"}},
+		{ID: "wait", Type: types.TypeWait, With: &map[string]any{}},
+	}
+
+	r := New(Options{Namespace: "krateo-system", Vars: map[string]string{"HOST": "krateo.example.com"}})
+	objs, err := r.Render(context.Background(), list)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	if len(objs) != 3 {
+		t.Fatalf("Render() returned %d objects, want 3", len(objs))
+	}
+
+	wantNames := []string{"krateo-sa", "settings", "krateo-config"}
+	wantSteps := []string{"manifests", "manifests", "config"}
+	for i, obj := range objs {
+		if obj.GetName() != wantNames[i] {
+			t.Fatalf("object %d name = %q, want %q", i, obj.GetName(), wantNames[i])
+		}
+		if got := obj.GetAnnotations()[StepIDAnnotation]; got != wantSteps[i] {
+			t.Fatalf("object %d step annotation = %q, want %q", i, got, wantSteps[i])
+		}
+	}
+
+	cfg := objs[2]
+	if cfg.GetNamespace() != "krateo-system" {
+		t.Fatalf("object namespace = %q, want krateo-system", cfg.GetNamespace())
+	}
+	data, _ := cfg.Object["data"].(map[string]any)
+	if data["host"] != "krateo.example.com" {
+		t.Fatalf("host = %v, want the value of the vars file", data["host"])
+	}
+
+	if data["sa"] != "krateo-sa" {
+		t.Fatalf("sa = %v, want the output of the manifests step", data["sa"])
+	}
+
+	if got := r.Outputs()["steps.config.name"]; got != "krateo-config" {
+		t.Fatalf("steps.config.name = %q, want krateo-config", got)
+	}
+	if _, ok := r.Outputs()["PREFIX"]; ok {
+		t.Fatalf("Outputs() must hold step outputs only")
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, objs); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := strings.Count(buf.String(), "---\n"); got != 3 {
+		t.Fatalf("Write() wrote %d documents, want 3", got)
+	}
+}
+
+func TestRendererRenderRequiresClusterVars(t *testing.T) {
+	list := []*types.Step{
+		{ID: "host", Type: types.TypeVar, With: &map[string]any{
+			"name":      "HOST",
+			"valueFrom": map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "cluster-info"}},
+		}},
+	}
+
+	_, err := New(Options{Namespace: "krateo-system"}).Render(context.Background(), list)
+	if err == nil || !strings.Contains(err.Error(), "HOST") {
+		t.Fatalf("Render() error = %v, want a missing variable error", err)
+	}
+}
+
+func TestLoadVarsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vars.yaml")
+	if err := os.WriteFile(path, []byte("HOST: krateo.example.com\nREPLICAS: 3\nDEBUG: true\n"), 0o644); err != nil {
+		t.Fatalf("failed to write vars file: %v", err)
+	}
+
+	vars, err := LoadVarsFile(path)
+	if err != nil {
+		t.Fatalf("LoadVarsFile() error = %v", err)
+	}
+
+	want := map[string]string{"HOST": "krateo.example.com", "REPLICAS": "3", "DEBUG": "true"}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Fatalf("vars[%s] = %q, want %q", k, vars[k], v)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package redact masks sensitive values, such as the ones read from Secrets,
+// in logs, reports, plans and installation snapshots.
+package redact
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mask replaces a sensitive value.
+const Mask = "***"
+
+// Values is the set of sensitive values collected by a workflow run. It is
+// safe for concurrent use; the zero value is empty and ready to use.
+type Values struct {
+	mu     sync.RWMutex
+	values []string // Longest first, so that a value containing another one is masked whole.
+}
+
+// Add taints the given values. Empty values are ignored.
+func (v *Values) Add(values ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for _, value := range values {
+		if value == "" || slices.Contains(v.values, value) {
+			continue
+		}
+		v.values = append(v.values, value)
+	}
+	slices.SortFunc(v.values, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+}
+
+// Taints reports whether s contains a sensitive value.
+func (v *Values) Taints(s string) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return slices.ContainsFunc(v.values, func(value string) bool {
+		return strings.Contains(s, value)
+	})
+}
+
+// String replaces every sensitive value found in s with Mask.
+func (v *Values) String(s string) string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	for _, value := range v.values {
+		s = strings.ReplaceAll(s, value, Mask)
+	}
+	return s
+}
+
+// Logger wraps logger so that the messages never carry a sensitive value.
+func (v *Values) Logger(logger func(string, ...any)) func(string, ...any) {
+	return func(format string, args ...any) {
+		logger("%s", v.String(fmt.Sprintf(format, args...)))
+	}
+}
+
+// Secret returns obj with the data and stringData values masked when it is a
+// Secret manifest, and obj itself otherwise. obj is never modified.
+func Secret(obj map[string]any) map[string]any {
+	if kind, _ := obj["kind"].(string); kind != "Secret" {
+		return obj
+	}
+
+	out := make(map[string]any, len(obj))
+	for k, val := range obj {
+		out[k] = val
+	}
+	for _, field := range []string{"data", "stringData"} {
+		if data, ok := obj[field].(map[string]any); ok {
+			out[field] = maskValues(data)
+		}
+	}
+	return out
+}
+
+// maskValues returns a copy of m where every value is Mask.
+func maskValues(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k := range m {
+		out[k] = Mask
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package redact
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValuesString(t *testing.T) {
+	var v Values
+	v.Add("s3cr3t", "", "s3cr3t-admin")
+
+	if got := v.String("password=s3cr3t-admin user=s3cr3t"); got != "password=*** user=***" {
+		t.Fatalf("String() = %q", got)
+	}
+	if !v.Taints("jdbc://app:s3cr3t@db") || v.Taints("jdbc://app@db") {
+		t.Fatalf("Taints() does not match the sensitive values")
+	}
+
+	var logged string
+	v.Logger(func(format string, args ...any) {
+		logged = args[0].(string)
+	})("value: %s", "s3cr3t")
+	if logged != "value: ***" {
+		t.Fatalf("Logger() logged %q", logged)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	obj := map[string]any{
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": "db"},
+		"stringData": map[string]any{"password": "s3cr3t"},
+	}
+
+	got := Secret(obj)
+	want := map[string]any{
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": "db"},
+		"stringData": map[string]any{"password": Mask},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Secret() = %v, want %v", got, want)
+	}
+	if obj["stringData"].(map[string]any)["password"] != "s3cr3t" {
+		t.Fatalf("Secret() modified the object")
+	}
+
+	cm := map[string]any{"kind": "ConfigMap", "data": map[string]any{"key": "value"}}
+	if !reflect.DeepEqual(Secret(cm), cm) {
+		t.Fatalf("Secret() masked a ConfigMap")
+	}
+}
@@ -0,0 +1,33 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+
+	rtv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+)
+
+func GetConfigMapValue(ctx context.Context, dyn getter.Getter, configMapKeySelector rtv1.ConfigMapKeySelector) (string, error) {
+	uns, err := dyn.Get(ctx, getter.GetOptions{
+		GVK:       corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+		Namespace: configMapKeySelector.Namespace,
+		Name:      configMapKeySelector.Name,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	val, ok, err := unstructured.NestedString(uns.Object, "data", configMapKeySelector.Key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("key %s not found in configmap %s/%s", configMapKeySelector.Key, configMapKeySelector.Namespace, configMapKeySelector.Name)
+	}
+
+	return val, nil
+}
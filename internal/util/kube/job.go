@@ -50,7 +50,10 @@ func (jw *JobWaiter) Wait(ctx context.Context, namespace, jobName string) error
 	for {
 		select {
 		case <-timeoutCtx.Done():
-			return fmt.Errorf("timeout waiting for Job %s/%s to complete after %v", namespace, jobName, jw.timeout)
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("stopped waiting for Job %s/%s: %w", namespace, jobName, err)
+			}
+			return fmt.Errorf("timeout waiting for Job %s/%s to complete after %v: %w", namespace, jobName, jw.timeout, context.DeadlineExceeded)
 		case <-ticker.C:
 			status, err := jw.checkStatus(timeoutCtx, namespace, jobName)
 			if err != nil {
@@ -0,0 +1,161 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/krateoplatformops/krateoctl/internal/dynamic"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// EvalCondition evaluates a step when expression.
+//
+// The expression runs against a document with two keys:
+//
+//	env:   the workflow variables, e.g. .env.INGRESS_CLASS
+//	steps: the results of the steps completed so far, keyed by step id
+//
+// The condition holds unless the first value produced is false or null,
+// following jq truthiness. An expression that produces no value is false.
+func EvalCondition(ctx context.Context, expr string, env map[string]string, results map[string]any) (bool, error) {
+	vars := make(map[string]any, len(env))
+	for k, v := range env {
+		vars[k] = v
+	}
+
+	stepResults := make(map[string]any, len(results))
+	for id, res := range results {
+		val, err := toJSONValue(res)
+		if err != nil {
+			return false, fmt.Errorf("result of step %q: %w", id, err)
+		}
+		stepResults[id] = val
+	}
+
+	doc := &unstructured.Unstructured{Object: map[string]any{
+		"env":   vars,
+		"steps": stepResults,
+	}}
+
+	val, err := dynamic.Extract(ctx, doc, expr)
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return dynamic.Truthy(val), nil
+}
+
+// ConditionPreview is the offline outcome of a step when expression.
+type ConditionPreview struct {
+	// Skipped is true when the condition is known to be false.
+	Skipped bool
+	// Reason explains the outcome in a human readable form.
+	Reason string
+}
+
+// PreviewConditions evaluates the when expressions without contacting the cluster.
+//
+// Only var steps with a literal value contribute to the environment. Conditions
+// that reference step results or variables read from the cluster are reported
+// as evaluated at apply time.
+func PreviewConditions(ctx context.Context, list []*types.Step) map[string]ConditionPreview {
+	env := map[string]string{}
+	var unknown []string
+
+	vars := steps.MapVars(env)
+
+	out := map[string]ConditionPreview{}
+	for _, step := range list {
+		if step == nil || step.Skip {
+			continue
+		}
+
+		name, value, static := staticVar(step)
+
+		if step.When != "" {
+			if preview, run := previewCondition(ctx, step.When, env, unknown); !run {
+				out[step.ID] = preview
+				if !preview.Skipped && name != "" {
+					unknown = append(unknown, name)
+				}
+				continue
+			}
+		}
+
+		switch {
+		case name == "":
+		case static:
+			env[name] = vars.Expand(value)
+		default:
+			unknown = append(unknown, name)
+		}
+	}
+
+	return out
+}
+
+// previewCondition evaluates expr against the statically known variables and
+// reports whether the step would run. Expressions that depend on values only
+// known at apply time are never considered false.
+func previewCondition(ctx context.Context, expr string, env map[string]string, unknown []string) (ConditionPreview, bool) {
+	deferred := strings.Contains(expr, ".steps")
+	for _, name := range unknown {
+		deferred = deferred || strings.Contains(expr, name)
+	}
+	if deferred {
+		return ConditionPreview{Reason: fmt.Sprintf("when condition %q is evaluated at apply time", expr)}, false
+	}
+
+	ok, err := EvalCondition(ctx, expr, env, nil)
+	if err != nil {
+		return ConditionPreview{Reason: fmt.Sprintf("when condition %q is invalid: %v", expr, err)}, false
+	}
+	if !ok {
+		return ConditionPreview{Skipped: true, Reason: skipReason(expr)}, false
+	}
+	return ConditionPreview{}, true
+}
+
+// staticVar returns the variable set by a var step and whether its value is
+// known without reading from the cluster.
+func staticVar(step *types.Step) (name, value string, static bool) {
+	if step.Type != types.TypeVar || step.With == nil {
+		return "", "", false
+	}
+
+	name, _ = (*step.With)["name"].(string)
+	value, _ = (*step.With)["value"].(string)
+	_, hasValueFrom := (*step.With)["valueFrom"]
+	return name, value, !hasValueFrom
+}
+
+func skipReason(expr string) string {
+	return fmt.Sprintf("when condition %q is false", expr)
+}
+
+// toJSONValue converts a step result into plain JSON types, as expected by gojq.
+func toJSONValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
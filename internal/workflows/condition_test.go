@@ -0,0 +1,69 @@
+package workflows
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+)
+
+func TestEvalCondition(t *testing.T) {
+	env := map[string]string{
+		"INGRESS_CLASS": "nginx",
+		"EMPTY":         "",
+	}
+	results := map[string]any{
+		"install-db": &steps.ChartResult{ReleaseName: "db", Status: "deployed"},
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "non empty variable", expr: `.env.INGRESS_CLASS != ""`, want: true},
+		{name: "empty variable", expr: `.env.EMPTY != ""`, want: false},
+		{name: "missing variable is null", expr: `.env.MISSING`, want: false},
+		{name: "string value is truthy", expr: `.env.INGRESS_CLASS`, want: true},
+		{name: "step result", expr: `.steps["install-db"].status == "deployed"`, want: true},
+		{name: "missing step result", expr: `.steps["install-ui"] != null`, want: false},
+		{name: "no output", expr: `empty`, want: false},
+		{name: "invalid expression", expr: `.env.[`, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := EvalCondition(context.Background(), tc.expr, env, results)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("EvalCondition() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Fatalf("EvalCondition() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPreviewConditions(t *testing.T) {
+	list := []*types.Step{
+		{ID: "class", Type: types.TypeVar, With: &map[string]any{"name": "CLASS", "value": ""}},
+		{ID: "host", Type: types.TypeVar, With: &map[string]any{"name": "HOST", "valueFrom": map[string]any{"kind": "Service"}}},
+		{ID: "ingress", Type: types.TypeChart, When: `.env.CLASS != ""`},
+		{ID: "dns", Type: types.TypeChart, When: `.env.HOST != ""`},
+		{ID: "ui", Type: types.TypeChart, When: `.env.CLASS == ""`},
+		{ID: "disabled", Type: types.TypeChart, When: `false`, Skip: true},
+	}
+
+	got := PreviewConditions(context.Background(), list)
+	want := map[string]ConditionPreview{
+		"ingress": {Skipped: true, Reason: `when condition ".env.CLASS != \"\"" is false`},
+		"dns":     {Reason: `when condition ".env.HOST != \"\"" is evaluated at apply time`},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("PreviewConditions() = %#v, want %#v", got, want)
+	}
+}
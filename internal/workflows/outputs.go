@@ -0,0 +1,88 @@
+package workflows
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+)
+
+// StepOutputsPrefix prefixes the variables holding the step results.
+const StepOutputsPrefix = "steps."
+
+// StepOutputs flattens a step result into variables named
+// steps.<id>.<field>, e.g. steps.install-authn.revision. Nested fields are
+// joined with dots and list items are addressed by index; null values are omitted.
+func StepOutputs(id string, res any) (map[string]string, error) {
+	val, err := toJSONValue(res)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]string{}
+	flatten(StepOutputsPrefix+id, val, out)
+	return out, nil
+}
+
+func flatten(prefix string, val any, out map[string]string) {
+	switch v := val.(type) {
+	case nil:
+	case map[string]any:
+		for k, elem := range v {
+			flatten(prefix+"."+k, elem, out)
+		}
+	case []any:
+		for i, elem := range v {
+			flatten(prefix+"."+strconv.Itoa(i), elem, out)
+		}
+	case float64:
+		out[prefix] = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		out[prefix] = steps.Strval(v)
+	}
+}
+
+// ResultVars collects the outputs of the steps that completed, in this run or
+// in a previous one, so that they can be expanded outside of the workflow.
+func ResultVars(results []StepResult[any]) map[string]string {
+	out := map[string]string{}
+	for _, res := range results {
+		if res.ID() == "" || res.Err() != nil || res.res == nil {
+			continue
+		}
+		vars, err := StepOutputs(res.ID(), res.res)
+		if err != nil {
+			continue
+		}
+		for k, v := range vars {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// RunVars returns the variables set by the steps that completed: the values of
+// the var steps and the step outputs (see ResultVars). They can seed, through
+// Opts.Vars, a workflow that runs after this one.
+func RunVars(results []StepResult[any]) map[string]string {
+	out := ResultVars(results)
+	for _, res := range results {
+		if v, ok := res.res.(*steps.VarResult); ok && res.Err() == nil && v != nil {
+			out[v.Name] = v.Value
+		}
+	}
+	return out
+}
+
+// publish makes the result of a completed step available to the following
+// steps as ${steps.<id>.<field>} placeholders.
+func (wf *Workflow) publish(id string, res any) {
+	vars, err := StepOutputs(id, res)
+	if err != nil {
+		wf.logger(fmt.Sprintf("unable to publish the result of step %s: %v", id, err))
+		return
+	}
+	for k, v := range vars {
+		wf.env.Set(k, v)
+	}
+}
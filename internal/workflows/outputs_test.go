@@ -0,0 +1,78 @@
+package workflows
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+)
+
+func TestStepOutputs(t *testing.T) {
+	got, err := StepOutputs("install-authn", &steps.ChartResult{
+		ReleaseName:  "authn",
+		ChartVersion: "0.20.1",
+		Status:       "deployed",
+		Revision:     3,
+	})
+	if err != nil {
+		t.Fatalf("StepOutputs() error = %v", err)
+	}
+
+	want := map[string]string{
+		"steps.install-authn.releaseName": "authn",
+		"steps.install-authn.chartName":   "",
+		"steps.install-authn.version":     "0.20.1",
+		"steps.install-authn.appVersion":  "",
+		"steps.install-authn.namespace":   "",
+		"steps.install-authn.status":      "deployed",
+		"steps.install-authn.operation":   "",
+		"steps.install-authn.revision":    "3",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("StepOutputs() = %v, want %v", got, want)
+	}
+}
+
+func TestStepOutputsNested(t *testing.T) {
+	got, err := StepOutputs("crds", &steps.ManifestsResult{
+		Source: "crds/",
+		Objects: []steps.ObjectResult{
+			{Kind: "CustomResourceDefinition", Name: "a"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StepOutputs() error = %v", err)
+	}
+
+	if v := got["steps.crds.objects.0.name"]; v != "a" {
+		t.Fatalf("steps.crds.objects.0.name = %q, want %q", v, "a")
+	}
+	if v := got["steps.crds.source"]; v != "crds/" {
+		t.Fatalf("steps.crds.source = %q, want %q", v, "crds/")
+	}
+}
+
+func TestRunPublishesStepOutputs(t *testing.T) {
+	h := &fakeVarHandler{}
+	wf := newFakeWorkflow(h, 1)
+
+	spec := &types.Workflow{Steps: []*types.Step{
+		{ID: "install-authn", Type: types.TypeChart},
+		{ID: "install-ui", Type: types.TypeChart, When: `.env["steps.install-authn.releaseName"] == "install-authn"`},
+	}}
+	results := wf.Run(context.Background(), spec, nil, nil)
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if v, _ := wf.env.Get("steps.install-ui.releaseName"); v != "install-ui" {
+		t.Fatalf("steps.install-ui.releaseName = %q, want %q", v, "install-ui")
+	}
+
+	vars := ResultVars(results)
+	if got := vars["steps.install-authn.releaseName"]; got != "install-authn" {
+		t.Fatalf("ResultVars() releaseName = %q, want %q", got, "install-authn")
+	}
+}
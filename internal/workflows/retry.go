@@ -0,0 +1,102 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// executeWithRetry runs a step until it succeeds, its retry policy is exhausted
+// or the error is not eligible for a retry. It returns the number of attempts made.
+// Every attempt is bounded by the step timeout, and no attempt follows the
+// cancellation of ctx.
+func (wf *Workflow) executeWithRetry(ctx context.Context, x *types.Step) (res any, attempts int, err error) {
+	maxAttempts := x.Retry.MaxAttempts()
+
+	for attempts = 1; ; attempts++ {
+		res, err = wf.attempt(ctx, x)
+		if err == nil || ctx.Err() != nil || attempts >= maxAttempts || !retryable(err, x.Retry.RetryOn) {
+			return res, attempts, err
+		}
+
+		delay := x.Retry.Delay(attempts)
+		wf.logger(fmt.Sprintf("step %s failed (attempt %d/%d), retrying in %s: %v", x.ID, attempts, maxAttempts, delay, err))
+
+		select {
+		case <-ctx.Done():
+			return res, attempts, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// errAttemptTimeout marks the error of an attempt that outlived the step timeout.
+var errAttemptTimeout = errors.New("step timed out")
+
+// attempt executes the step once within its timeout. The error of an attempt
+// that outlives it says so, which makes it eligible for the timeout retry condition.
+func (wf *Workflow) attempt(ctx context.Context, x *types.Step) (any, error) {
+	timeout := x.AttemptTimeout()
+	if timeout <= 0 {
+		return wf.execute(ctx, x)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := wf.execute(attemptCtx, x)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w after %s: %w", errAttemptTimeout, timeout, err)
+	}
+	return res, err
+}
+
+// retryable reports whether err matches one of the given conditions.
+// An empty list of conditions matches every error.
+func retryable(err error, conditions []types.RetryCondition) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if len(conditions) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(conditions, func(cond types.RetryCondition) bool {
+		return matchesCondition(err, cond)
+	})
+}
+
+// matchesCondition matches the API and context errors of a condition. Only the
+// messages the API server and the Helm client do not return as typed errors are
+// matched as text, so that a configuration error, such as a key not found in a
+// Secret, is not retried.
+func matchesCondition(err error, cond types.RetryCondition) bool {
+	msg := strings.ToLower(err.Error())
+
+	switch cond {
+	case types.RetryOnConflict:
+		return apierrors.IsConflict(err) ||
+			strings.Contains(msg, "the object has been modified")
+	case types.RetryOnTimeout:
+		var netErr net.Error
+		return apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) ||
+			apierrors.IsServiceUnavailable(err) || apierrors.IsTooManyRequests(err) ||
+			errors.Is(err, context.DeadlineExceeded) || errors.Is(err, errAttemptTimeout) ||
+			(errors.As(err, &netErr) && netErr.Timeout())
+	case types.RetryOnWebhook:
+		return strings.Contains(msg, "failed calling webhook")
+	case types.RetryOnNotFound:
+		return apierrors.IsNotFound(err) || meta.IsNoMatchError(err) ||
+			strings.Contains(msg, "no matches for kind")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,101 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRetryable(t *testing.T) {
+	gr := schema.GroupResource{Group: "postgresql.cnpg.io", Resource: "clusters"}
+
+	tests := []struct {
+		name       string
+		err        error
+		conditions []types.RetryCondition
+		want       bool
+	}{
+		{
+			name: "any error without conditions",
+			err:  fmt.Errorf("boom"),
+			want: true,
+		},
+		{
+			name: "canceled context is never retried",
+			err:  fmt.Errorf("apply: %w", context.Canceled),
+			want: false,
+		},
+		{
+			name:       "conflict",
+			err:        fmt.Errorf("apply: %w", apierrors.NewConflict(gr, "db", fmt.Errorf("changed"))),
+			conditions: []types.RetryCondition{types.RetryOnConflict},
+			want:       true,
+		},
+		{
+			name:       "server timeout",
+			err:        apierrors.NewServerTimeout(gr, "create", 1),
+			conditions: []types.RetryCondition{types.RetryOnTimeout},
+			want:       true,
+		},
+		{
+			name:       "deadline exceeded",
+			err:        fmt.Errorf("wait: %w", context.DeadlineExceeded),
+			conditions: []types.RetryCondition{types.RetryOnTimeout},
+			want:       true,
+		},
+		{
+			name:       "webhook not ready",
+			err:        fmt.Errorf(`Internal error occurred: failed calling webhook "mcluster.cnpg.io": connection refused`),
+			conditions: []types.RetryCondition{types.RetryOnWebhook},
+			want:       true,
+		},
+		{
+			name:       "not found",
+			err:        apierrors.NewNotFound(gr, "db"),
+			conditions: []types.RetryCondition{types.RetryOnNotFound},
+			want:       true,
+		},
+		{
+			name:       "kind not registered yet",
+			err:        fmt.Errorf(`no matches for kind "Cluster" in version "postgresql.cnpg.io/v1"`),
+			conditions: []types.RetryCondition{types.RetryOnNotFound},
+			want:       true,
+		},
+		{
+			name:       "attempt timeout",
+			err:        fmt.Errorf("%w after 1m0s: %w", errAttemptTimeout, fmt.Errorf("chart install interrupted")),
+			conditions: []types.RetryCondition{types.RetryOnTimeout},
+			want:       true,
+		},
+		{
+			name:       "configuration error mentioning not found",
+			err:        fmt.Errorf("valuesFrom[0]: key password not found in secret krateo-system/db"),
+			conditions: []types.RetryCondition{types.RetryOnNotFound},
+			want:       false,
+		},
+		{
+			name:       "configuration error mentioning a timeout",
+			err:        fmt.Errorf(`invalid timeout "5x" in step install-authn`),
+			conditions: []types.RetryCondition{types.RetryOnTimeout},
+			want:       false,
+		},
+		{
+			name:       "condition does not match",
+			err:        apierrors.NewNotFound(gr, "db"),
+			conditions: []types.RetryCondition{types.RetryOnConflict, types.RetryOnWebhook},
+			want:       false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := retryable(tc.err, tc.conditions); got != tc.want {
+				t.Fatalf("retryable() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
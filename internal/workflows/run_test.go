@@ -0,0 +1,572 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeVarHandler struct {
+	mu      sync.Mutex
+	order   []string
+	fail    map[string]bool
+	flaky   map[string]int
+	block   map[string]bool // Steps that run until their context is cancelled
+	delay   time.Duration
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (h *fakeVarHandler) Handle(ctx context.Context, id string, _ *map[string]any, _ steps.HandleOptions) (*steps.VarResult, error) {
+	n := h.running.Add(1)
+	defer h.running.Add(-1)
+	for {
+		peak := h.peak.Load()
+		if n <= peak || h.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+
+	time.Sleep(h.delay)
+	if h.block[id] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.order = append(h.order, id)
+
+	if h.fail[id] {
+		return nil, fmt.Errorf("boom")
+	}
+	if h.flaky[id] > 0 {
+		h.flaky[id]--
+		return nil, apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, id, fmt.Errorf("the object has been modified"))
+	}
+	return &steps.VarResult{}, nil
+}
+
+// fakeChartHandler records chart steps in the same handler as var steps.
+type fakeChartHandler struct {
+	*fakeVarHandler
+}
+
+func (h fakeChartHandler) Handle(ctx context.Context, id string, in *map[string]any, opts steps.HandleOptions) (*steps.ChartResult, error) {
+	if _, err := h.fakeVarHandler.Handle(ctx, id, in, opts); err != nil {
+		return nil, err
+	}
+	return &steps.ChartResult{ReleaseName: id}, nil
+}
+
+// fakeSecretHandler records secret steps in the same handler as var steps and
+// exports DB_PASSWORD, as a secret step reading it from a Secret would.
+type fakeSecretHandler struct {
+	*fakeVarHandler
+	env *cache.Cache[string, string]
+}
+
+func (h fakeSecretHandler) Handle(ctx context.Context, id string, in *map[string]any, opts steps.HandleOptions) (*steps.SecretResult, error) {
+	if _, err := h.fakeVarHandler.Handle(ctx, id, in, opts); err != nil {
+		return nil, err
+	}
+	h.env.Set("DB_PASSWORD", "s3cr3t")
+	return &steps.SecretResult{}, nil
+}
+
+// fakeJobHandler records job steps in the same handler as var steps.
+type fakeJobHandler struct {
+	*fakeVarHandler
+}
+
+func (h fakeJobHandler) Handle(ctx context.Context, id string, in *map[string]any, opts steps.HandleOptions) (*steps.JobResult, error) {
+	if _, err := h.fakeVarHandler.Handle(ctx, id, in, opts); err != nil {
+		return nil, err
+	}
+	return &steps.JobResult{Name: id}, nil
+}
+
+func newFakeWorkflow(h *fakeVarHandler, parallelism int) *Workflow {
+	env := cache.New[string, string]()
+	return &Workflow{
+		logger:        func(string, ...any) {},
+		parallelism:   parallelism,
+		env:           env,
+		varHandler:    h,
+		chartHandler:  fakeChartHandler{h},
+		secretHandler: fakeSecretHandler{h, env},
+		jobHandler:    fakeJobHandler{h},
+	}
+}
+
+func varStep(id string, deps ...string) *types.Step {
+	return &types.Step{ID: id, Type: types.TypeVar, DependsOn: deps}
+}
+
+func TestRunSequentialWithoutDependencies(t *testing.T) {
+	h := &fakeVarHandler{}
+	wf := newFakeWorkflow(h, 4)
+
+	spec := &types.Workflow{Steps: []*types.Step{varStep("a"), varStep("b"), varStep("c")}}
+	results := wf.Run(context.Background(), spec, nil, nil)
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(h.order, want) {
+		t.Fatalf("order = %v, want %v", h.order, want)
+	}
+	if got := h.peak.Load(); got != 1 {
+		t.Fatalf("peak concurrency = %d, want 1", got)
+	}
+}
+
+func TestRunParallelHonoursDependencies(t *testing.T) {
+	h := &fakeVarHandler{delay: 20 * time.Millisecond}
+	wf := newFakeWorkflow(h, 2)
+
+	spec := &types.Workflow{Steps: []*types.Step{
+		varStep("a"),
+		varStep("b", "a"),
+		varStep("c", "a"),
+		varStep("d", "b", "c"),
+	}}
+
+	var notified []int
+	results := wf.Run(context.Background(), spec, nil, func(idx int, _ *types.Step, _ bool) {
+		notified = append(notified, idx)
+	})
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := h.peak.Load(); got != 2 {
+		t.Fatalf("peak concurrency = %d, want 2", got)
+	}
+	if last := h.order[len(h.order)-1]; last != "d" {
+		t.Fatalf("last step = %q, want %q", last, "d")
+	}
+	if len(notified) != 4 {
+		t.Fatalf("notifications = %v, want 4", notified)
+	}
+	for i, res := range results {
+		if res.ID() != spec.Steps[i].ID {
+			t.Fatalf("results[%d].ID() = %q, want %q", i, res.ID(), spec.Steps[i].ID)
+		}
+		if res.Duration() < h.delay {
+			t.Fatalf("results[%d].Duration() = %v, want at least %v", i, res.Duration(), h.delay)
+		}
+	}
+}
+
+func TestRunFailureStopsDependents(t *testing.T) {
+	h := &fakeVarHandler{fail: map[string]bool{"db": true}}
+	wf := newFakeWorkflow(h, 1)
+
+	spec := &types.Workflow{Steps: []*types.Step{
+		varStep("crds"),
+		varStep("db", "crds"),
+		varStep("backend", "db"),
+	}}
+
+	results := wf.Run(context.Background(), spec, nil, nil)
+
+	err := Err(results)
+	if err == nil {
+		t.Fatalf("Run() expected an error")
+	}
+	if want := "db (branch: crds -> db): boom"; err.Error() != want {
+		t.Fatalf("Err() = %q, want %q", err.Error(), want)
+	}
+	if results[2].ID() != "" {
+		t.Fatalf("dependent step should not start, got result %q", results[2].ID())
+	}
+}
+
+func TestRunDryRunReportsEveryFailure(t *testing.T) {
+	h := &fakeVarHandler{fail: map[string]bool{"db": true, "cache": true}}
+	wf := newFakeWorkflow(h, 1)
+	wf.dryRun = true
+
+	spec := &types.Workflow{Steps: []*types.Step{
+		varStep("crds"),
+		varStep("db", "crds"),
+		varStep("backend", "db"),
+		varStep("cache", "crds"),
+		varStep("frontend", "crds"),
+	}}
+
+	results := wf.Run(context.Background(), spec, nil, nil)
+
+	if want := []string{"crds", "db", "cache", "frontend"}; !reflect.DeepEqual(h.order, want) {
+		t.Fatalf("order = %v, want %v", h.order, want)
+	}
+	if results[1].Err() == nil || results[3].Err() == nil {
+		t.Fatalf("both failures should be reported, got %v and %v", results[1].Err(), results[3].Err())
+	}
+	if results[2].ID() != "" {
+		t.Fatalf("dependent step should not start, got result %q", results[2].ID())
+	}
+}
+
+func TestRunReportsCycles(t *testing.T) {
+	wf := newFakeWorkflow(&fakeVarHandler{}, 1)
+
+	spec := &types.Workflow{Steps: []*types.Step{
+		varStep("a", "b"),
+		varStep("b", "a"),
+	}}
+
+	err := Err(wf.Run(context.Background(), spec, nil, nil))
+	if err == nil {
+		t.Fatalf("Run() expected a cycle error")
+	}
+	if want := `a: step "a": dependency cycle detected: a -> b -> a`; err.Error() != want {
+		t.Fatalf("Err() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestRunSkipsStepsWhenConditionIsFalse(t *testing.T) {
+	h := &fakeVarHandler{}
+	wf := newFakeWorkflow(h, 1)
+	wf.env.Set("INGRESS_CLASS", "")
+
+	ingress := varStep("ingress")
+	ingress.When = `.env.INGRESS_CLASS != ""`
+	backend := varStep("backend")
+	backend.When = `.steps.crds != null`
+
+	spec := &types.Workflow{Steps: []*types.Step{varStep("crds"), ingress, backend}}
+
+	var skipped []string
+	results := wf.Run(context.Background(), spec, nil, func(_ int, step *types.Step, skip bool) {
+		if skip {
+			skipped = append(skipped, step.ID)
+		}
+	})
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if want := []string{"crds", "backend"}; !reflect.DeepEqual(h.order, want) {
+		t.Fatalf("order = %v, want %v", h.order, want)
+	}
+	if want := []string{"ingress"}; !reflect.DeepEqual(skipped, want) {
+		t.Fatalf("skipped = %v, want %v", skipped, want)
+	}
+	if want := `when condition ".env.INGRESS_CLASS != \"\"" is false`; results[1].SkipReason() != want {
+		t.Fatalf("SkipReason() = %q, want %q", results[1].SkipReason(), want)
+	}
+}
+
+func TestRunIgnoresConditionsWhenDeleting(t *testing.T) {
+	h := &fakeVarHandler{}
+	wf := newFakeWorkflow(h, 1)
+	wf.op = steps.Delete
+
+	backend := varStep("backend")
+	backend.When = `.steps.crds != null`
+
+	results := wf.Run(context.Background(), &types.Workflow{Steps: []*types.Step{varStep("crds"), backend}}, nil, nil)
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if want := []string{"backend", "crds"}; !reflect.DeepEqual(h.order, want) {
+		t.Fatalf("order = %v, want %v", h.order, want)
+	}
+}
+
+func TestRunFailsOnInvalidCondition(t *testing.T) {
+	wf := newFakeWorkflow(&fakeVarHandler{}, 1)
+
+	step := varStep("a")
+	step.When = `.env.[`
+
+	err := Err(wf.Run(context.Background(), &types.Workflow{Steps: []*types.Step{step}}, nil, nil))
+	if err == nil || !strings.Contains(err.Error(), "evaluate when condition") {
+		t.Fatalf("Err() = %v, want when condition error", err)
+	}
+}
+
+func TestRunRetriesFailedSteps(t *testing.T) {
+	tests := []struct {
+		name         string
+		retry        *types.RetryPolicy
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "no retry policy",
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "succeeds within the attempts",
+			retry:        &types.RetryPolicy{Attempts: 3, Backoff: "1ms"},
+			wantAttempts: 3,
+		},
+		{
+			name:         "attempts exhausted",
+			retry:        &types.RetryPolicy{Attempts: 2, Backoff: "1ms"},
+			wantAttempts: 2,
+			wantErr:      true,
+		},
+		{
+			name:         "error not eligible",
+			retry:        &types.RetryPolicy{Attempts: 3, Backoff: "1ms", RetryOn: []types.RetryCondition{types.RetryOnWebhook}},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &fakeVarHandler{flaky: map[string]int{"a": 2}}
+			wf := newFakeWorkflow(h, 1)
+
+			step := varStep("a")
+			step.Retry = tc.retry
+
+			results := wf.Run(context.Background(), &types.Workflow{Steps: []*types.Step{step}}, nil, nil)
+			if err := Err(results); (err != nil) != tc.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got := results[0].Attempts(); got != tc.wantAttempts {
+				t.Fatalf("Attempts() = %d, want %d", got, tc.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRunStepTimeout(t *testing.T) {
+	h := &fakeVarHandler{block: map[string]bool{"a": true}}
+	wf := newFakeWorkflow(h, 1)
+
+	step := varStep("a")
+	step.Timeout = "20ms"
+	step.Retry = &types.RetryPolicy{Attempts: 2, Backoff: "1ms", RetryOn: []types.RetryCondition{types.RetryOnTimeout}}
+
+	results := wf.Run(context.Background(), &types.Workflow{Steps: []*types.Step{step, varStep("b")}}, nil, nil)
+	err := Err(results)
+	if err == nil || !strings.Contains(err.Error(), "step timed out after 20ms") {
+		t.Fatalf("Run() error = %v, want the step timeout", err)
+	}
+	if got := results[0].Attempts(); got != 2 {
+		t.Fatalf("Attempts() = %d, want the timed out attempt to be retried", got)
+	}
+	if results[0].Interrupted() {
+		t.Fatalf("Interrupted() = true, want a step timeout not to interrupt the run")
+	}
+	if results[1].ID() != "" {
+		t.Fatalf("step b ran after the timeout of step a")
+	}
+}
+
+func TestRunInterrupted(t *testing.T) {
+	h := &fakeVarHandler{block: map[string]bool{"b": true}}
+	wf := newFakeWorkflow(h, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	step := varStep("b")
+	step.Retry = &types.RetryPolicy{Attempts: 3, Backoff: "1ms"}
+	spec := &types.Workflow{Steps: []*types.Step{varStep("a"), step, varStep("c")}}
+	results := wf.Run(ctx, spec, nil, nil)
+
+	if results[0].Err() != nil || results[0].Interrupted() {
+		t.Fatalf("step a = %v, want it to complete before the interrupt", results[0].Err())
+	}
+	if !results[1].Interrupted() || results[1].Attempts() != 1 {
+		t.Fatalf("step b interrupted = %v after %d attempts, want it interrupted without retries", results[1].Interrupted(), results[1].Attempts())
+	}
+	if results[2].ID() != "" {
+		t.Fatalf("step c started after the interrupt")
+	}
+}
+
+func TestRunResumesCompletedSteps(t *testing.T) {
+	h := &fakeVarHandler{}
+	wf := newFakeWorkflow(h, 1)
+
+	chart := func(id, version string) *types.Step {
+		return &types.Step{ID: id, Type: types.TypeChart, With: &map[string]any{"version": version}}
+	}
+	spec := &types.Workflow{Steps: []*types.Step{
+		varStep("vars"),
+		chart("crds", "1.0.0"),
+		chart("backend", "2.0.0"),
+		chart("frontend", "3.0.0"),
+	}}
+
+	digest := func(step *types.Step) string {
+		d, err := types.Digest(step)
+		if err != nil {
+			t.Fatalf("Digest() error = %v", err)
+		}
+		return d
+	}
+	wf.completed = map[string]Checkpoint{
+		"vars":    {Digest: digest(spec.Steps[0])},
+		"crds":    {Digest: digest(spec.Steps[1]), Result: map[string]any{"releaseName": "crds"}},
+		"backend": {Digest: digest(chart("backend", "1.9.0"))},
+	}
+
+	var recorded []string
+	wf.onCompleted = func(res StepResult[any]) {
+		recorded = append(recorded, res.ID())
+		if res.Digest() == "" {
+			t.Fatalf("completed step %s has no digest", res.ID())
+		}
+	}
+
+	results := wf.Run(context.Background(), spec, nil, nil)
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if want := []string{"vars", "backend", "frontend"}; !reflect.DeepEqual(h.order, want) {
+		t.Fatalf("executed = %v, want %v", h.order, want)
+	}
+	if want := []string{"backend", "frontend"}; !reflect.DeepEqual(recorded, want) {
+		t.Fatalf("checkpoints = %v, want %v", recorded, want)
+	}
+	if !results[1].Resumed() || results[2].Resumed() {
+		t.Fatalf("Resumed() = %v, %v, want true, false", results[1].Resumed(), results[2].Resumed())
+	}
+	if got, want := results[1].Result(), any(map[string]any{"releaseName": "crds"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("resumed Result() = %v, want %v", got, want)
+	}
+}
+
+func TestRunDoesNotResumeStepsWhoseVariablesChanged(t *testing.T) {
+	h := &fakeVarHandler{}
+	wf := newFakeWorkflow(h, 1)
+	wf.env.Set("CHART_VERSION", "1.1.0")
+
+	step := &types.Step{ID: "backend", Type: types.TypeChart, With: &map[string]any{"version": "${CHART_VERSION}"}}
+	previous, err := types.Digest(&types.Step{ID: "backend", Type: types.TypeChart, With: &map[string]any{"version": "1.0.0"}})
+	if err != nil {
+		t.Fatalf("Digest() error = %v", err)
+	}
+	wf.completed = map[string]Checkpoint{"backend": {Digest: previous}}
+
+	results := wf.Run(context.Background(), &types.Workflow{Steps: []*types.Step{step}}, nil, nil)
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if results[0].Resumed() || !reflect.DeepEqual(h.order, []string{"backend"}) {
+		t.Fatalf("backend resumed = %v, executed = %v, want it executed again", results[0].Resumed(), h.order)
+	}
+
+	// The same variable value yields the digest of the previous run.
+	wf.env.Set("CHART_VERSION", "1.0.0")
+	if got, _ := wf.digest(step); got != previous {
+		t.Fatalf("digest() = %s, want the digest of the expanded input %s", got, previous)
+	}
+}
+
+func TestRunResumeExecutesSecretSteps(t *testing.T) {
+	h := &fakeVarHandler{}
+	wf := newFakeWorkflow(h, 1)
+
+	secret := &types.Step{ID: "db-credentials", Type: types.TypeSecret, With: &map[string]any{"name": "db"}}
+	chart := &types.Step{ID: "backend", Type: types.TypeChart, With: &map[string]any{"values": map[string]any{"password": "${DB_PASSWORD}"}}}
+
+	digest := func(step *types.Step) string {
+		d, err := types.Digest(step)
+		if err != nil {
+			t.Fatalf("Digest() error = %v", err)
+		}
+		return d
+	}
+	wf.completed = map[string]Checkpoint{
+		"db-credentials": {Digest: digest(secret)},
+		// The backend checkpoint matches only when ${DB_PASSWORD} is set again.
+		"backend": {Digest: digest(&types.Step{ID: "backend", Type: types.TypeChart, With: &map[string]any{"values": map[string]any{"password": "s3cr3t"}}})},
+	}
+
+	results := wf.Run(context.Background(), &types.Workflow{Steps: []*types.Step{secret, chart}}, nil, nil)
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if want := []string{"db-credentials"}; !reflect.DeepEqual(h.order, want) {
+		t.Fatalf("executed = %v, want %v", h.order, want)
+	}
+	if results[0].Resumed() || !results[1].Resumed() {
+		t.Fatalf("Resumed() = %v, %v, want false, true", results[0].Resumed(), results[1].Resumed())
+	}
+}
+
+func TestRunResumeRestoresJobOutputs(t *testing.T) {
+	h := &fakeVarHandler{}
+	wf := newFakeWorkflow(h, 1)
+
+	job := &types.Step{ID: "migrate", Type: types.TypeJob, With: &map[string]any{"template": map[string]any{}}}
+	chart := &types.Step{ID: "backend", Type: types.TypeChart, With: &map[string]any{"values": map[string]any{"schema": "${SCHEMA_VERSION}"}}}
+
+	digest := func(step *types.Step) string {
+		d, err := types.Digest(step)
+		if err != nil {
+			t.Fatalf("Digest() error = %v", err)
+		}
+		return d
+	}
+	wf.completed = map[string]Checkpoint{
+		// Checkpoint results are read back from the Installation status as maps.
+		"migrate": {Digest: digest(job), Result: map[string]any{"name": "migrate-x1", "outputs": map[string]any{"SCHEMA_VERSION": "42"}}},
+		// The backend checkpoint matches only when ${SCHEMA_VERSION} is set again.
+		"backend": {Digest: digest(&types.Step{ID: "backend", Type: types.TypeChart, With: &map[string]any{"values": map[string]any{"schema": "42"}}})},
+	}
+
+	results := wf.Run(context.Background(), &types.Workflow{Steps: []*types.Step{job, chart}}, nil, nil)
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(h.order) != 0 {
+		t.Fatalf("executed = %v, want both steps resumed", h.order)
+	}
+	if !results[0].Resumed() || !results[1].Resumed() {
+		t.Fatalf("Resumed() = %v, %v, want true, true", results[0].Resumed(), results[1].Resumed())
+	}
+	if got, _ := wf.env.Get("steps.migrate.outputs.SCHEMA_VERSION"); got != "42" {
+		t.Fatalf("${steps.migrate.outputs.SCHEMA_VERSION} = %q, want 42", got)
+	}
+}
+
+func TestRunResumeExecutesJobStepsWithSensitiveOutputs(t *testing.T) {
+	h := &fakeVarHandler{}
+	wf := newFakeWorkflow(h, 1)
+
+	job := &types.Step{ID: "bootstrap", Type: types.TypeJob, With: &map[string]any{"template": map[string]any{}}}
+	digest, err := types.Digest(job)
+	if err != nil {
+		t.Fatalf("Digest() error = %v", err)
+	}
+	// The checkpoint only holds the mask of the sensitive output.
+	wf.completed = map[string]Checkpoint{
+		"bootstrap": {Digest: digest, Result: map[string]any{"outputs": map[string]any{"TOKEN": "***"}, "sensitive": []any{"TOKEN"}}},
+	}
+
+	results := wf.Run(context.Background(), &types.Workflow{Steps: []*types.Step{job}}, nil, nil)
+	if err := Err(results); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if results[0].Resumed() || !reflect.DeepEqual(h.order, []string{"bootstrap"}) {
+		t.Fatalf("bootstrap resumed = %v, executed = %v, want it executed again", results[0].Resumed(), h.order)
+	}
+}
@@ -0,0 +1,57 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	helmconfig "github.com/krateoplatformops/plumbing/helm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// OperationRollback reports an atomic upgrade rolled back to the previous revision.
+	OperationRollback = "rollback"
+	// OperationRollbackUninstall reports an atomic install reverted by uninstalling the release.
+	OperationRollbackUninstall = "rollback/uninstall"
+)
+
+// revert undoes the failed install or upgrade of an atomic chart step: the release
+// goes back to the previous revision, or it is uninstalled when there is none.
+// The returned error always wraps cause, since the step still failed.
+func (r *chartStepHandler) revert(ctx context.Context, cli helmconfig.Client, id string, result *steps.ChartResult, previous *helmconfig.Release, spec *types.ChartSpec, cause error) error {
+	if previous == nil {
+		result.Operation = OperationRollbackUninstall
+		err := cli.Uninstall(ctx, result.ReleaseName, &helmconfig.UninstallConfig{
+			IgnoreNotFound: true,
+			Wait:           true,
+			Timeout:        spec.Timeout.Duration,
+		})
+		if err != nil {
+			return fmt.Errorf("%w (uninstall of release %s failed: %v)", cause, result.ReleaseName, err)
+		}
+
+		result.Status = "uninstalled"
+		result.Updated = metav1.Now()
+		r.logger(fmt.Sprintf("[chart:%s]: install failed, release %s uninstalled", id, result.ReleaseName))
+		return fmt.Errorf("%w (release %s uninstalled)", cause, result.ReleaseName)
+	}
+
+	result.Operation = OperationRollback
+	release, err := cli.Rollback(ctx, result.ReleaseName, &helmconfig.RollbackConfig{
+		ReleaseVersion: previous.Revision,
+		Timeout:        spec.Timeout.Duration,
+		Wait:           true,
+		CleanupOnFail:  true,
+		MaxHistory:     *spec.MaxHistory,
+	})
+	if err != nil {
+		return fmt.Errorf("%w (rollback to revision %d failed: %v)", cause, previous.Revision, err)
+	}
+
+	fillResult(result, release)
+	r.logger(fmt.Sprintf("[chart:%s]: upgrade failed, release %s rolled back to revision %d",
+		id, result.ReleaseName, previous.Revision))
+	return fmt.Errorf("%w (rolled back to revision %d)", cause, previous.Revision)
+}
@@ -0,0 +1,98 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	helmconfig "github.com/krateoplatformops/plumbing/helm"
+)
+
+type fakeHelmClient struct {
+	helmconfig.Client
+	current     *helmconfig.Release // Returned by GetRelease
+	rollback    *helmconfig.RollbackConfig
+	uninstalled string
+	err         error
+}
+
+func (f *fakeHelmClient) Rollback(_ context.Context, name string, cfg *helmconfig.RollbackConfig) (*helmconfig.Release, error) {
+	f.rollback = cfg
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &helmconfig.Release{Name: name, Namespace: "krateo-system", Revision: cfg.ReleaseVersion + 2, Status: helmconfig.StatusDeployed}, nil
+}
+
+func (f *fakeHelmClient) GetRelease(context.Context, string, *helmconfig.GetConfig) (*helmconfig.Release, error) {
+	return f.current, nil
+}
+
+func (f *fakeHelmClient) Uninstall(_ context.Context, name string, _ *helmconfig.UninstallConfig) error {
+	f.uninstalled = name
+	return f.err
+}
+
+func TestChartHandlerRevert(t *testing.T) {
+	cause := errors.New("failed to upgrade chart: timed out waiting for the condition")
+
+	tests := []struct {
+		name          string
+		previous      *helmconfig.Release
+		clientErr     error
+		wantOperation string
+		wantErr       string
+	}{
+		{
+			name:          "upgrade is rolled back",
+			previous:      &helmconfig.Release{Name: "authn", Revision: 3},
+			wantOperation: OperationRollback,
+			wantErr:       "rolled back to revision 3",
+		},
+		{
+			name:          "install is uninstalled",
+			wantOperation: OperationRollbackUninstall,
+			wantErr:       "release authn uninstalled",
+		},
+		{
+			name:          "rollback failure is reported",
+			previous:      &helmconfig.Release{Name: "authn", Revision: 3},
+			clientErr:     errors.New("release locked"),
+			wantOperation: OperationRollback,
+			wantErr:       "rollback to revision 3 failed: release locked",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &types.ChartSpec{URL: "https://charts.krateo.io/authn-0.20.1.tgz"}
+			spec.SetDefaults()
+
+			cli := &fakeHelmClient{err: tc.clientErr}
+			handler := &chartStepHandler{logger: func(string, ...any) {}}
+			result := newResult(spec, "krateo-system")
+
+			err := handler.revert(context.Background(), cli, "authn", result, tc.previous, spec, cause)
+			if !errors.Is(err, cause) {
+				t.Fatalf("revert() error = %v, want it to wrap the original failure", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("revert() error = %q, want it to contain %q", err, tc.wantErr)
+			}
+			if result.Operation != tc.wantOperation {
+				t.Fatalf("Operation = %q, want %q", result.Operation, tc.wantOperation)
+			}
+
+			switch {
+			case tc.previous != nil && cli.rollback.ReleaseVersion != tc.previous.Revision:
+				t.Fatalf("rolled back to revision %d, want %d", cli.rollback.ReleaseVersion, tc.previous.Revision)
+			case tc.previous == nil && cli.uninstalled != "authn":
+				t.Fatalf("uninstalled release = %q, want authn", cli.uninstalled)
+			case tc.previous != nil && tc.clientErr == nil && result.Revision != 5:
+				t.Fatalf("Revision = %d, want the one of the rollback release", result.Revision)
+			}
+		})
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
-	"github.com/krateoplatformops/krateoctl/internal/expand"
+	"github.com/krateoplatformops/krateoctl/internal/health"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
 	helmconfig "github.com/krateoplatformops/plumbing/helm"
+	chartcache "github.com/krateoplatformops/plumbing/helm/getter/cache"
 	helm "github.com/krateoplatformops/plumbing/helm/v3"
 
 	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 )
 
@@ -21,22 +26,29 @@ type ChartHandlerOptions struct {
 	Env    *cache.Cache[string, string]
 	Cfg    *rest.Config
 	Logger func(string, ...any)
+	// Render renders the chart templates into the step result instead of
+	// installing the release. The cluster is not contacted.
+	Render bool
+	// Atomic is the default of the atomic option of the chart steps.
+	Atomic bool
+	// Readiness evaluates the waitForReady option; it may be nil when no step needs it.
+	Readiness *health.Waiter
+	// Sensitive receives the values and credentials read from Secrets, so that they are masked in the logs and reports.
+	Sensitive *redact.Values
 }
 
 func ChartHandler(opts ChartHandlerOptions) steps.Handler[*steps.ChartResult] {
 	hdl := &chartStepHandler{
-		env:    opts.Env,
-		dyn:    opts.Dyn,
-		cfg:    opts.Cfg,
-		logger: opts.Logger,
-	}
-	hdl.subst = func(k string) string {
-		if v, ok := hdl.env.Get(k); ok {
-			return v
-		}
-
-		return "$" + k
+		env:       opts.Env,
+		dyn:       opts.Dyn,
+		cfg:       opts.Cfg,
+		render:    opts.Render,
+		atomic:    opts.Atomic,
+		ready:     opts.Readiness,
+		sensitive: opts.Sensitive,
+		logger:    opts.Logger,
 	}
+	hdl.vars = steps.EnvVars(hdl.env)
 
 	return hdl
 }
@@ -44,25 +56,18 @@ func ChartHandler(opts ChartHandlerOptions) steps.Handler[*steps.ChartResult] {
 var _ steps.Handler[*steps.ChartResult] = (*chartStepHandler)(nil)
 
 type chartStepHandler struct {
-	env    *cache.Cache[string, string]
-	ns     string
-	op     steps.Op
-	subst  func(k string) string
-	render bool
-	logger func(string, ...any)
-	dyn    *getter.Getter
-	cfg    *rest.Config
+	env       *cache.Cache[string, string]
+	vars      steps.Vars
+	render    bool
+	atomic    bool
+	ready     *health.Waiter
+	sensitive *redact.Values
+	logger    func(string, ...any)
+	dyn       *getter.Getter
+	cfg       *rest.Config
 }
 
-func (r *chartStepHandler) Namespace(ns string) {
-	r.ns = ns
-}
-
-func (r *chartStepHandler) Op(op steps.Op) {
-	r.op = op
-}
-
-func (r *chartStepHandler) Handle(ctx context.Context, id string, ext *map[string]any) (*steps.ChartResult, error) {
+func (r *chartStepHandler) Handle(ctx context.Context, id string, ext *map[string]any, opts steps.HandleOptions) (*steps.ChartResult, error) {
 
 	spec := &types.ChartSpec{}
 	data, err := json.Marshal(ext)
@@ -75,54 +80,95 @@ func (r *chartStepHandler) Handle(ctx context.Context, id string, ext *map[strin
 		return nil, fmt.Errorf("failed to unmarshal chart step input: %w", err)
 	}
 	spec.SetDefaults()
-	if expanded := r.expandValues(spec.Values); expanded != nil {
+	if expanded := r.vars.ExpandValues(spec.Values); expanded != nil {
 		if valuesMap, ok := expanded.(map[string]any); ok {
 			spec.Values = valuesMap
 		}
 	}
 
-	namespace := r.ns
+	namespace := opts.Namespace
 	if spec.Namespace != "" {
 		namespace = spec.Namespace
 	}
-	cli, err := helm.NewClient(r.cfg,
-		helm.WithNamespace(namespace),
-	)
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create helm client: %w", err)
+	clientOpts := []helm.ClientOption{
+		helm.WithNamespace(namespace),
 	}
 
-	result := &steps.ChartResult{}
-
-	if r.op != steps.Delete {
-		result.Operation = "install/upgrade"
+	var username, password string
+	if opts.Op != steps.Delete {
+		spec.Values, err = r.resolveValues(ctx, spec, namespace)
+		if err != nil {
+			return nil, err
+		}
 
-		var releaseName string
+		username, password, err = r.resolveCredentials(ctx, spec.Credentials, namespace)
+		if err != nil {
+			return nil, err
+		}
 
-		if spec.URL != "" {
-			releaseName = steps.DeriveReleaseName(spec.URL)
+		if spec.TLS != nil {
+			if err := spec.TLS.Validate(); err != nil {
+				return nil, err
+			}
 		}
-		if spec.Repo != "" {
-			releaseName = spec.Repo
+
+		if r.render {
+			return r.renderChart(ctx, id, spec, namespace, username, password)
 		}
-		if spec.ReleaseName != "" {
-			releaseName = spec.ReleaseName
+
+		if spec.TLS != nil {
+
+			dir, err := os.MkdirTemp("", "krateoctl-chart-")
+			if err != nil {
+				return nil, fmt.Errorf("failed to create chart cache directory: %w", err)
+			}
+			defer os.RemoveAll(dir)
+
+			if err := prefetchChart(spec, username, password, dir); err != nil {
+				return nil, err
+			}
+			clientOpts = append(clientOpts, helm.WithCache(chartcache.WithDir(dir)))
 		}
+	}
+
+	cli, err := helm.NewClient(r.cfg, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create helm client: %w", err)
+	}
+	defer cli.Close()
+
+	result := newResult(spec, namespace)
+	releaseName := result.ReleaseName
+
+	if opts.Op != steps.Delete {
+		result.Operation = "install/upgrade"
 
 		release, err := cli.GetRelease(ctx, releaseName, &helmconfig.GetConfig{})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get release: %w", err)
 		}
 
+		atomic := r.atomic
+		if spec.Atomic != nil {
+			atomic = *spec.Atomic
+		}
+
 		actionConfig := &helmconfig.ActionConfig{
 			ChartVersion:          spec.Version,
 			ChartName:             spec.Repo,
 			Values:                spec.Values,
-			Wait:                  spec.Wait,
+			Wait:                  spec.Wait || atomic,
 			InsecureSkipTLSverify: spec.InsecureSkipTLSVerify,
 			Timeout:               spec.Timeout.Duration,
+			Username:              username,
+			Password:              password,
 		}
+		if opts.DryRun {
+			return result, r.dryRun(ctx, cli, id, result, release, spec, actionConfig)
+		}
+
+		var previous *helmconfig.Release
 		if release == nil {
 			release, err = cli.Install(ctx,
 				releaseName,
@@ -131,10 +177,17 @@ func (r *chartStepHandler) Handle(ctx context.Context, id string, ext *map[strin
 					ActionConfig: actionConfig,
 				})
 			if err != nil {
-				return result, fmt.Errorf("failed to install chart: %w", err)
+				err = fmt.Errorf("failed to install chart: %w", err)
+				switch {
+				case ctx.Err() != nil:
+					err = r.settle(ctx, cli, id, result, nil, err)
+				case atomic:
+					err = r.revert(ctx, cli, id, result, nil, spec, err)
+				}
+				return result, err
 			}
 		} else {
-			if release.Status == helmconfig.StatusPendingInstall || release.Status == helmconfig.StatusPendingUpgrade || release.Status == helmconfig.StatusPendingRollback {
+			if pending(release.Status) {
 				release, err = cli.Rollback(ctx, releaseName, &helmconfig.RollbackConfig{
 					ReleaseVersion: release.Revision,
 				})
@@ -142,6 +195,7 @@ func (r *chartStepHandler) Handle(ctx context.Context, id string, ext *map[strin
 					return result, fmt.Errorf("failed to rollback release %s: %w", releaseName, err)
 				}
 			}
+			previous = release
 			release, err = cli.Upgrade(ctx,
 				releaseName,
 				spec.URL,
@@ -150,20 +204,42 @@ func (r *chartStepHandler) Handle(ctx context.Context, id string, ext *map[strin
 					MaxHistory:   *spec.MaxHistory,
 				})
 			if err != nil {
-				return result, fmt.Errorf("failed to upgrade chart: %w", err)
+				err = fmt.Errorf("failed to upgrade chart: %w", err)
+				switch {
+				case ctx.Err() != nil:
+					err = r.settle(ctx, cli, id, result, previous, err)
+				case atomic:
+					err = r.revert(ctx, cli, id, result, previous, spec, err)
+				}
+				return result, err
 			}
 		}
+		fillResult(result, release)
+
+		if spec.WaitForReady {
+			if err := r.waitForRelease(ctx, id, release, spec); err != nil {
+				err = fmt.Errorf("release %s is not ready: %w", releaseName, err)
+				if atomic {
+					err = r.revert(ctx, cli, id, result, previous, spec, err)
+				}
+				return result, err
+			}
+			result.Ready = true
+		}
 
 		r.logger(fmt.Sprintf(
-			"[chart:%s]: %s operation completed for release %s",
-			id, result.Operation, result.ReleaseName))
+			"[chart:%s]: %s operation completed for release %s (revision %d, %s)",
+			id, result.Operation, result.ReleaseName, result.Revision, result.Status))
 
 		return result, nil
 	}
 
 	result.Operation = "uninstall"
+	if opts.DryRun {
+		return result, r.dryRunUninstall(ctx, cli, id, result)
+	}
 
-	err = cli.Uninstall(ctx, spec.ReleaseName, &helmconfig.UninstallConfig{
+	err = cli.Uninstall(ctx, releaseName, &helmconfig.UninstallConfig{
 		IgnoreNotFound: true,
 	})
 	if err != nil {
@@ -171,6 +247,7 @@ func (r *chartStepHandler) Handle(ctx context.Context, id string, ext *map[strin
 	}
 
 	result.Status = "uninstalled"
+	result.Updated = metav1.Now()
 
 	r.logger(fmt.Sprintf(
 		"[chart:%s]: uninstall operation completed for release %s",
@@ -179,22 +256,70 @@ func (r *chartStepHandler) Handle(ctx context.Context, id string, ext *map[strin
 	return result, nil
 }
 
-// expandValues walks Helm values and resolves ${VAR} placeholders via the shared cache.
-func (r *chartStepHandler) expandValues(val any) any {
-	switch v := val.(type) {
-	case map[string]any:
-		for key, elem := range v {
-			v[key] = r.expandValues(elem)
-		}
-		return v
-	case []any:
-		for i, elem := range v {
-			v[i] = r.expandValues(elem)
-		}
-		return v
-	case string:
-		return expand.Expand(v, "", r.subst)
-	default:
-		return val
+func newResult(spec *types.ChartSpec, namespace string) *steps.ChartResult {
+	releaseName := spec.ReleaseName
+	if releaseName == "" {
+		releaseName = releaseNameOf(spec)
+	}
+
+	return &steps.ChartResult{
+		ReleaseName:  releaseName,
+		ChartName:    chartNameOf(spec),
+		ChartVersion: spec.Version,
+		Namespace:    namespace,
+	}
+}
+
+// waitForRelease waits until every object in the release manifest is ready.
+func (r *chartStepHandler) waitForRelease(ctx context.Context, id string, release *helmconfig.Release, spec *types.ChartSpec) error {
+	if r.ready == nil {
+		return fmt.Errorf("waitForReady requires a cluster connection")
+	}
+	if release == nil {
+		return nil
+	}
+
+	objs, err := dynamic.ParseManifests([]byte(release.Manifest), "release "+release.Name)
+	if err != nil {
+		return err
+	}
+
+	r.logger(fmt.Sprintf("[chart:%s]: waiting for %d object(s) of release %s to become ready", id, len(objs), release.Name))
+	return r.ready.WaitForReady(ctx, id, objs, release.Namespace, spec.Readiness.Timeout())
+}
+
+// releaseNameOf derives the release name from the chart reference when it is not set.
+func releaseNameOf(spec *types.ChartSpec) string {
+	if spec.Repo != "" {
+		return spec.Repo
+	}
+	if spec.URL != "" {
+		return steps.DeriveReleaseName(spec.URL)
+	}
+	return ""
+}
+
+// chartNameOf returns the chart name, either the repository chart or the one
+// found in the chart URL.
+func chartNameOf(spec *types.ChartSpec) string {
+	if spec.Repo != "" {
+		return spec.Repo
+	}
+	return steps.DeriveReleaseName(spec.URL)
+}
+
+// fillResult copies the release returned by Helm into the step result.
+func fillResult(result *steps.ChartResult, release *helmconfig.Release) {
+	result.Updated = metav1.Now()
+	if release == nil {
+		return
+	}
+
+	result.ReleaseName = release.Name
+	result.Namespace = release.Namespace
+	result.Revision = release.Revision
+	result.Status = string(release.Status)
+	if release.ChartVersion != "" {
+		result.ChartVersion = release.ChartVersion
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
 )
 
 func TestChartHandlerExpandValues(t *testing.T) {
@@ -13,12 +14,7 @@ func TestChartHandlerExpandValues(t *testing.T) {
 	env.Set("SNOWPLOW_PORT", "30081")
 
 	handler := &chartStepHandler{env: env}
-	handler.subst = func(k string) string {
-		if v, ok := handler.env.Get(k); ok {
-			return v
-		}
-		return "$" + k
-	}
+	handler.vars = steps.EnvVars(handler.env)
 
 	input := map[string]any{
 		"config": map[string]any{
@@ -34,7 +30,7 @@ func TestChartHandlerExpandValues(t *testing.T) {
 		"unchanged": 42,
 	}
 
-	got := handler.expandValues(input).(map[string]any)
+	got := handler.vars.ExpandValues(input).(map[string]any)
 
 	cfg := got["config"].(map[string]any)
 	if cfg["AUTHN"] != "http://127.0.0.1:30082" {
@@ -81,9 +81,6 @@ func TestChartStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create chart handler: %v", err)
 			}
 
-			handler.Namespace(chartNamespace)
-			handler.Op(steps.Create)
-
 			// Use a simple chart from a URL (this requires internet connectivity)
 			chartJSON := `{
                 "name": "test-nginx",
@@ -103,7 +100,7 @@ func TestChartStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal chart JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-install-chart", &ext)
+			result, err := handler.Handle(ctx, "test-install-chart", &ext, steps.HandleOptions{Namespace: chartNamespace, Op: steps.Create})
 
 			// Note: This test might fail in environments without internet access
 			if err != nil {
@@ -134,9 +131,6 @@ func TestChartStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create chart handler: %v", err)
 			}
 
-			handler.Namespace(chartNamespace)
-			handler.Op(steps.Create)
-
 			chartJSON := `{
                 "name": "test-nginx-vars",
                 "url": "https://raw.githubusercontent.com/helm/examples/main/charts/hello-world/hello-world-0.1.0.tgz",
@@ -163,7 +157,7 @@ func TestChartStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal chart JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-install-chart-vars", &ext)
+			result, err := handler.Handle(ctx, "test-install-chart-vars", &ext, steps.HandleOptions{Namespace: chartNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Logf("Chart installation with vars failed (expected in some test environments): %v", err)
@@ -180,9 +174,6 @@ func TestChartStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create chart handler: %v", err)
 			}
 
-			handler.Namespace(chartNamespace)
-			handler.Op(steps.Create)
-
 			// Create a chart handler with render mode enabled
 			chartHandler := handler
 			chartHandler.render = true
@@ -205,7 +196,7 @@ func TestChartStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal chart JSON: %v", err)
 			}
-			result, err := chartHandler.Handle(ctx, "test-template-chart", &ext)
+			result, err := chartHandler.Handle(ctx, "test-template-chart", &ext, steps.HandleOptions{Namespace: chartNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Logf("Chart templating failed (expected in some test environments): %v", err)
@@ -222,9 +213,6 @@ func TestChartStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create chart handler: %v", err)
 			}
 
-			handler.Namespace(chartNamespace)
-			handler.Op(steps.Create)
-
 			// Use a well-known chart repository
 			chartJSON := `{
                 "name": "test-nginx-repo",
@@ -250,7 +238,7 @@ func TestChartStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal chart JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-install-repo-chart", &ext)
+			result, err := handler.Handle(ctx, "test-install-repo-chart", &ext, steps.HandleOptions{Namespace: chartNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Logf("Chart installation from repository failed (expected in some test environments): %v", err)
@@ -271,9 +259,6 @@ func TestChartStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create chart handler: %v", err)
 			}
 
-			handler.Namespace(chartNamespace)
-			handler.Op(steps.Update)
-
 			chartJSON := `{
                 "name": "test-nginx",
                 "url": "https://raw.githubusercontent.com/helm/examples/main/charts/hello-world/hello-world-0.1.0.tgz",
@@ -296,7 +281,7 @@ func TestChartStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal chart JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-upgrade-chart", &ext)
+			result, err := handler.Handle(ctx, "test-upgrade-chart", &ext, steps.HandleOptions{Namespace: chartNamespace, Op: steps.Update})
 
 			if err != nil {
 				t.Logf("Chart upgrade failed (expected if chart wasn't installed): %v", err)
@@ -317,9 +302,6 @@ func TestChartStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create chart handler: %v", err)
 			}
 
-			handler.Namespace(chartNamespace)
-			handler.Op(steps.Delete)
-
 			chartJSON := `{
                 "name": "test-nginx",
                 "url": "https://raw.githubusercontent.com/helm/examples/main/charts/hello-world/hello-world-0.1.0.tgz",
@@ -331,7 +313,7 @@ func TestChartStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal chart JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-uninstall-chart", &ext)
+			result, err := handler.Handle(ctx, "test-uninstall-chart", &ext, steps.HandleOptions{Namespace: chartNamespace, Op: steps.Delete})
 
 			if err != nil {
 				t.Logf("Chart uninstall failed (expected if chart wasn't installed): %v", err)
@@ -372,9 +354,6 @@ func TestChartStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create chart handler: %v", err)
 			}
 
-			handler.Namespace(chartNamespace)
-			handler.Op(steps.Create)
-
 			chartJSON := `{
                 "name": "test-nginx-creds",
                 "url": "https://raw.githubusercontent.com/helm/examples/main/charts/hello-world/hello-world-0.1.0.tgz",
@@ -401,7 +380,7 @@ func TestChartStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal chart JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-install-chart-creds", &ext)
+			result, err := handler.Handle(ctx, "test-install-chart-creds", &ext, steps.HandleOptions{Namespace: chartNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Logf("Chart installation with credentials failed (expected in some test environments): %v", err)
@@ -0,0 +1,85 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	helmconfig "github.com/krateoplatformops/plumbing/helm"
+)
+
+// dryRun installs or upgrades the release in Helm server dry-run mode: the
+// templates are rendered with lookups against the cluster and validated
+// against its schemas, but no release revision nor object is stored. The
+// change is derived by comparing the rendered manifest with the current one.
+func (r *chartStepHandler) dryRun(ctx context.Context, cli helmconfig.Client, id string, result *steps.ChartResult, current *helmconfig.Release, spec *types.ChartSpec, cfg *helmconfig.ActionConfig) error {
+	cfg.DryRun = helmconfig.DryRunServer
+	cfg.Wait = false
+
+	var (
+		release *helmconfig.Release
+		err     error
+	)
+	if current == nil {
+		release, err = cli.Install(ctx, result.ReleaseName, spec.URL, &helmconfig.InstallConfig{
+			ActionConfig: cfg,
+		})
+	} else {
+		release, err = cli.Upgrade(ctx, result.ReleaseName, spec.URL, &helmconfig.UpgradeConfig{
+			ActionConfig: cfg,
+			MaxHistory:   *spec.MaxHistory,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("dry run of chart failed: %w", err)
+	}
+	fillResult(result, release)
+
+	switch {
+	case current == nil:
+		result.Change = string(applier.ChangeCreate)
+	case release != nil && release.Manifest == current.Manifest:
+		result.Change = string(applier.ChangeUnchanged)
+	default:
+		result.Change = string(applier.ChangeUpdate)
+	}
+
+	r.logger(fmt.Sprintf("[chart:%s]: release %s would be %s (dry run)", id, result.ReleaseName, changeVerb(result.Change)))
+	return nil
+}
+
+// dryRunUninstall checks the uninstall of the release without removing it. A
+// missing release reports no change.
+func (r *chartStepHandler) dryRunUninstall(ctx context.Context, cli helmconfig.Client, id string, result *steps.ChartResult) error {
+	release, err := cli.GetRelease(ctx, result.ReleaseName, &helmconfig.GetConfig{})
+	if err != nil {
+		return fmt.Errorf("failed to get release: %w", err)
+	}
+	if release == nil {
+		return nil
+	}
+
+	err = cli.Uninstall(ctx, result.ReleaseName, &helmconfig.UninstallConfig{
+		IgnoreNotFound: true,
+		DryRun:         true,
+	})
+	if err != nil {
+		return fmt.Errorf("dry run of uninstall failed: %w", err)
+	}
+
+	result.Change = steps.ChangeDelete
+	r.logger(fmt.Sprintf("[chart:%s]: release %s would be uninstalled (dry run)", id, result.ReleaseName))
+	return nil
+}
+
+func changeVerb(change string) string {
+	switch applier.Change(change) {
+	case applier.ChangeCreate:
+		return "installed"
+	case applier.ChangeUpdate:
+		return "upgraded"
+	}
+	return "left unchanged"
+}
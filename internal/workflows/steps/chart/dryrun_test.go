@@ -0,0 +1,121 @@
+package steps
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	helmconfig "github.com/krateoplatformops/plumbing/helm"
+)
+
+type fakeDryRunClient struct {
+	helmconfig.Client
+	manifest string
+	current  *helmconfig.Release
+	dryRuns  []string
+}
+
+func (f *fakeDryRunClient) Install(_ context.Context, name, _ string, cfg *helmconfig.InstallConfig) (*helmconfig.Release, error) {
+	f.record("install", cfg.DryRun == helmconfig.DryRunServer)
+	return &helmconfig.Release{Name: name, Namespace: "krateo-system", Revision: 1, Manifest: f.manifest}, nil
+}
+
+func (f *fakeDryRunClient) Upgrade(_ context.Context, name, _ string, cfg *helmconfig.UpgradeConfig) (*helmconfig.Release, error) {
+	f.record("upgrade", cfg.DryRun == helmconfig.DryRunServer)
+	return &helmconfig.Release{Name: name, Namespace: "krateo-system", Revision: 4, Manifest: f.manifest}, nil
+}
+
+func (f *fakeDryRunClient) GetRelease(context.Context, string, *helmconfig.GetConfig) (*helmconfig.Release, error) {
+	return f.current, nil
+}
+
+func (f *fakeDryRunClient) Uninstall(_ context.Context, _ string, cfg *helmconfig.UninstallConfig) error {
+	f.record("uninstall", cfg.DryRun)
+	return nil
+}
+
+func (f *fakeDryRunClient) record(op string, dryRun bool) {
+	if !dryRun {
+		op += " (persisted)"
+	}
+	f.dryRuns = append(f.dryRuns, op)
+}
+
+func TestChartHandlerDryRun(t *testing.T) {
+	deployed := &helmconfig.Release{Name: "authn", Revision: 3, Manifest: "kind: Deployment\n"}
+
+	tests := []struct {
+		name       string
+		current    *helmconfig.Release
+		manifest   string
+		op         steps.Op
+		wantCalls  []string
+		wantChange string
+	}{
+		{
+			name:       "new release is installed",
+			manifest:   "kind: Deployment\n",
+			wantCalls:  []string{"install"},
+			wantChange: "create",
+		},
+		{
+			name:       "same manifest is unchanged",
+			current:    deployed,
+			manifest:   "kind: Deployment\n",
+			wantCalls:  []string{"upgrade"},
+			wantChange: "unchanged",
+		},
+		{
+			name:       "different manifest is upgraded",
+			current:    deployed,
+			manifest:   "kind: StatefulSet\n",
+			wantCalls:  []string{"upgrade"},
+			wantChange: "update",
+		},
+		{
+			name:       "release is uninstalled",
+			current:    deployed,
+			op:         steps.Delete,
+			wantCalls:  []string{"uninstall"},
+			wantChange: steps.ChangeDelete,
+		},
+		{
+			name: "missing release is not uninstalled",
+			op:   steps.Delete,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &types.ChartSpec{URL: "https://charts.krateo.io/authn-0.20.1.tgz", Wait: true}
+			spec.SetDefaults()
+
+			cli := &fakeDryRunClient{manifest: tc.manifest, current: tc.current}
+			handler := &chartStepHandler{logger: func(string, ...any) {}}
+			result := newResult(spec, "krateo-system")
+
+			var err error
+			if tc.op == steps.Delete {
+				err = handler.dryRunUninstall(context.Background(), cli, "authn", result)
+			} else {
+				cfg := &helmconfig.ActionConfig{Wait: spec.Wait}
+				err = handler.dryRun(context.Background(), cli, "authn", result, tc.current, spec, cfg)
+				if cfg.Wait {
+					t.Fatalf("dryRun() kept waiting for the release")
+				}
+			}
+			if err != nil {
+				t.Fatalf("dry run error = %v", err)
+			}
+
+			if !slices.Equal(cli.dryRuns, tc.wantCalls) {
+				t.Fatalf("helm calls = %v, want %v", cli.dryRuns, tc.wantCalls)
+			}
+			if result.Change != tc.wantChange {
+				t.Fatalf("Change = %q, want %q", result.Change, tc.wantChange)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	helmconfig "github.com/krateoplatformops/plumbing/helm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// settleTimeout bounds the cleanup of a release whose install or upgrade was interrupted.
+const settleTimeout = time.Minute
+
+// settle makes sure that the install or upgrade cancelled with ctx, on interrupt
+// or at a timeout, does not leave the release pending: Helm refuses any further
+// operation on it. A pending release goes back to the previous revision, or is
+// uninstalled when there is none, without waiting for its objects. The cleanup
+// runs on a context detached from the cancelled one. The returned error always
+// wraps cause, since the step still failed.
+func (r *chartStepHandler) settle(ctx context.Context, cli helmconfig.Client, id string, result *steps.ChartResult, previous *helmconfig.Release, cause error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+	defer cancel()
+
+	release, err := cli.GetRelease(ctx, result.ReleaseName, &helmconfig.GetConfig{})
+	if err != nil {
+		return fmt.Errorf("%w (status of release %s unknown: %v)", cause, result.ReleaseName, err)
+	}
+	if release == nil || !pending(release.Status) {
+		return cause
+	}
+
+	if previous == nil {
+		result.Operation = OperationRollbackUninstall
+		err := cli.Uninstall(ctx, result.ReleaseName, &helmconfig.UninstallConfig{
+			IgnoreNotFound: true,
+			Timeout:        settleTimeout,
+		})
+		if err != nil {
+			return fmt.Errorf("%w (release %s left %s, uninstall failed: %v)", cause, result.ReleaseName, release.Status, err)
+		}
+
+		result.Status = "uninstalled"
+		result.Updated = metav1.Now()
+		r.logger(fmt.Sprintf("[chart:%s]: install interrupted, release %s uninstalled", id, result.ReleaseName))
+		return fmt.Errorf("%w (release %s uninstalled)", cause, result.ReleaseName)
+	}
+
+	result.Operation = OperationRollback
+	release, err = cli.Rollback(ctx, result.ReleaseName, &helmconfig.RollbackConfig{
+		ReleaseVersion: previous.Revision,
+		Timeout:        settleTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("%w (release %s left pending, rollback to revision %d failed: %v)", cause, result.ReleaseName, previous.Revision, err)
+	}
+
+	fillResult(result, release)
+	r.logger(fmt.Sprintf("[chart:%s]: upgrade interrupted, release %s rolled back to revision %d",
+		id, result.ReleaseName, previous.Revision))
+	return fmt.Errorf("%w (rolled back to revision %d)", cause, previous.Revision)
+}
+
+func pending(status helmconfig.Status) bool {
+	return status == helmconfig.StatusPendingInstall || status == helmconfig.StatusPendingUpgrade || status == helmconfig.StatusPendingRollback
+}
@@ -0,0 +1,81 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	helmconfig "github.com/krateoplatformops/plumbing/helm"
+)
+
+func TestChartHandlerSettle(t *testing.T) {
+	cause := errors.New("failed to upgrade chart: context canceled")
+
+	tests := []struct {
+		name          string
+		current       *helmconfig.Release
+		previous      *helmconfig.Release
+		wantOperation string
+		wantErr       string
+		wantRollback  bool
+		wantUninstall bool
+	}{
+		{
+			name:          "pending upgrade is rolled back",
+			current:       &helmconfig.Release{Name: "authn", Revision: 4, Status: helmconfig.StatusPendingUpgrade},
+			previous:      &helmconfig.Release{Name: "authn", Revision: 3},
+			wantOperation: OperationRollback,
+			wantErr:       "rolled back to revision 3",
+			wantRollback:  true,
+		},
+		{
+			name:          "pending install is uninstalled",
+			current:       &helmconfig.Release{Name: "authn", Revision: 1, Status: helmconfig.StatusPendingInstall},
+			wantOperation: OperationRollbackUninstall,
+			wantErr:       "release authn uninstalled",
+			wantUninstall: true,
+		},
+		{
+			name:          "failed release is left as is",
+			current:       &helmconfig.Release{Name: "authn", Revision: 4, Status: helmconfig.StatusFailed},
+			previous:      &helmconfig.Release{Name: "authn", Revision: 3},
+			wantOperation: "install/upgrade",
+			wantErr:       cause.Error(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &types.ChartSpec{URL: "https://charts.krateo.io/authn-0.20.1.tgz"}
+			spec.SetDefaults()
+
+			cli := &fakeHelmClient{current: tc.current}
+			handler := &chartStepHandler{logger: func(string, ...any) {}}
+			result := newResult(spec, "krateo-system")
+			result.Operation = "install/upgrade"
+
+			// The cleanup must not depend on the cancelled context of the step.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := handler.settle(ctx, cli, "authn", result, tc.previous, cause)
+			if !errors.Is(err, cause) {
+				t.Fatalf("settle() error = %v, want it to wrap the original failure", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("settle() error = %q, want it to contain %q", err, tc.wantErr)
+			}
+			if result.Operation != tc.wantOperation {
+				t.Fatalf("Operation = %q, want %q", result.Operation, tc.wantOperation)
+			}
+			if rolledBack := cli.rollback != nil; rolledBack != tc.wantRollback {
+				t.Fatalf("rolled back = %v, want %v", rolledBack, tc.wantRollback)
+			}
+			if uninstalled := cli.uninstalled != ""; uninstalled != tc.wantUninstall {
+				t.Fatalf("uninstalled = %v, want %v", uninstalled, tc.wantUninstall)
+			}
+		})
+	}
+}
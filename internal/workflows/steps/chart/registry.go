@@ -0,0 +1,194 @@
+package steps
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/krateoplatformops/krateoctl/internal/resolvers"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	chartcache "github.com/krateoplatformops/plumbing/helm/getter/cache"
+	"helm.sh/helm/v3/pkg/cli"
+	helmgetter "helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// resolveCredentials returns the username and the password of the chart credentials,
+// reading the password from the referenced Secret. The Secret namespace defaults to
+// the release namespace.
+func (r *chartStepHandler) resolveCredentials(ctx context.Context, creds *types.Credentials, namespace string) (string, string, error) {
+	if creds == nil {
+		return "", "", nil
+	}
+	if r.dyn == nil {
+		return "", "", fmt.Errorf("chart credentials require a cluster connection")
+	}
+
+	sel := creds.PasswordRef
+	sel.Name = r.vars.Expand(sel.Name)
+	sel.Namespace = r.vars.Expand(sel.Namespace)
+	if sel.Namespace == "" {
+		sel.Namespace = namespace
+	}
+
+	password, err := resolvers.GetSecret(ctx, *r.dyn, sel)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read chart credentials from secret %s/%s: %w", sel.Namespace, sel.Name, err)
+	}
+	if r.sensitive != nil {
+		r.sensitive.Add(password)
+	}
+
+	return r.vars.Expand(creds.Username), password, nil
+}
+
+// prefetchChart downloads the chart honouring the TLS settings of the step, which the
+// Helm client does not support, and stores it in a chart cache rooted at dir under the
+// key the Helm client looks up (chart URL and version). A Helm client created with that
+// cache then loads the chart from disk instead of reaching the repository.
+func prefetchChart(spec *types.ChartSpec, username, password, dir string) error {
+	data, err := downloadChart(spec, username, password)
+	if err != nil {
+		return err
+	}
+
+	c, err := chartcache.NewDiskCache(chartcache.WithDir(dir))
+	if err != nil {
+		return fmt.Errorf("failed to create chart cache: %w", err)
+	}
+	defer c.Stop()
+
+	return c.Set(spec.URL, spec.Version, data)
+}
+
+func downloadChart(spec *types.ChartSpec, username, password string) (*bytes.Buffer, error) {
+	if registry.IsOCI(spec.URL) {
+		return pullOCIChart(spec, username, password)
+	}
+
+	tlsFiles := spec.TLS
+	if tlsFiles == nil {
+		tlsFiles = &types.ChartTLS{}
+	}
+
+	ref := spec.URL
+	if spec.Repo != "" {
+		var err error
+		ref, err = repo.FindChartInAuthAndTLSAndPassRepoURL(spec.URL, username, password,
+			spec.Repo, spec.Version, tlsFiles.CertFile, tlsFiles.KeyFile, tlsFiles.CAFile,
+			spec.InsecureSkipTLSVerify, false, helmgetter.All(cli.New()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find chart %s in repository %s: %w", spec.Repo, spec.URL, err)
+		}
+	}
+
+	g, err := helmgetter.NewHTTPGetter()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := g.Get(ref,
+		helmgetter.WithURL(ref),
+		helmgetter.WithBasicAuth(username, password),
+		helmgetter.WithTLSClientConfig(tlsFiles.CertFile, tlsFiles.KeyFile, tlsFiles.CAFile),
+		helmgetter.WithInsecureSkipVerifyTLS(spec.InsecureSkipTLSVerify),
+		helmgetter.WithAcceptHeader("application/gzip,application/octet-stream"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download chart %s: %w", ref, err)
+	}
+	return data, nil
+}
+
+// pullOCIChart logs in to the registry and pulls the chart. The latest semver tag
+// is used when the version is not set.
+func pullOCIChart(spec *types.ChartSpec, username, password string) (*bytes.Buffer, error) {
+	tlsCfg, err := tlsConfig(spec.TLS, spec.InsecureSkipTLSVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []registry.ClientOption{
+		registry.ClientOptHTTPClient(&http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsCfg,
+				Proxy:           http.ProxyFromEnvironment,
+			},
+		}),
+	}
+	if username != "" {
+		opts = append(opts, registry.ClientOptBasicAuth(username, password))
+	}
+	client, err := registry.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	ref := ociReference(spec.URL, spec.Repo)
+	tag := spec.Version
+	if tag == "" {
+		tags, err := client.Tags(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %w", ref, err)
+		}
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("no semver tags found for %s", ref)
+		}
+		tag = tags[0]
+	}
+
+	res, err := client.Pull(ref + ":" + tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull chart %s:%s: %w", ref, tag, err)
+	}
+	return bytes.NewBuffer(res.Chart.Data), nil
+}
+
+// ociReference returns the repository reference of an oci:// chart URL, without
+// scheme, appending the chart name when it is set apart.
+func ociReference(url, chart string) string {
+	ref := strings.TrimSuffix(strings.TrimPrefix(url, registry.OCIScheme+"://"), "/")
+	if chart != "" {
+		ref = ref + "/" + chart
+	}
+	return ref
+}
+
+// tlsConfig builds the client TLS configuration from the PEM files of the step.
+func tlsConfig(t *types.ChartTLS, insecure bool) (*tls.Config, error) {
+	cfg := &tls.Config{InsecureSkipVerify: insecure}
+	if t == nil {
+		return cfg, nil
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", t.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,100 @@
+package steps
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	chartcache "github.com/krateoplatformops/plumbing/helm/getter/cache"
+)
+
+func TestPrefetchChartWithCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "robot" || pass != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("chart-archive"))
+	}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+
+	spec := &types.ChartSpec{
+		URL:     srv.URL + "/charts/authn-0.20.1.tgz",
+		Version: "0.20.1",
+		TLS:     &types.ChartTLS{CAFile: caFile},
+	}
+
+	dir := t.TempDir()
+	if err := prefetchChart(spec, "robot", "s3cr3t", dir); err != nil {
+		t.Fatalf("prefetchChart() error = %v", err)
+	}
+
+	c, err := chartcache.NewDiskCache(chartcache.WithDir(dir))
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	defer c.Stop()
+
+	rc, ok := c.Get(spec.URL, spec.Version)
+	if !ok {
+		t.Fatalf("chart not found in cache")
+	}
+	defer rc.Close()
+
+	data, _ := io.ReadAll(rc)
+	if string(data) != "chart-archive" {
+		t.Fatalf("cached chart = %q, want %q", data, "chart-archive")
+	}
+}
+
+func TestPrefetchChartUnknownAuthority(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("chart-archive"))
+	}))
+	defer srv.Close()
+
+	spec := &types.ChartSpec{
+		URL: srv.URL + "/charts/authn-0.20.1.tgz",
+		TLS: &types.ChartTLS{},
+	}
+	if err := prefetchChart(spec, "", "", t.TempDir()); err == nil {
+		t.Fatalf("prefetchChart() expected a certificate error")
+	}
+}
+
+func TestOCIReference(t *testing.T) {
+	tests := []struct {
+		url   string
+		chart string
+		want  string
+	}{
+		{url: "oci://harbor.example.com/krateo/authn", want: "harbor.example.com/krateo/authn"},
+		{url: "oci://harbor.example.com/krateo/", chart: "authn", want: "harbor.example.com/krateo/authn"},
+	}
+
+	for _, tc := range tests {
+		if got := ociReference(tc.url, tc.chart); got != tc.want {
+			t.Fatalf("ociReference(%q, %q) = %q, want %q", tc.url, tc.chart, got, tc.want)
+		}
+	}
+}
+
+func TestTLSConfigRequiresKeyPair(t *testing.T) {
+	if err := (&types.ChartTLS{CertFile: "client.pem"}).Validate(); err == nil {
+		t.Fatalf("Validate() expected an error when keyFile is missing")
+	}
+	if _, err := tlsConfig(&types.ChartTLS{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, false); err == nil {
+		t.Fatalf("tlsConfig() expected an error for a missing CA bundle")
+	}
+}
@@ -0,0 +1,66 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// renderChart downloads the chart and renders its templates, hooks and CRDs
+// included, the same way `helm template` does.
+func (r *chartStepHandler) renderChart(ctx context.Context, id string, spec *types.ChartSpec, namespace, username, password string) (*steps.ChartResult, error) {
+	data, err := downloadChart(spec, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	chrt, err := loader.LoadArchive(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart: %w", err)
+	}
+
+	result := newResult(spec, namespace)
+	result.Operation = "template"
+
+	install := action.NewInstall(&action.Configuration{
+		Log: func(string, ...any) {},
+	})
+	install.DryRun = true
+	install.DryRunOption = "client"
+	install.ClientOnly = true
+	install.Replace = true
+	install.IncludeCRDs = true
+	install.ReleaseName = result.ReleaseName
+	install.Namespace = namespace
+
+	release, err := install.RunWithContext(ctx, chrt, spec.Values)
+	if err != nil {
+		return result, fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(release.Manifest)
+	for _, hook := range release.Hooks {
+		fmt.Fprintf(&sb, "\n---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+	}
+
+	result.Manifest = sb.String()
+	result.Status = "rendered"
+	result.Updated = metav1.Now()
+	if release.Chart != nil && release.Chart.Metadata != nil {
+		result.ChartName = release.Chart.Metadata.Name
+		result.ChartVersion = release.Chart.Metadata.Version
+		result.AppVersion = release.Chart.Metadata.AppVersion
+	}
+
+	r.logger(fmt.Sprintf("[chart:%s]: rendered chart %s %s as release %s",
+		id, result.ChartName, result.ChartVersion, result.ReleaseName))
+
+	return result, nil
+}
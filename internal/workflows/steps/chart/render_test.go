@@ -0,0 +1,63 @@
+package steps
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestChartHandlerRender(t *testing.T) {
+	archive, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "hello", Version: "0.1.0", AppVersion: "1.0"},
+		Values:   map[string]any{"replicas": 1},
+		Templates: []*chart.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}\n  namespace: {{ .Release.Namespace }}\ndata:\n  replicas: {{ .Values.replicas | quote }}\n"),
+		}},
+	}, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to package chart: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, archive)
+	}))
+	defer srv.Close()
+
+	env := cache.New[string, string]()
+	env.Set("REPLICAS", "3")
+
+	handler := ChartHandler(ChartHandlerOptions{
+		Env:    env,
+		Logger: func(string, ...any) {},
+		Render: true,
+	})
+
+	ext := map[string]any{
+		"url":         srv.URL + "/hello-0.1.0.tgz",
+		"version":     "0.1.0",
+		"releaseName": "greeter",
+		"values":      map[string]any{"replicas": "${REPLICAS}"},
+	}
+
+	res, err := handler.Handle(context.Background(), "hello", &ext, steps.HandleOptions{Namespace: "krateo-system", Op: steps.Create})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	if res.Operation != "template" || res.ReleaseName != "greeter" || res.ChartName != "hello" || res.AppVersion != "1.0" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	for _, want := range []string{"name: greeter", "namespace: krateo-system", `replicas: "3"`} {
+		if !strings.Contains(res.Manifest, want) {
+			t.Fatalf("rendered manifest misses %q:\n%s", want, res.Manifest)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/krateoplatformops/krateoctl/internal/resolvers"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	"sigs.k8s.io/yaml"
+)
+
+// resolveValues merges the valuesFrom sources, in declared order, and then the
+// inline values on top of them. The sources are read at apply time only and their
+// content is never expanded, since it may hold secret material.
+func (r *chartStepHandler) resolveValues(ctx context.Context, spec *types.ChartSpec, namespace string) (map[string]any, error) {
+	if len(spec.ValuesFrom) == 0 {
+		return spec.Values, nil
+	}
+
+	out := map[string]any{}
+	for i, src := range spec.ValuesFrom {
+		if err := src.Validate(); err != nil {
+			return nil, fmt.Errorf("valuesFrom[%d]: %w", i, err)
+		}
+
+		content, err := r.readValuesSource(ctx, src, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("valuesFrom[%d]: %w", i, err)
+		}
+		if src.SecretKeyRef != nil {
+			r.taint(content, src.TargetPath == "")
+		}
+
+		if src.TargetPath != "" {
+			setValue(out, strings.Split(src.TargetPath, "."), content)
+			continue
+		}
+
+		doc := map[string]any{}
+		if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+			return nil, fmt.Errorf("valuesFrom[%d]: failed to parse values: %w", i, err)
+		}
+		mergeValues(out, doc)
+	}
+	mergeValues(out, spec.Values)
+
+	return out, nil
+}
+
+func (r *chartStepHandler) readValuesSource(ctx context.Context, src types.ValuesFromSource, namespace string) (string, error) {
+	if src.File != "" {
+		path := r.vars.Expand(src.File)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read values file: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if r.dyn == nil {
+		return "", fmt.Errorf("configMapKeyRef and secretKeyRef require a cluster connection")
+	}
+
+	if src.ConfigMapKeyRef != nil {
+		sel := *src.ConfigMapKeyRef
+		sel.Name = r.vars.Expand(sel.Name)
+		sel.Namespace = r.vars.Expand(sel.Namespace)
+		if sel.Namespace == "" {
+			sel.Namespace = namespace
+		}
+		return resolvers.GetConfigMapValue(ctx, *r.dyn, sel)
+	}
+
+	sel := *src.SecretKeyRef
+	sel.Name = r.vars.Expand(sel.Name)
+	sel.Namespace = r.vars.Expand(sel.Namespace)
+	if sel.Namespace == "" {
+		sel.Namespace = namespace
+	}
+	return resolvers.GetSecret(ctx, *r.dyn, sel)
+}
+
+// minTaintedLength is the length below which the strings of a values document
+// read from a Secret are not masked: short strings such as "true" or "oidc"
+// would mask every log line using the same word.
+const minTaintedLength = 8
+
+// taint adds the content read from a Secret to the sensitive values. For a
+// values document, set at no target path, the strings it holds are added too,
+// except the short ones.
+func (r *chartStepHandler) taint(content string, document bool) {
+	if r.sensitive == nil {
+		return
+	}
+	r.sensitive.Add(content)
+	if !document {
+		return
+	}
+
+	var doc any
+	if err := yaml.Unmarshal([]byte(content), &doc); err == nil {
+		r.sensitive.Add(stringLeaves(doc)...)
+	}
+}
+
+// stringLeaves returns the strings of a values document that are at least
+// minTaintedLength long.
+func stringLeaves(v any) []string {
+	switch v := v.(type) {
+	case string:
+		if len(v) < minTaintedLength {
+			return nil
+		}
+		return []string{v}
+	case map[string]any:
+		var out []string
+		for _, item := range v {
+			out = append(out, stringLeaves(item)...)
+		}
+		return out
+	case []any:
+		var out []string
+		for _, item := range v {
+			out = append(out, stringLeaves(item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// mergeValues deep merges src into dst; values of src win, except for maps
+// present on both sides, which are merged recursively.
+func mergeValues(dst, src map[string]any) {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]any); ok {
+			if dstMap, ok := dst[k].(map[string]any); ok {
+				mergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// setValue sets val at the given path, creating the intermediate maps.
+func setValue(dst map[string]any, path []string, val any) {
+	for _, k := range path[:len(path)-1] {
+		next, ok := dst[k].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			dst[k] = next
+		}
+		dst = next
+	}
+	dst[path[len(path)-1]] = val
+}
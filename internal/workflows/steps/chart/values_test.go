@@ -0,0 +1,105 @@
+package steps
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	rtv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+)
+
+func TestChartHandlerResolveValues(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	if err := os.WriteFile(base, []byte("replicas: 1\nauth:\n  oidc:\n    issuer: https://idp\n"), 0o644); err != nil {
+		t.Fatalf("failed to write values file: %v", err)
+	}
+	secret := filepath.Join(dir, "client-secret")
+	if err := os.WriteFile(secret, []byte("s3cr3t"), 0o600); err != nil {
+		t.Fatalf("failed to write values file: %v", err)
+	}
+
+	env := cache.New[string, string]()
+	env.Set("VALUES_DIR", dir)
+
+	handler := &chartStepHandler{env: env}
+	handler.vars = steps.EnvVars(handler.env)
+
+	spec := &types.ChartSpec{
+		ValuesFrom: []types.ValuesFromSource{
+			{File: "${VALUES_DIR}/base.yaml"},
+			{File: secret, TargetPath: "auth.oidc.clientSecret"},
+		},
+		Values: map[string]any{"replicas": 3},
+	}
+
+	got, err := handler.resolveValues(context.Background(), spec, "krateo-system")
+	if err != nil {
+		t.Fatalf("resolveValues() error = %v", err)
+	}
+
+	want := map[string]any{
+		"replicas": 3,
+		"auth": map[string]any{
+			"oidc": map[string]any{
+				"issuer":       "https://idp",
+				"clientSecret": "s3cr3t",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("resolveValues() = %v, want %v", got, want)
+	}
+}
+
+func TestChartHandlerResolveValuesErrors(t *testing.T) {
+	handler := &chartStepHandler{vars: steps.MapVars(nil)}
+
+	tests := []struct {
+		name string
+		src  types.ValuesFromSource
+	}{
+		{name: "no source", src: types.ValuesFromSource{TargetPath: "a"}},
+		{name: "two sources", src: types.ValuesFromSource{File: "a.yaml", SecretKeyRef: &rtv1.SecretKeySelector{Key: "k"}}},
+		{name: "missing file", src: types.ValuesFromSource{File: filepath.Join(t.TempDir(), "missing.yaml")}},
+		{name: "secret without cluster", src: types.ValuesFromSource{SecretKeyRef: &rtv1.SecretKeySelector{Key: "k"}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &types.ChartSpec{ValuesFrom: []types.ValuesFromSource{tc.src}}
+			if _, err := handler.resolveValues(context.Background(), spec, "krateo-system"); err == nil {
+				t.Fatalf("resolveValues() expected an error")
+			}
+		})
+	}
+}
+
+func TestChartHandlerTaintsSecretValues(t *testing.T) {
+	sensitive := &redact.Values{}
+	handler := &chartStepHandler{vars: steps.MapVars(nil), sensitive: sensitive}
+
+	handler.taint("auth:\n  clientSecret: s3cr3t-v4lue\n  provider: oidc\n  enabled: \"true\"\nnamespace: default\n", true)
+	handler.taint("p4ss", false)
+
+	for _, s := range []string{"clientSecret: s3cr3t-v4lue", "password: p4ss"} {
+		if !sensitive.Taints(s) {
+			t.Fatalf("Taints(%q) = false, want true", s)
+		}
+	}
+
+	// The short strings of the document are common words, not secrets.
+	line := "[chart:authn]: installing in namespace default with the oidc provider enabled: true"
+	if got := sensitive.String(line); got != line {
+		t.Fatalf("String() = %q, want the line unchanged", got)
+	}
+
+	// Without a sensitive set the content is left alone.
+	(&chartStepHandler{vars: steps.MapVars(nil)}).taint("s3cr3t", true)
+}
@@ -0,0 +1,329 @@
+package steps
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+	"github.com/krateoplatformops/krateoctl/internal/util/kube"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ steps.Handler[*steps.JobResult] = (*jobStepHandler)(nil)
+
+// logsGracePeriod is how long the log stream may keep running after the Job completed.
+const logsGracePeriod = 5 * time.Second
+
+// defaultTTLAfterFinished is how long, in seconds, a finished Job and its pods are
+// kept, for inspection, when the template does not set ttlSecondsAfterFinished.
+// Every run creates a new Job, so they would pile up otherwise.
+const defaultTTLAfterFinished int64 = 3600
+
+type JobHandlerOptions struct {
+	Applier *applier.Applier
+	Getter  *getter.Getter
+	Cfg     *rest.Config
+	Env     *cache.Cache[string, string]
+	// Sensitive marks the outputs built from a sensitive value, so that they are masked in the reports and checkpoints.
+	Sensitive *redact.Values
+	Logger    func(string, ...any)
+}
+
+func JobHandler(opts JobHandlerOptions) steps.Handler[*steps.JobResult] {
+	hdl := &jobStepHandler{
+		env:       opts.Env,
+		sensitive: opts.Sensitive,
+		logger:    opts.Logger,
+		apply: func(ctx context.Context, content map[string]any, o applier.ApplyOptions) error {
+			return opts.Applier.Apply(ctx, content, o)
+		},
+		wait: func(ctx context.Context, namespace, name string, timeout time.Duration) error {
+			return kube.NewJobWaiter(opts.Getter).WithTimeout(timeout).Wait(ctx, namespace, name)
+		},
+		client: func() (kubernetes.Interface, error) {
+			return kubernetes.NewForConfig(opts.Cfg)
+		},
+	}
+	hdl.vars = steps.EnvVars(hdl.env)
+
+	return hdl
+}
+
+type jobStepHandler struct {
+	env       *cache.Cache[string, string]
+	vars      steps.Vars
+	sensitive *redact.Values
+	logger    func(string, ...any)
+	apply     func(context.Context, map[string]any, applier.ApplyOptions) error
+	wait      func(ctx context.Context, namespace, name string, timeout time.Duration) error
+	client    func() (kubernetes.Interface, error)
+}
+
+// Handle applies the Job template under a unique name, streams the pod logs to
+// the debug logger and waits for completion. Outputs are exported to the
+// workflow variables. Job steps are not executed in delete mode, and are only
+// validated by the API server in dry-run mode.
+func (r *jobStepHandler) Handle(ctx context.Context, id string, ext *map[string]any, opts steps.HandleOptions) (*steps.JobResult, error) {
+	spec := types.JobStep{}
+	data, err := json.Marshal(ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job step input: %w", err)
+	}
+
+	err = json.Unmarshal(data, &spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job step input: %w", err)
+	}
+	spec.SetDefaults()
+
+	if len(spec.Template) == 0 {
+		return nil, fmt.Errorf("job step %s: with.template is required", id)
+	}
+
+	job := r.toJob(id, spec.Template, opts.Namespace)
+	result := &steps.JobResult{
+		Name:      job.GetName(),
+		Namespace: job.GetNamespace(),
+	}
+
+	if opts.Op == steps.Delete {
+		result.Operation = "none"
+		r.logger(fmt.Sprintf("[job:%s]: skipped in delete mode", id))
+		return result, nil
+	}
+
+	result.Operation = "run"
+	err = r.apply(ctx, job.Object, applier.ApplyOptions{
+		GVK:       job.GroupVersionKind(),
+		Namespace: job.GetNamespace(),
+		Name:      job.GetName(),
+		DryRun:    opts.DryRun,
+	})
+	if opts.DryRun && applier.IsUnverifiable(err) {
+		r.logger(fmt.Sprintf("[job:%s]: Job %s/%s not verified: %v", id, job.GetNamespace(), job.GetName(), err))
+		err = nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to apply Job %s/%s: %w", job.GetNamespace(), job.GetName(), err)
+	}
+	if opts.DryRun {
+		// The Job is not created, so there are no logs nor outputs to wait for.
+		result.Operation = "none"
+		r.logger(fmt.Sprintf("[job:%s]: Job %s/%s accepted in dry-run mode", id, job.GetNamespace(), job.GetName()))
+		return result, nil
+	}
+	r.logger(fmt.Sprintf("[job:%s]: created Job %s/%s", id, job.GetNamespace(), job.GetName()))
+
+	cli, err := r.client()
+	if err != nil {
+		return result, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	container := ""
+	if spec.Output != nil {
+		container = spec.Output.Container
+	}
+
+	logsCtx, stopLogs := context.WithCancel(ctx)
+	defer stopLogs()
+	logsDone := make(chan struct{})
+	go func() {
+		defer close(logsDone)
+		r.streamLogs(logsCtx, cli, id, job.GetNamespace(), job.GetName(), container)
+	}()
+
+	if err := r.wait(ctx, job.GetNamespace(), job.GetName(), spec.Timeout.Duration); err != nil {
+		return result, err
+	}
+
+	select {
+	case <-logsDone:
+	case <-time.After(logsGracePeriod):
+	}
+
+	if spec.Output == nil {
+		return result, nil
+	}
+
+	outputs, err := readOutputs(ctx, cli, job.GetNamespace(), job.GetName(), spec.Output)
+	if err != nil {
+		return result, fmt.Errorf("failed to read outputs of Job %s/%s: %w", job.GetNamespace(), job.GetName(), err)
+	}
+
+	for k, v := range outputs {
+		// An output built from a sensitive value, such as a password the
+		// Job printed back, is sensitive too.
+		if r.sensitive != nil && r.sensitive.Taints(v) {
+			r.sensitive.Add(v)
+			result.Sensitive = append(result.Sensitive, k)
+		}
+		r.env.Set(k, v)
+	}
+	slices.Sort(result.Sensitive)
+	result.Outputs = outputs
+
+	r.logger(fmt.Sprintf("[job:%s]: exported %d output(s)", id, len(outputs)))
+
+	return result, nil
+}
+
+// toJob expands the template and gives the Job a unique name, so that every run
+// creates a new Job instead of patching an immutable completed one. The Job is
+// garbage collected an hour after it finished, unless the template says otherwise.
+func (r *jobStepHandler) toJob(id string, template map[string]any, ns string) *unstructured.Unstructured {
+	job := &unstructured.Unstructured{Object: r.vars.ExpandValues(template).(map[string]any)}
+	if job.GetAPIVersion() == "" {
+		job.SetAPIVersion("batch/v1")
+	}
+	if job.GetKind() == "" {
+		job.SetKind("Job")
+	}
+	if job.GetNamespace() == "" {
+		job.SetNamespace(ns)
+	}
+	if _, ok, _ := unstructured.NestedFieldNoCopy(job.Object, "spec", "ttlSecondsAfterFinished"); !ok {
+		_ = unstructured.SetNestedField(job.Object, defaultTTLAfterFinished, "spec", "ttlSecondsAfterFinished")
+	}
+
+	base := job.GetName()
+	if base == "" {
+		base = id
+	}
+	const suffixLen = 5
+	if max := 63 - suffixLen - 1; len(base) > max {
+		base = strings.TrimRight(base[:max], "-.")
+	}
+	job.SetName(fmt.Sprintf("%s-%s", base, utilrand.String(suffixLen)))
+
+	return job
+}
+
+// streamLogs follows the logs of the first pod of the Job until it terminates.
+func (r *jobStepHandler) streamLogs(ctx context.Context, cli kubernetes.Interface, id, namespace, name, container string) {
+	pod, err := waitForPod(ctx, cli, namespace, name)
+	if err != nil {
+		return
+	}
+
+	stream, err := cli.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Follow:    true,
+	}).Stream(ctx)
+	if err != nil {
+		r.logger(fmt.Sprintf("[job:%s]: unable to stream logs of pod %s: %v", id, pod.Name, err))
+		return
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		r.logger(fmt.Sprintf("[job:%s] %s", id, scanner.Text()))
+	}
+}
+
+// waitForPod returns the first pod of the Job that left the Pending phase.
+func waitForPod(ctx context.Context, cli kubernetes.Interface, namespace, name string) (*corev1.Pod, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		pods, err := cli.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + name})
+		if err == nil {
+			for i := range pods.Items {
+				if pods.Items[i].Status.Phase != corev1.PodPending {
+					return &pods.Items[i], nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// readOutputs reads the KEY=VALUE outputs of the succeeded pod of the Job.
+func readOutputs(ctx context.Context, cli kubernetes.Interface, namespace, name string, out *types.JobOutput) (map[string]string, error) {
+	pods, err := cli.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + name})
+	if err != nil {
+		return nil, err
+	}
+
+	var pod *corev1.Pod
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodSucceeded {
+			pod = &pods.Items[i]
+			break
+		}
+	}
+	if pod == nil {
+		return nil, fmt.Errorf("no succeeded pod found")
+	}
+
+	container := out.Container
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	switch out.Source {
+	case types.JobOutputTerminationMessage:
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name == container && cs.State.Terminated != nil {
+				return parseOutputs(cs.State.Terminated.Message, ""), nil
+			}
+		}
+		return nil, fmt.Errorf("container %q has no termination message", container)
+	case types.JobOutputLogs:
+		raw, err := cli.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: container}).DoRaw(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return parseOutputs(string(raw), out.Marker), nil
+	default:
+		return nil, fmt.Errorf("unsupported output source %q (supported: %s, %s)", out.Source, types.JobOutputTerminationMessage, types.JobOutputLogs)
+	}
+}
+
+// parseOutputs extracts KEY=VALUE pairs, one per line. When marker is set only
+// the lines starting with it are considered, and the marker is stripped.
+func parseOutputs(text, marker string) map[string]string {
+	out := map[string]string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if marker != "" {
+			var ok bool
+			if line, ok = strings.CutPrefix(line, marker); !ok {
+				continue
+			}
+			line = strings.TrimSpace(line)
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,224 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseOutputs(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		marker string
+		want   map[string]string
+	}{
+		{
+			name: "termination message",
+			text: "DB_HOST=db.svc\n# comment\n\nDB_PORT = 5432\nnot a pair",
+			want: map[string]string{"DB_HOST": "db.svc", "DB_PORT": " 5432"},
+		},
+		{
+			name:   "marker lines only",
+			text:   "starting\n::out:: TOKEN=a=b\nTOKEN2=ignored\n::out::EMPTY=",
+			marker: "::out::",
+			want:   map[string]string{"TOKEN": "a=b", "EMPTY": ""},
+		},
+		{
+			name: "empty",
+			want: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseOutputs(tt.text, tt.marker)
+			if !maps.Equal(got, tt.want) {
+				t.Fatalf("parseOutputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestHandler(cli kubernetes.Interface, waitErr error) (*jobStepHandler, *[]map[string]any) {
+	env := cache.New[string, string]()
+	env.Set("IMAGE", "busybox")
+
+	var applied []map[string]any
+	hdl := &jobStepHandler{
+		env:    env,
+		logger: func(string, ...any) {},
+		apply: func(_ context.Context, content map[string]any, _ applier.ApplyOptions) error {
+			applied = append(applied, content)
+			// Simulate the Job controller creating the pod.
+			name := content["metadata"].(map[string]any)["name"].(string)
+			_, err := cli.CoreV1().Pods("krateo-system").Create(context.Background(), &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name + "-pod",
+					Namespace: "krateo-system",
+					Labels:    map[string]string{"job-name": name},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodSucceeded,
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name: "main",
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+							Message: "CLUSTER_ID=abc123\nREGION=eu-west-1",
+						}},
+					}},
+				},
+			}, metav1.CreateOptions{})
+			return err
+		},
+		wait: func(context.Context, string, string, time.Duration) error {
+			return waitErr
+		},
+		client: func() (kubernetes.Interface, error) {
+			return cli, nil
+		},
+	}
+	hdl.vars = steps.EnvVars(env)
+	return hdl, &applied
+}
+
+func jobInput(output map[string]any) *map[string]any {
+	in := map[string]any{
+		"template": map[string]any{
+			"metadata": map[string]any{"name": "bootstrap"},
+			"spec": map[string]any{
+				"template": map[string]any{
+					"spec": map[string]any{
+						"containers": []any{map[string]any{"name": "main", "image": "${IMAGE}"}},
+					},
+				},
+			},
+		},
+	}
+	if output != nil {
+		in["output"] = output
+	}
+	return &in
+}
+
+func TestJobHandler(t *testing.T) {
+	t.Run("exports termination message outputs", func(t *testing.T) {
+		hdl, applied := newTestHandler(fake.NewSimpleClientset(), nil)
+
+		res, err := hdl.Handle(context.Background(), "bootstrap", jobInput(map[string]any{}),
+			steps.HandleOptions{Namespace: "krateo-system", Op: steps.Create})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+
+		if !strings.HasPrefix(res.Name, "bootstrap-") || len(res.Name) != len("bootstrap-")+5 {
+			t.Fatalf("Handle() name = %q, want bootstrap-<suffix>", res.Name)
+		}
+		if res.Namespace != "krateo-system" {
+			t.Fatalf("Handle() namespace = %q, want krateo-system", res.Namespace)
+		}
+
+		want := map[string]string{"CLUSTER_ID": "abc123", "REGION": "eu-west-1"}
+		if !maps.Equal(res.Outputs, want) {
+			t.Fatalf("Handle() outputs = %v, want %v", res.Outputs, want)
+		}
+		if v, _ := hdl.env.Get("CLUSTER_ID"); v != "abc123" {
+			t.Fatalf("env CLUSTER_ID = %q, want abc123", v)
+		}
+
+		if len(*applied) != 1 {
+			t.Fatalf("applied %d objects, want 1", len(*applied))
+		}
+		job := (*applied)[0]
+		if job["kind"] != "Job" || job["apiVersion"] != "batch/v1" {
+			t.Fatalf("applied kind = %v %v, want batch/v1 Job", job["apiVersion"], job["kind"])
+		}
+		containers := job["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any)["containers"].([]any)
+		if image := containers[0].(map[string]any)["image"]; image != "busybox" {
+			t.Fatalf("container image = %v, want busybox", image)
+		}
+		if ttl := job["spec"].(map[string]any)["ttlSecondsAfterFinished"]; ttl != defaultTTLAfterFinished {
+			t.Fatalf("ttlSecondsAfterFinished = %v, want %d", ttl, defaultTTLAfterFinished)
+		}
+	})
+
+	t.Run("masks outputs built from sensitive values", func(t *testing.T) {
+		hdl, _ := newTestHandler(fake.NewSimpleClientset(), nil)
+		hdl.sensitive = &redact.Values{}
+		hdl.sensitive.Add("abc123")
+
+		res, err := hdl.Handle(context.Background(), "bootstrap", jobInput(map[string]any{}),
+			steps.HandleOptions{Namespace: "krateo-system", Op: steps.Create})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if want := []string{"CLUSTER_ID"}; !slices.Equal(res.Sensitive, want) {
+			t.Fatalf("Handle() sensitive = %v, want %v", res.Sensitive, want)
+		}
+
+		redacted := steps.Redact(res).(*steps.JobResult)
+		want := map[string]string{"CLUSTER_ID": redact.Mask, "REGION": "eu-west-1"}
+		if !maps.Equal(redacted.Outputs, want) {
+			t.Fatalf("Redact() outputs = %v, want %v", redacted.Outputs, want)
+		}
+		if res.Outputs["CLUSTER_ID"] != "abc123" {
+			t.Fatalf("Redact() changed the outputs of the step result")
+		}
+	})
+
+	t.Run("keeps the ttlSecondsAfterFinished of the template", func(t *testing.T) {
+		hdl, _ := newTestHandler(fake.NewSimpleClientset(), nil)
+
+		job := hdl.toJob("bootstrap", map[string]any{"spec": map[string]any{"ttlSecondsAfterFinished": int64(0)}}, "krateo-system")
+		if ttl, _, _ := unstructured.NestedInt64(job.Object, "spec", "ttlSecondsAfterFinished"); ttl != 0 {
+			t.Fatalf("ttlSecondsAfterFinished = %d, want 0", ttl)
+		}
+	})
+
+	t.Run("job failure is reported", func(t *testing.T) {
+		hdl, _ := newTestHandler(fake.NewSimpleClientset(), errors.New("job failed: BackoffLimitExceeded"))
+
+		_, err := hdl.Handle(context.Background(), "bootstrap", jobInput(nil),
+			steps.HandleOptions{Namespace: "krateo-system", Op: steps.Create})
+		if err == nil || !strings.Contains(err.Error(), "BackoffLimitExceeded") {
+			t.Fatalf("Handle() error = %v, want BackoffLimitExceeded", err)
+		}
+	})
+
+	t.Run("skipped in delete mode", func(t *testing.T) {
+		hdl, applied := newTestHandler(fake.NewSimpleClientset(), nil)
+
+		res, err := hdl.Handle(context.Background(), "bootstrap", jobInput(nil),
+			steps.HandleOptions{Namespace: "krateo-system", Op: steps.Delete})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if res.Operation != "none" || len(*applied) != 0 {
+			t.Fatalf("Handle() operation = %q, applied = %d, want none and 0", res.Operation, len(*applied))
+		}
+	})
+
+	t.Run("missing template", func(t *testing.T) {
+		hdl, _ := newTestHandler(fake.NewSimpleClientset(), nil)
+
+		_, err := hdl.Handle(context.Background(), "bootstrap", &map[string]any{},
+			steps.HandleOptions{Namespace: "krateo-system", Op: steps.Create})
+		if err == nil || !strings.Contains(err.Error(), "with.template is required") {
+			t.Fatalf("Handle() error = %v, want missing template", err)
+		}
+	})
+}
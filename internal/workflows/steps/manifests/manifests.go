@@ -0,0 +1,312 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/deletor"
+	"github.com/krateoplatformops/krateoctl/internal/util/remote"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ steps.Handler[*steps.ManifestsResult] = (*manifestsStepHandler)(nil)
+
+type ManifestsHandlerOptions struct {
+	Applier *applier.Applier
+	Deletor *deletor.Deletor
+	Env     *cache.Cache[string, string]
+	Logger  func(string, ...any)
+}
+
+func ManifestsHandler(opts ManifestsHandlerOptions) steps.Handler[*steps.ManifestsResult] {
+	hdl := &manifestsStepHandler{
+		env:    opts.Env,
+		logger: opts.Logger,
+		apply: func(ctx context.Context, content map[string]any, o applier.ApplyOptions) error {
+			return opts.Applier.Apply(ctx, content, o)
+		},
+		dryRun: func(ctx context.Context, content map[string]any, o applier.ApplyOptions) (applier.Change, error) {
+			return opts.Applier.DryRun(ctx, content, o)
+		},
+		delete: func(ctx context.Context, o deletor.DeleteOptions) error {
+			return opts.Deletor.Delete(ctx, o)
+		},
+		namespaced: func(gvk schema.GroupVersionKind) (bool, error) {
+			return opts.Applier.IsNamespaced(gvk)
+		},
+		fetch: fetchURL,
+	}
+	hdl.vars = steps.EnvVars(hdl.env)
+
+	return hdl
+}
+
+// Render loads the objects of a manifests step and expands their ${VAR}
+// placeholders with env, without sending them to the cluster. The namespaces
+// are left as written, since the scope of the kinds is known to the cluster only.
+func Render(ctx context.Context, id string, ext *map[string]any, env *cache.Cache[string, string]) ([]*unstructured.Unstructured, error) {
+	hdl := ManifestsHandler(ManifestsHandlerOptions{Env: env}).(*manifestsStepHandler)
+
+	src, err := hdl.source(id, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := hdl.load(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		hdl.vars.ExpandValues(obj.Object)
+	}
+
+	return objs, nil
+}
+
+type manifestsStepHandler struct {
+	env        *cache.Cache[string, string]
+	vars       steps.Vars
+	logger     func(string, ...any)
+	apply      func(context.Context, map[string]any, applier.ApplyOptions) error
+	dryRun     func(context.Context, map[string]any, applier.ApplyOptions) (applier.Change, error)
+	delete     func(context.Context, deletor.DeleteOptions) error
+	namespaced func(schema.GroupVersionKind) (bool, error)
+	fetch      func(ctx context.Context, url string) ([]byte, error)
+}
+
+// Handle applies all the objects of the source in document order; in delete
+// mode the objects are removed in reverse order and missing ones are ignored.
+func (r *manifestsStepHandler) Handle(ctx context.Context, id string, ext *map[string]any, opts steps.HandleOptions) (*steps.ManifestsResult, error) {
+	src, err := r.source(id, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := r.load(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &steps.ManifestsResult{Source: src.String()}
+
+	if opts.Op == steps.Delete {
+		result.Operation = "delete"
+		slices.Reverse(objs)
+	} else {
+		result.Operation = "apply"
+	}
+
+	for _, obj := range objs {
+		r.vars.ExpandValues(obj.Object)
+
+		res, err := r.handleObject(ctx, obj, opts)
+		if res != nil {
+			result.Objects = append(result.Objects, *res)
+		}
+		if err != nil {
+			return result, fmt.Errorf("%s %s %s: %w", result.Operation, obj.GetKind(), objectRef(obj), err)
+		}
+		switch {
+		case res == nil:
+		case res.Change != "":
+			r.logger(fmt.Sprintf("[manifests:%s]: %s %s %s (dry run)", id, res.Change, obj.GetKind(), objectRef(obj)))
+		default:
+			r.logger(fmt.Sprintf("[manifests:%s]: %s %s %s", id, res.Operation, obj.GetKind(), objectRef(obj)))
+		}
+	}
+
+	return result, nil
+}
+
+// handleObject applies or deletes a single object. The namespace defaults to
+// the workflow one for namespaced kinds and is dropped for cluster scoped kinds.
+// A nil result means that the object was already gone.
+func (r *manifestsStepHandler) handleObject(ctx context.Context, obj *unstructured.Unstructured, opts steps.HandleOptions) (*steps.ObjectResult, error) {
+	gvk := obj.GroupVersionKind()
+
+	namespaced, err := r.namespaced(gvk)
+	if err != nil {
+		if opts.Op == steps.Delete && meta.IsNoMatchError(err) {
+			// The CRD has already been removed together with its objects.
+			return nil, nil
+		}
+		if opts.DryRun && meta.IsNoMatchError(err) {
+			// The CRD is applied by an earlier step that the dry run did not persist.
+			return &steps.ObjectResult{
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       obj.GetKind(),
+				Name:       obj.GetName(),
+				Namespace:  obj.GetNamespace(),
+				Operation:  "apply",
+				Change:     steps.ChangeUnverified,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to resolve scope: %w", err)
+	}
+
+	switch {
+	case !namespaced:
+		obj.SetNamespace("")
+	case obj.GetNamespace() == "":
+		obj.SetNamespace(opts.Namespace)
+	}
+
+	res := &steps.ObjectResult{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+	}
+
+	if opts.Op == steps.Delete {
+		res.Operation = "delete"
+		err := r.delete(ctx, deletor.DeleteOptions{
+			GVK:       gvk,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			DryRun:    opts.DryRun,
+		})
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if opts.DryRun {
+			res.Change = steps.ChangeDelete
+		}
+		return res, err
+	}
+
+	res.Operation = "apply"
+	if opts.DryRun {
+		change, err := r.dryRun(ctx, obj.Object, applier.ApplyOptions{
+			GVK:       gvk,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		})
+		if applier.IsUnverifiable(err) {
+			change, err = steps.ChangeUnverified, nil
+		}
+		res.Change = string(change)
+		return res, err
+	}
+
+	return res, r.apply(ctx, obj.Object, applier.ApplyOptions{
+		GVK:       gvk,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	})
+}
+
+// source decodes the step input and expands the configured source.
+func (r *manifestsStepHandler) source(id string, ext *map[string]any) (types.ManifestsSource, error) {
+	spec := types.Manifests{}
+	data, err := json.Marshal(ext)
+	if err != nil {
+		return types.ManifestsSource{}, fmt.Errorf("failed to marshal manifests step input: %w", err)
+	}
+
+	err = json.Unmarshal(data, &spec)
+	if err != nil {
+		return types.ManifestsSource{}, fmt.Errorf("failed to unmarshal manifests step input: %w", err)
+	}
+
+	src := types.ManifestsSource{
+		File: r.vars.Expand(spec.Source.File),
+		Dir:  r.vars.Expand(spec.Source.Dir),
+		URL:  r.vars.Expand(spec.Source.URL),
+	}
+	if err := src.Validate(); err != nil {
+		return types.ManifestsSource{}, fmt.Errorf("manifests step %s: %w", id, err)
+	}
+
+	return src, nil
+}
+
+// load reads and decodes the manifests of the source.
+func (r *manifestsStepHandler) load(ctx context.Context, src types.ManifestsSource) ([]*unstructured.Unstructured, error) {
+	switch {
+	case src.URL != "":
+		content, err := r.fetch(ctx, src.URL)
+		if err != nil {
+			return nil, err
+		}
+		return dynamic.ParseManifests(content, src.URL)
+	case src.File != "":
+		content, err := os.ReadFile(src.File)
+		if err != nil {
+			return nil, fmt.Errorf("read manifest file %s: %w", src.File, err)
+		}
+		return dynamic.ParseManifests(content, src.File)
+	}
+
+	entries, err := os.ReadDir(src.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest directory %s: %w", src.Dir, err)
+	}
+
+	// os.ReadDir returns the entries sorted by filename.
+	var objs []*unstructured.Unstructured
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		path := filepath.Join(src.Dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read manifest file %s: %w", path, err)
+		}
+
+		list, err := dynamic.ParseManifests(content, path)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, list...)
+	}
+
+	return objs, nil
+}
+
+func fetchURL(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, remote.DefaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifests URL %s: %w", url, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d", url, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func objectRef(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
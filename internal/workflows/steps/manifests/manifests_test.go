@@ -0,0 +1,256 @@
+package steps
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/deletor"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const rbacManifests = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ${SA_NAME}
+---
+# comment only document
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: krateo-reader
+  namespace: ignored
+rules: []
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: krateo-reader
+  namespace: other
+`
+
+type recorder struct {
+	calls []string
+}
+
+func newTestHandler(rec *recorder, deleteErr error) *manifestsStepHandler {
+	env := cache.New[string, string]()
+	env.Set("SA_NAME", "krateo-sa")
+
+	hdl := &manifestsStepHandler{
+		env:    env,
+		logger: func(string, ...any) {},
+		apply: func(_ context.Context, _ map[string]any, o applier.ApplyOptions) error {
+			rec.calls = append(rec.calls, "apply "+o.GVK.Kind+" "+o.Namespace+"/"+o.Name)
+			return nil
+		},
+		dryRun: func(_ context.Context, _ map[string]any, o applier.ApplyOptions) (applier.Change, error) {
+			rec.calls = append(rec.calls, "dry-run "+o.GVK.Kind+" "+o.Namespace+"/"+o.Name)
+			switch o.GVK.Kind {
+			case "ServiceAccount":
+				return applier.ChangeUnchanged, nil
+			case "ClusterRole":
+				return applier.ChangeUpdate, nil
+			}
+			return "", apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, o.Namespace)
+		},
+		delete: func(_ context.Context, o deletor.DeleteOptions) error {
+			if o.DryRun {
+				rec.calls = append(rec.calls, "dry-run delete "+o.GVK.Kind+" "+o.Namespace+"/"+o.Name)
+				return deleteErr
+			}
+			rec.calls = append(rec.calls, "delete "+o.GVK.Kind+" "+o.Namespace+"/"+o.Name)
+			return deleteErr
+		},
+		namespaced: func(gvk schema.GroupVersionKind) (bool, error) {
+			switch gvk.Kind {
+			case "ClusterRole":
+				return false, nil
+			case "Widget":
+				return false, &meta.NoKindMatchError{GroupKind: gvk.GroupKind()}
+			}
+			return true, nil
+		},
+		fetch: func(_ context.Context, url string) ([]byte, error) {
+			rec.calls = append(rec.calls, "fetch "+url)
+			return []byte(rbacManifests), nil
+		},
+	}
+	hdl.vars = steps.EnvVars(env)
+	return hdl
+}
+
+func TestManifestsHandler(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "rbac.yaml")
+	if err := os.WriteFile(file, []byte(rbacManifests), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "z-widget.yml"), []byte("apiVersion: example.io/v1\nkind: Widget\nmetadata:\n  name: w\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := []string{
+		"apply ServiceAccount krateo-system/krateo-sa",
+		"apply ClusterRole /krateo-reader",
+		"apply RoleBinding other/krateo-reader",
+	}
+
+	tests := []struct {
+		name      string
+		source    map[string]any
+		op        steps.Op
+		deleteErr error
+		want      []string
+		wantObjs  int
+		wantErr   string
+	}{
+		{
+			name:     "file applied in order",
+			source:   map[string]any{"file": file},
+			op:       steps.Create,
+			want:     applied,
+			wantObjs: 3,
+		},
+		{
+			name:     "url",
+			source:   map[string]any{"url": "https://example.com/rbac.yaml"},
+			op:       steps.Create,
+			want:     append([]string{"fetch https://example.com/rbac.yaml"}, applied...),
+			wantObjs: 3,
+		},
+		{
+			name:    "unknown kind fails on apply",
+			source:  map[string]any{"dir": dir},
+			op:      steps.Create,
+			want:    applied,
+			wantErr: "failed to resolve scope",
+		},
+		{
+			name:   "dir deleted in reverse order skipping unknown kinds",
+			source: map[string]any{"dir": dir},
+			op:     steps.Delete,
+			want: []string{
+				"delete RoleBinding other/krateo-reader",
+				"delete ClusterRole /krateo-reader",
+				"delete ServiceAccount krateo-system/krateo-sa",
+			},
+			wantObjs: 3,
+		},
+		{
+			name:      "missing objects are ignored on delete",
+			source:    map[string]any{"file": file},
+			op:        steps.Delete,
+			deleteErr: apierrors.NewNotFound(schema.GroupResource{}, "x"),
+			want: []string{
+				"delete RoleBinding other/krateo-reader",
+				"delete ClusterRole /krateo-reader",
+				"delete ServiceAccount krateo-system/krateo-sa",
+			},
+		},
+		{
+			name:    "multiple sources",
+			source:  map[string]any{"file": file, "dir": dir},
+			op:      steps.Create,
+			wantErr: "exactly one of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			hdl := newTestHandler(rec, tt.deleteErr)
+
+			in := map[string]any{"source": tt.source}
+			res, err := hdl.Handle(context.Background(), "rbac", &in, steps.HandleOptions{Namespace: "krateo-system", Op: tt.op})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Handle() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			if !slices.Equal(rec.calls, tt.want) {
+				t.Fatalf("Handle() calls = %v, want %v", rec.calls, tt.want)
+			}
+			if tt.wantErr == "" && len(res.Objects) != tt.wantObjs {
+				t.Fatalf("Handle() objects = %d, want %d", len(res.Objects), tt.wantObjs)
+			}
+		})
+	}
+}
+
+func TestManifestsHandlerDryRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "rbac.yaml"), []byte(rbacManifests), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "z-widget.yml"), []byte("apiVersion: example.io/v1\nkind: Widget\nmetadata:\n  name: w\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		op          steps.Op
+		wantCalls   []string
+		wantChanges []string
+	}{
+		{
+			name: "apply reports the changes without applying",
+			op:   steps.Create,
+			wantCalls: []string{
+				"dry-run ServiceAccount krateo-system/krateo-sa",
+				"dry-run ClusterRole /krateo-reader",
+				"dry-run RoleBinding other/krateo-reader",
+			},
+			wantChanges: []string{"unchanged", "update", steps.ChangeUnverified, steps.ChangeUnverified},
+		},
+		{
+			name: "delete reports the objects that would be removed",
+			op:   steps.Delete,
+			wantCalls: []string{
+				"dry-run delete RoleBinding other/krateo-reader",
+				"dry-run delete ClusterRole /krateo-reader",
+				"dry-run delete ServiceAccount krateo-system/krateo-sa",
+			},
+			wantChanges: []string{steps.ChangeDelete, steps.ChangeDelete, steps.ChangeDelete},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			hdl := newTestHandler(rec, nil)
+
+			in := map[string]any{"source": map[string]any{"dir": dir}}
+			res, err := hdl.Handle(context.Background(), "rbac", &in, steps.HandleOptions{Namespace: "krateo-system", Op: tt.op, DryRun: true})
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			if !slices.Equal(rec.calls, tt.wantCalls) {
+				t.Fatalf("Handle() calls = %v, want %v", rec.calls, tt.wantCalls)
+			}
+
+			var changes []string
+			for _, obj := range res.Objects {
+				changes = append(changes, obj.Change)
+			}
+			if !slices.Equal(changes, tt.wantChanges) {
+				t.Fatalf("Handle() changes = %v, want %v", changes, tt.wantChanges)
+			}
+		})
+	}
+}
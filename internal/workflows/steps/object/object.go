@@ -8,7 +8,8 @@ import (
 	"github.com/krateoplatformops/krateoctl/internal/cache"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/deletor"
-	"github.com/krateoplatformops/krateoctl/internal/expand"
+	"github.com/krateoplatformops/krateoctl/internal/health"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -18,40 +19,35 @@ import (
 
 var _ steps.Handler[*steps.ObjectResult] = (*objStepHandler)(nil)
 
-func ObjectHandler(app *applier.Applier, del *deletor.Deletor, env *cache.Cache[string, string], logger func(string, ...any)) steps.Handler[*steps.ObjectResult] {
+// ObjectHandler applies object steps; ready evaluates the waitForReady option
+// and may be nil when no step needs it.
+func ObjectHandler(app *applier.Applier, del *deletor.Deletor, ready *health.Waiter, env *cache.Cache[string, string], logger func(string, ...any)) steps.Handler[*steps.ObjectResult] {
 	return &objStepHandler{
-		app: app, del: del, env: env,
-		subst: func(k string) string {
-			if v, ok := env.Get(k); ok {
-				return v
-			}
-
-			return "$" + k
-		},
+		app: app, del: del, ready: ready, env: env,
+		vars:   steps.EnvVars(env),
 		logger: logger,
 	}
 }
 
+// Render builds the object of an object step, expanding the ${VAR} placeholders
+// with env, without sending it to the cluster.
+func Render(id string, ext *map[string]any, namespace string, env *cache.Cache[string, string], logger func(string, ...any)) (*unstructured.Unstructured, error) {
+	hdl := ObjectHandler(nil, nil, nil, env, logger).(*objStepHandler)
+	uns, _, err := hdl.toUnstructured(id, ext, namespace)
+	return uns, err
+}
+
 type objStepHandler struct {
 	app    *applier.Applier
 	del    *deletor.Deletor
+	ready  *health.Waiter
 	env    *cache.Cache[string, string]
-	ns     string
-	op     steps.Op
-	subst  func(k string) string
+	vars   steps.Vars
 	logger func(string, ...any)
 }
 
-func (r *objStepHandler) Namespace(ns string) {
-	r.ns = ns
-}
-
-func (r *objStepHandler) Op(op steps.Op) {
-	r.op = op
-}
-
-func (r *objStepHandler) Handle(ctx context.Context, id string, ext *map[string]any) (*steps.ObjectResult, error) {
-	uns, err := r.toUnstructured(id, ext)
+func (r *objStepHandler) Handle(ctx context.Context, id string, ext *map[string]any, opts steps.HandleOptions) (*steps.ObjectResult, error) {
+	uns, readiness, err := r.toUnstructured(id, ext, opts.Namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -68,45 +64,84 @@ func (r *objStepHandler) Handle(ctx context.Context, id string, ext *map[string]
 		Namespace:  uns.GetNamespace(),
 	}
 
-	if r.op == steps.Delete {
+	if opts.Op == steps.Delete {
 		result.Operation = "delete"
 		err := r.del.Delete(ctx, deletor.DeleteOptions{
 			GVK:       gv.WithKind(uns.GetKind()),
 			Namespace: uns.GetNamespace(),
 			Name:      uns.GetName(),
+			DryRun:    opts.DryRun,
 		})
+		if opts.DryRun {
+			result.Change = steps.ChangeDelete
+		}
 		if apierrors.IsNotFound(err) {
+			result.Change = ""
 			err = nil
 		}
 		return result, err
 	}
 
+	if opts.DryRun {
+		result.Operation = "apply"
+		change, err := r.app.DryRun(ctx, uns.Object, applier.ApplyOptions{
+			GVK:       gv.WithKind(uns.GetKind()),
+			Namespace: uns.GetNamespace(),
+			Name:      uns.GetName(),
+		})
+		if applier.IsUnverifiable(err) {
+			r.logger(fmt.Sprintf("[object:%s]: %s %s not verified: %v", id, uns.GetKind(), uns.GetName(), err))
+			change, err = steps.ChangeUnverified, nil
+		}
+		result.Change = string(change)
+		return result, err
+	}
+
 	result.Operation = "apply"
-	err = r.app.Apply(ctx, uns.Object, applier.ApplyOptions{
+	obj, err := r.app.ApplyObject(ctx, uns.Object, applier.ApplyOptions{
 		GVK:       gv.WithKind(uns.GetKind()),
 		Namespace: uns.GetNamespace(),
 		Name:      uns.GetName(),
 	})
+	if obj != nil {
+		result.UID = string(obj.GetUID())
+		result.ResourceVersion = obj.GetResourceVersion()
+		result.Generation = obj.GetGeneration()
+	}
+	if err != nil || !readiness.WaitForReady {
+		return result, err
+	}
 
-	return result, err
+	if r.ready == nil {
+		return result, fmt.Errorf("waitForReady requires a cluster connection")
+	}
+	err = r.ready.WaitForReady(ctx, id, []*unstructured.Unstructured{uns}, uns.GetNamespace(), readiness.Timeout())
+	if err != nil {
+		return result, err
+	}
+	result.Ready = true
+	r.logger(fmt.Sprintf("[object:%s]: %s %s is ready", id, uns.GetKind(), uns.GetName()))
+
+	return result, nil
 }
 
-func (r *objStepHandler) toUnstructured(id string, ext *map[string]any) (*unstructured.Unstructured, error) {
+// toUnstructured builds the object of the step and returns its readiness options.
+func (r *objStepHandler) toUnstructured(id string, ext *map[string]any, ns string) (*unstructured.Unstructured, types.Readiness, error) {
 	res := types.Object{}
 
 	data, err := json.Marshal(ext)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal chart step input: %w", err)
+		return nil, types.Readiness{}, fmt.Errorf("failed to marshal chart step input: %w", err)
 	}
 
 	err = json.Unmarshal(data, &res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal object step input: %w", err)
+		return nil, types.Readiness{}, fmt.Errorf("failed to unmarshal object step input: %w", err)
 	}
 
 	namespace := res.Metadata.Namespace
 	if len(namespace) == 0 {
-		namespace = r.ns
+		namespace = ns
 	}
 
 	src := map[string]any{
@@ -119,15 +154,15 @@ func (r *objStepHandler) toUnstructured(id string, ext *map[string]any) (*unstru
 	}
 
 	mergeMaps(src, res.BodyFields)
-	if expanded := r.expandValues(src); expanded != nil {
+	if expanded := r.vars.ExpandValues(src); expanded != nil {
 		if objMap, ok := expanded.(map[string]any); ok {
 			src = objMap
 		}
 	}
 
-	r.logger(fmt.Sprintf("[object:%s]: %v", id, src))
+	r.logger(fmt.Sprintf("[object:%s]: %v", id, redact.Secret(src)))
 
-	return &unstructured.Unstructured{Object: src}, nil
+	return &unstructured.Unstructured{Object: src}, res.Readiness, nil
 }
 
 func mergeMaps(dest, src map[string]any) {
@@ -144,23 +179,3 @@ func mergeMaps(dest, src map[string]any) {
 		dest[k] = v
 	}
 }
-
-// expandValues resolves ${VAR} placeholders recursively using the shared cache.
-func (r *objStepHandler) expandValues(val any) any {
-	switch v := val.(type) {
-	case map[string]any:
-		for key, elem := range v {
-			v[key] = r.expandValues(elem)
-		}
-		return v
-	case []any:
-		for i, elem := range v {
-			v[i] = r.expandValues(elem)
-		}
-		return v
-	case string:
-		return expand.Expand(v, "", r.subst)
-	default:
-		return val
-	}
-}
@@ -2,21 +2,18 @@ package steps
 
 import (
 	"testing"
+	"time"
 
 	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
 )
 
 func TestObjectHandlerExpandValues(t *testing.T) {
 	env := cache.New[string, string]()
 	env.Set("CONFIG_VALUE", "expanded")
 
-	handler := &objStepHandler{env: env, ns: "default"}
-	handler.subst = func(k string) string {
-		if v, ok := handler.env.Get(k); ok {
-			return v
-		}
-		return "$" + k
-	}
+	handler := &objStepHandler{env: env, logger: func(string, ...any) {}}
+	handler.vars = steps.EnvVars(handler.env)
 
 	ext := map[string]any{
 		"apiVersion": "v1",
@@ -27,13 +24,22 @@ func TestObjectHandlerExpandValues(t *testing.T) {
 		"data": map[string]any{
 			"KEY": "${CONFIG_VALUE}",
 		},
+		"waitForReady": true,
+		"readyTimeout": "30s",
 	}
 
-	uns, err := handler.toUnstructured("obj-expand", &ext)
+	uns, readiness, err := handler.toUnstructured("obj-expand", &ext, "default")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
+	if !readiness.WaitForReady || readiness.Timeout() != 30*time.Second {
+		t.Fatalf("unexpected readiness options: %+v", readiness)
+	}
+	if _, ok := uns.Object["waitForReady"]; ok {
+		t.Fatalf("readiness options must not be part of the object")
+	}
+
 	data, ok := uns.Object["data"].(map[string]any)
 	if !ok {
 		t.Fatalf("expected data map in unstructured object")
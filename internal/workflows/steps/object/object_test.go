@@ -83,9 +83,6 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create object handler: %v", err)
 			}
 
-			handler.Namespace(objNamespace)
-			handler.Op(steps.Create)
-
 			objJSON := `{
                 "apiVersion": "v1",
                 "kind": "ConfigMap",
@@ -104,7 +101,7 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal object JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-create-cm", &ext)
+			result, err := handler.Handle(ctx, "test-create-cm", &ext, steps.HandleOptions{Namespace: objNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -150,9 +147,6 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create object handler: %v", err)
 			}
 
-			handler.Namespace(objNamespace)
-			handler.Op(steps.Create)
-
 			objJSON := `{
                 "apiVersion": "v1",
                 "kind": "Secret",
@@ -172,7 +166,7 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal object JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-create-secret", &ext)
+			result, err := handler.Handle(ctx, "test-create-secret", &ext, steps.HandleOptions{Namespace: objNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -210,9 +204,6 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create object handler: %v", err)
 			}
 
-			handler.Namespace(objNamespace)
-			handler.Op(steps.Update)
-
 			objJSON := `{
                 "apiVersion": "v1",
                 "kind": "ConfigMap",
@@ -231,7 +222,7 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal object JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-update-cm", &ext)
+			result, err := handler.Handle(ctx, "test-update-cm", &ext, steps.HandleOptions{Namespace: objNamespace, Op: steps.Update})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -272,9 +263,6 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create object handler: %v", err)
 			}
 
-			handler.Namespace(objNamespace)
-			handler.Op(steps.Delete)
-
 			objJSON := `{
                 "apiVersion": "v1",
                 "kind": "ConfigMap",
@@ -289,7 +277,7 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal object JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-delete-cm", &ext)
+			result, err := handler.Handle(ctx, "test-delete-cm", &ext, steps.HandleOptions{Namespace: objNamespace, Op: steps.Delete})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -321,9 +309,6 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create object handler: %v", err)
 			}
 
-			handler.Namespace(objNamespace)
-			handler.Op(steps.Create)
-
 			// No namespace specified in metadata, should use handler's default
 			objJSON := `{
                 "apiVersion": "v1",
@@ -341,7 +326,7 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal object JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-default-ns-cm", &ext)
+			result, err := handler.Handle(ctx, "test-default-ns-cm", &ext, steps.HandleOptions{Namespace: objNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -375,9 +360,6 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create object handler: %v", err)
 			}
 
-			handler.Namespace(objNamespace)
-			handler.Op(steps.Create)
-
 			// Test object with multiple fields that should be captured in BodyFields
 			objJSON := `{
                 "apiVersion": "apps/v1",
@@ -421,7 +403,7 @@ func TestObjectStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal object JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-deployment", &ext)
+			result, err := handler.Handle(ctx, "test-deployment", &ext, steps.HandleOptions{Namespace: objNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -457,7 +439,7 @@ func createObjectHandler(cfg *envconf.Config) (*objStepHandler, error) {
 
 	env := cache.New[string, string]()
 
-	handler := ObjectHandler(applier, deletor, env, func(msg string, args ...any) {
+	handler := ObjectHandler(applier, deletor, nil, env, func(msg string, args ...any) {
 		fmt.Printf("ObjectHandler: %s\n", fmt.Sprintf(msg, args...))
 	})
 	return handler.(*objStepHandler), nil
@@ -0,0 +1,424 @@
+package steps
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ steps.Handler[*steps.SecretResult] = (*secretStepHandler)(nil)
+
+var secretGVK = schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+
+type SecretHandlerOptions struct {
+	Applier *applier.Applier
+	Getter  *getter.Getter
+	Env     *cache.Cache[string, string]
+	// Sensitive receives the exported key values, so that they are masked in the logs and reports.
+	Sensitive *redact.Values
+	Logger    func(string, ...any)
+}
+
+func SecretHandler(opts SecretHandlerOptions) steps.Handler[*steps.SecretResult] {
+	hdl := &secretStepHandler{
+		env:       opts.Env,
+		sensitive: opts.Sensitive,
+		logger:    opts.Logger,
+		get: func(ctx context.Context, o getter.GetOptions) (*unstructured.Unstructured, error) {
+			return opts.Getter.Get(ctx, o)
+		},
+		apply: func(ctx context.Context, content map[string]any, o applier.ApplyOptions) error {
+			return opts.Applier.Apply(ctx, content, o)
+		},
+	}
+	hdl.vars = steps.EnvVars(hdl.env)
+
+	return hdl
+}
+
+type secretStepHandler struct {
+	env       *cache.Cache[string, string]
+	sensitive *redact.Values
+	vars      steps.Vars
+	logger    func(string, ...any)
+	get       func(context.Context, getter.GetOptions) (*unstructured.Unstructured, error)
+	apply     func(context.Context, map[string]any, applier.ApplyOptions) error
+}
+
+// Handle adds the missing keys to the Secret and exports every key as a
+// workflow variable. Existing keys are kept as they are, and values never
+// appear in logs or in the step result. Secrets are not deleted in delete mode.
+func (r *secretStepHandler) Handle(ctx context.Context, id string, ext *map[string]any, opts steps.HandleOptions) (*steps.SecretResult, error) {
+	spec := types.SecretStep{}
+	data, err := json.Marshal(ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal secret step input: %w", err)
+	}
+
+	err = json.Unmarshal(data, &spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal secret step input: %w", err)
+	}
+
+	spec.Name = r.vars.Expand(spec.Name)
+	spec.Namespace = r.vars.Expand(spec.Namespace)
+	if spec.Namespace == "" {
+		spec.Namespace = opts.Namespace
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("secret step %s: %w", id, err)
+	}
+
+	result := &steps.SecretResult{
+		Name:      spec.Name,
+		Namespace: spec.Namespace,
+		Operation: "none",
+	}
+
+	if opts.Op == steps.Delete {
+		r.logger(fmt.Sprintf("[secret:%s]: secret %s/%s is kept in delete mode", id, spec.Namespace, spec.Name))
+		return result, nil
+	}
+
+	res := &resolver{
+		handler: r,
+		spec:    &spec,
+		others:  map[string]map[string]string{},
+	}
+
+	existing, secretType, err := res.read(ctx, spec.Namespace, spec.Name)
+	if err != nil {
+		return result, err
+	}
+
+	added, err := res.resolve(ctx, existing)
+	if err != nil {
+		return result, fmt.Errorf("secret %s/%s: %w", spec.Namespace, spec.Name, err)
+	}
+
+	if len(added) > 0 {
+		if existing == nil {
+			result.Operation = "create"
+			secretType = spec.Type
+			if secretType == "" {
+				secretType = "Opaque"
+			}
+		} else {
+			result.Operation = "update"
+		}
+
+		if err := r.apply(ctx, toSecret(spec.Namespace, spec.Name, secretType, res.values), applier.ApplyOptions{
+			GVK:       secretGVK,
+			Namespace: spec.Namespace,
+			Name:      spec.Name,
+			DryRun:    opts.DryRun,
+		}); err != nil {
+			if !opts.DryRun || !applier.IsUnverifiable(err) {
+				return result, fmt.Errorf("failed to apply secret %s/%s: %w", spec.Namespace, spec.Name, err)
+			}
+			r.logger(fmt.Sprintf("[secret:%s]: secret %s/%s not verified: %v", id, spec.Namespace, spec.Name, err))
+		}
+		result.Added = added
+	}
+
+	for _, key := range spec.KeyNames() {
+		name := spec.ExportName(key)
+		r.env.Set(name, res.values[key])
+		if r.sensitive != nil {
+			r.sensitive.Add(res.values[key])
+		}
+		result.Exported = append(result.Exported, name)
+	}
+
+	r.logger(fmt.Sprintf("[secret:%s]: %s %s/%s, added keys %v, exported %v",
+		id, result.Operation, spec.Namespace, spec.Name, result.Added, result.Exported))
+
+	return result, nil
+}
+
+// resolver computes the values of the keys of a single Secret.
+type resolver struct {
+	handler *secretStepHandler
+	spec    *types.SecretStep
+	// values holds the resolved keys of the managed Secret.
+	values map[string]string
+	// others caches the data of the other Secrets read, keyed by namespace/name.
+	others map[string]map[string]string
+}
+
+// resolve fills in the keys missing from existing and returns their names.
+//
+// Literal and fromSecret keys are resolved first. Generated keys then take,
+// when possible, the value of the other side of a constraint; the remaining
+// ones are generated one at a time, so that a value generated for a key is
+// propagated to the keys constrained to it. Finally every constraint whose
+// sides are both known must hold.
+func (res *resolver) resolve(ctx context.Context, existing map[string]string) ([]string, error) {
+	res.values = map[string]string{}
+	for k, v := range existing {
+		res.values[k] = v
+	}
+
+	constraints := make([]types.SecretConstraint, 0, len(res.spec.Constraints))
+	for _, expr := range res.spec.Constraints {
+		c, err := types.ParseSecretConstraint(expr)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, c)
+	}
+
+	var added, pending []string
+	for _, key := range res.spec.KeyNames() {
+		if _, ok := res.values[key]; ok {
+			continue
+		}
+		added = append(added, key)
+
+		src := res.spec.Keys[key]
+		switch {
+		case src.Value != "":
+			res.values[key] = res.handler.vars.Expand(src.Value)
+		case src.FromSecret != nil:
+			val, err := res.fromSecret(ctx, src.FromSecret)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", key, err)
+			}
+			res.values[key] = val
+		default:
+			pending = append(pending, key)
+		}
+	}
+
+	for {
+		for changed := true; changed; {
+			changed = false
+			for _, c := range constraints {
+				ok, err := res.propagate(ctx, c, &pending)
+				if err != nil {
+					return nil, err
+				}
+				changed = changed || ok
+			}
+		}
+
+		if len(pending) == 0 {
+			break
+		}
+
+		key := pending[0]
+		pending = pending[1:]
+		val, err := generate(res.spec.Keys[key])
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", key, err)
+		}
+		res.values[key] = val
+	}
+
+	for _, c := range constraints {
+		l, lok, err := res.lookup(ctx, c.Left)
+		if err != nil {
+			return nil, err
+		}
+		r, rok, err := res.lookup(ctx, c.Right)
+		if err != nil {
+			return nil, err
+		}
+		if lok && rok && l != r {
+			return nil, fmt.Errorf("constraint %q is not satisfied: values differ", c)
+		}
+	}
+
+	return added, nil
+}
+
+// propagate copies a known value to a pending generated key on the other side
+// of the constraint. It reports whether a key was resolved.
+func (res *resolver) propagate(ctx context.Context, c types.SecretConstraint, pending *[]string) (bool, error) {
+	for _, pair := range [][2]types.SecretKeyPath{{c.Left, c.Right}, {c.Right, c.Left}} {
+		target, source := pair[0], pair[1]
+		if target.Secret != res.spec.Name {
+			continue
+		}
+
+		idx := -1
+		for i, key := range *pending {
+			if key == target.Key {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			continue
+		}
+
+		val, ok, err := res.lookup(ctx, source)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			continue
+		}
+
+		res.values[target.Key] = val
+		*pending = append((*pending)[:idx], (*pending)[idx+1:]...)
+		return true, nil
+	}
+	return false, nil
+}
+
+// lookup returns the value of a key, either of the managed Secret or of
+// another Secret in the same namespace.
+func (res *resolver) lookup(ctx context.Context, path types.SecretKeyPath) (string, bool, error) {
+	if path.Secret == res.spec.Name {
+		val, ok := res.values[path.Key]
+		return val, ok, nil
+	}
+
+	data, err := res.other(ctx, res.spec.Namespace, path.Secret)
+	if err != nil {
+		return "", false, err
+	}
+	val, ok := data[path.Key]
+	return val, ok, nil
+}
+
+func (res *resolver) fromSecret(ctx context.Context, ref *types.SecretKeyRef) (string, error) {
+	namespace := res.handler.vars.Expand(ref.Namespace)
+	if namespace == "" {
+		namespace = res.spec.Namespace
+	}
+
+	data, err := res.other(ctx, namespace, ref.Name)
+	if err != nil {
+		return "", err
+	}
+	val, ok := data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s/%s", ref.Key, namespace, ref.Name)
+	}
+	return val, nil
+}
+
+// other returns the data of another Secret; a missing Secret has no keys.
+func (res *resolver) other(ctx context.Context, namespace, name string) (map[string]string, error) {
+	ref := namespace + "/" + name
+	if data, ok := res.others[ref]; ok {
+		return data, nil
+	}
+
+	data, _, err := res.read(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	res.others[ref] = data
+	return data, nil
+}
+
+// read returns the decoded data and the type of a Secret, or nil if it does not exist.
+func (res *resolver) read(ctx context.Context, namespace, name string) (map[string]string, string, error) {
+	obj, err := res.handler.get(ctx, getter.GetOptions{
+		GVK:       secretGVK,
+		Namespace: namespace,
+		Name:      name,
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+
+	raw, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid data in secret %s/%s: %w", namespace, name, err)
+	}
+
+	data := make(map[string]string, len(raw))
+	for k, v := range raw {
+		dec, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid value of key %s in secret %s/%s: %w", k, namespace, name, err)
+		}
+		data[k] = string(dec)
+	}
+
+	secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+	return data, secretType, nil
+}
+
+// toSecret builds the applied Secret. Existing keys are included with their
+// current value, so that the server-side apply does not drop them.
+func toSecret(namespace, name, secretType string, values map[string]string) map[string]any {
+	data := make(map[string]any, len(values))
+	for k, v := range values {
+		data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"type": secretType,
+		"data": data,
+	}
+}
+
+func generate(key types.SecretKey) (string, error) {
+	switch key.Generate {
+	case types.SecretGenerateBase64Key:
+		length := key.Length
+		if length == 0 {
+			length = types.DefaultBase64KeyLength
+		}
+		return generateBase64Key(length)
+	default:
+		length := key.Length
+		if length == 0 {
+			length = types.DefaultPasswordLength
+		}
+		return generatePassword(length)
+	}
+}
+
+// generateBase64Key returns length random bytes encoded in base64.
+func generateBase64Key(length int) (string, error) {
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// generatePassword returns a random password made of letters and numbers only (URL-safe).
+func generatePassword(length int) (string, error) {
+	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	password := make([]byte, length)
+	charsetLen := big.NewInt(int64(len(charset)))
+
+	for i := 0; i < length; i++ {
+		idx, err := rand.Int(rand.Reader, charsetLen)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random password: %w", err)
+		}
+		password[i] = charset[idx.Int64()]
+	}
+
+	return string(password), nil
+}
@@ -0,0 +1,262 @@
+package steps
+
+import (
+	"context"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeCluster stores Secrets data by name, values in clear text.
+type fakeCluster map[string]map[string]string
+
+func newTestHandler(cluster fakeCluster) (*secretStepHandler, *int) {
+	env := cache.New[string, string]()
+	env.Set("DB_USER", "krateo-db-user")
+
+	applies := 0
+	hdl := &secretStepHandler{
+		env:    env,
+		logger: func(string, ...any) {},
+		get: func(_ context.Context, o getter.GetOptions) (*unstructured.Unstructured, error) {
+			data, ok := cluster[o.Name]
+			if !ok {
+				return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, o.Name)
+			}
+			enc := map[string]any{}
+			for k, v := range data {
+				enc[k] = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+			return &unstructured.Unstructured{Object: map[string]any{"type": "Opaque", "data": enc}}, nil
+		},
+		apply: func(_ context.Context, content map[string]any, o applier.ApplyOptions) error {
+			applies++
+			data := map[string]string{}
+			for k, v := range content["data"].(map[string]any) {
+				dec, _ := base64.StdEncoding.DecodeString(v.(string))
+				data[k] = string(dec)
+			}
+			cluster[o.Name] = data
+			return nil
+		},
+	}
+	hdl.vars = steps.EnvVars(env)
+	return hdl, &applies
+}
+
+const dbConstraint = "krateo-db.DB_PASS == krateo-db-user.password"
+
+func dbSecret() *map[string]any {
+	return &map[string]any{
+		"name": "krateo-db",
+		"keys": map[string]any{
+			"DB_USER": map[string]any{"value": "${DB_USER}"},
+			"DB_PASS": map[string]any{"generate": "password", "length": 24},
+		},
+		"constraints": []any{dbConstraint},
+	}
+}
+
+func dbUserSecret() *map[string]any {
+	return &map[string]any{
+		"name": "krateo-db-user",
+		"keys": map[string]any{
+			"username": map[string]any{"value": "${DB_USER}"},
+			"password": map[string]any{"generate": "password", "export": "DB_USER_PASSWORD"},
+		},
+		"constraints": []any{dbConstraint},
+	}
+}
+
+func TestSecretHandler(t *testing.T) {
+	opts := steps.HandleOptions{Namespace: "krateo-system", Op: steps.Create}
+
+	t.Run("generates consistent passwords", func(t *testing.T) {
+		cluster := fakeCluster{}
+		hdl, _ := newTestHandler(cluster)
+
+		res, err := hdl.Handle(context.Background(), "db", dbSecret(), opts)
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if res.Operation != "create" || !slices.Equal(res.Added, []string{"DB_PASS", "DB_USER"}) {
+			t.Fatalf("Handle() = %s %v, want create [DB_PASS DB_USER]", res.Operation, res.Added)
+		}
+		if got := cluster["krateo-db"]["DB_PASS"]; len(got) != 24 {
+			t.Fatalf("DB_PASS length = %d, want 24", len(got))
+		}
+
+		if _, err := hdl.Handle(context.Background(), "db-user", dbUserSecret(), opts); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if cluster["krateo-db-user"]["password"] != cluster["krateo-db"]["DB_PASS"] {
+			t.Fatalf("password = %q, want DB_PASS %q", cluster["krateo-db-user"]["password"], cluster["krateo-db"]["DB_PASS"])
+		}
+		if got := cluster["krateo-db-user"]["username"]; got != "krateo-db-user" {
+			t.Fatalf("username = %q, want krateo-db-user", got)
+		}
+
+		if v, _ := hdl.env.Get("DB_USER_PASSWORD"); v != cluster["krateo-db"]["DB_PASS"] {
+			t.Fatalf("exported DB_USER_PASSWORD = %q, want the shared password", v)
+		}
+		if v, _ := hdl.env.Get("KRATEO_DB_DB_PASS"); v != cluster["krateo-db"]["DB_PASS"] {
+			t.Fatalf("exported KRATEO_DB_DB_PASS = %q, want the shared password", v)
+		}
+	})
+
+	t.Run("adopts the existing side of a constraint", func(t *testing.T) {
+		cluster := fakeCluster{"krateo-db-user": {"username": "krateo-db-user", "password": "existing"}}
+		hdl, _ := newTestHandler(cluster)
+
+		if _, err := hdl.Handle(context.Background(), "db", dbSecret(), opts); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if got := cluster["krateo-db"]["DB_PASS"]; got != "existing" {
+			t.Fatalf("DB_PASS = %q, want existing", got)
+		}
+	})
+
+	t.Run("never overwrites existing keys", func(t *testing.T) {
+		cluster := fakeCluster{"krateo-db": {"DB_USER": "custom", "DB_PASS": "keep-me", "EXTRA": "x"}}
+		hdl, applies := newTestHandler(cluster)
+
+		res, err := hdl.Handle(context.Background(), "db", dbSecret(), opts)
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if res.Operation != "none" || *applies != 0 {
+			t.Fatalf("Handle() operation = %s, applies = %d, want none and 0", res.Operation, *applies)
+		}
+		if got := cluster["krateo-db"]["DB_PASS"]; got != "keep-me" {
+			t.Fatalf("DB_PASS = %q, want keep-me", got)
+		}
+	})
+
+	t.Run("adds missing keys keeping the others", func(t *testing.T) {
+		cluster := fakeCluster{"krateo-db": {"DB_PASS": "keep-me", "EXTRA": "x"}}
+		hdl, _ := newTestHandler(cluster)
+
+		res, err := hdl.Handle(context.Background(), "db", dbSecret(), opts)
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if res.Operation != "update" || !slices.Equal(res.Added, []string{"DB_USER"}) {
+			t.Fatalf("Handle() = %s %v, want update [DB_USER]", res.Operation, res.Added)
+		}
+		want := map[string]string{"DB_PASS": "keep-me", "EXTRA": "x", "DB_USER": "krateo-db-user"}
+		for k, v := range want {
+			if cluster["krateo-db"][k] != v {
+				t.Fatalf("%s = %q, want %q", k, cluster["krateo-db"][k], v)
+			}
+		}
+	})
+
+	t.Run("reports violated constraints", func(t *testing.T) {
+		cluster := fakeCluster{
+			"krateo-db":      {"DB_USER": "krateo-db-user", "DB_PASS": "one"},
+			"krateo-db-user": {"username": "krateo-db-user", "password": "two"},
+		}
+		hdl, _ := newTestHandler(cluster)
+
+		_, err := hdl.Handle(context.Background(), "db", dbSecret(), opts)
+		if err == nil || !strings.Contains(err.Error(), "is not satisfied") {
+			t.Fatalf("Handle() error = %v, want constraint violation", err)
+		}
+	})
+
+	t.Run("fromSecret copies another secret", func(t *testing.T) {
+		cluster := fakeCluster{"jwt-sign-key": {"JWT_SIGN_KEY": "signing"}}
+		hdl, _ := newTestHandler(cluster)
+
+		in := &map[string]any{
+			"name": "copy",
+			"keys": map[string]any{
+				"KEY": map[string]any{"fromSecret": map[string]any{"name": "jwt-sign-key", "key": "JWT_SIGN_KEY"}},
+			},
+		}
+		if _, err := hdl.Handle(context.Background(), "copy", in, opts); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if got := cluster["copy"]["KEY"]; got != "signing" {
+			t.Fatalf("KEY = %q, want signing", got)
+		}
+	})
+
+	t.Run("the result holds no values", func(t *testing.T) {
+		cluster := fakeCluster{"krateo-db": {"DB_USER": "krateo-db-user", "DB_PASS": "super-secret"}}
+		hdl, _ := newTestHandler(cluster)
+
+		var logs []string
+		hdl.logger = func(msg string, _ ...any) { logs = append(logs, msg) }
+
+		res, err := hdl.Handle(context.Background(), "db", dbSecret(), opts)
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		out := strings.Join(append(logs, res.Name, res.Namespace, strings.Join(res.Exported, ",")), "\n")
+		if strings.Contains(out, "super-secret") {
+			t.Fatalf("secret value leaked: %s", out)
+		}
+	})
+}
+
+func TestSecretHandlerValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		with    map[string]any
+		wantErr string
+	}{
+		{
+			name:    "missing name",
+			with:    map[string]any{"keys": map[string]any{"A": map[string]any{"value": "x"}}},
+			wantErr: "name is required",
+		},
+		{
+			name: "multiple sources",
+			with: map[string]any{"name": "s", "keys": map[string]any{
+				"A": map[string]any{"value": "x", "generate": "password"},
+			}},
+			wantErr: "exactly one of generate, value or fromSecret",
+		},
+		{
+			name: "unknown generator",
+			with: map[string]any{"name": "s", "keys": map[string]any{
+				"A": map[string]any{"generate": "uuid"},
+			}},
+			wantErr: `unsupported generate "uuid"`,
+		},
+		{
+			name: "unrelated constraint",
+			with: map[string]any{"name": "s", "keys": map[string]any{
+				"A": map[string]any{"generate": "password"},
+			}, "constraints": []any{"a.x == b.y"}},
+			wantErr: "does not reference secret s",
+		},
+		{
+			name: "malformed constraint",
+			with: map[string]any{"name": "s", "keys": map[string]any{
+				"A": map[string]any{"generate": "password"},
+			}, "constraints": []any{"s.A = b.y"}},
+			wantErr: "invalid constraint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hdl, _ := newTestHandler(fakeCluster{})
+			_, err := hdl.Handle(context.Background(), "s", &tt.with, steps.HandleOptions{Namespace: "ns", Op: steps.Create})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Handle() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -12,8 +12,24 @@ const (
 	Delete
 )
 
+// HandleOptions carries the settings of a single step invocation.
+// They are passed on every call so that handlers can serve concurrent steps.
+type HandleOptions struct {
+	Namespace string
+	Op        Op
+	// DryRun runs the step against the API server in dry-run mode: objects are
+	// validated and admitted but nothing is persisted.
+	DryRun bool
+}
+
+// Changes reported by the dry runs, in addition to the applier.Change ones.
+const (
+	ChangeDelete = "delete"
+	// ChangeUnverified marks objects the API server cannot validate yet, such as
+	// custom resources whose CRD or namespace is created by an earlier step.
+	ChangeUnverified = "unverified"
+)
+
 type Handler[T any] interface {
-	Namespace(ns string)
-	Op(op Op)
-	Handle(ctx context.Context, id string, in *map[string]any) (T, error)
+	Handle(ctx context.Context, id string, in *map[string]any, opts HandleOptions) (T, error)
 }
@@ -1,20 +1,58 @@
 package steps
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"maps"
+
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // Add these result types to the existing file
 
 type VarResult struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
+	// Sensitive is set when the value is masked outside of the steps applying it.
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// Redact returns res, or a copy with the sensitive values masked when it is the
+// result of a sensitive var step or of a job step with sensitive outputs, for
+// the logs, the reports and the checkpoints.
+func Redact(res any) any {
+	switch v := res.(type) {
+	case *VarResult:
+		if v != nil && v.Sensitive {
+			out := *v
+			out.Value = redact.Mask
+			return &out
+		}
+	case *JobResult:
+		if v != nil && len(v.Sensitive) > 0 {
+			out := *v
+			out.Outputs = maps.Clone(v.Outputs)
+			for _, k := range v.Sensitive {
+				out.Outputs[k] = redact.Mask
+			}
+			return &out
+		}
+	}
+	return res
 }
 
 type ObjectResult struct {
-	APIVersion string `json:"apiVersion"`
-	Kind       string `json:"kind"`
-	Name       string `json:"name"`
-	Namespace  string `json:"namespace"`
-	Operation  string `json:"operation"`
+	APIVersion      string `json:"apiVersion"`
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	Operation       string `json:"operation"`
+	UID             string `json:"uid,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Generation      int64  `json:"generation,omitempty"`
+	// Ready is true when the step waited for the object to become ready.
+	Ready bool `json:"ready,omitempty"`
+	// Change is what the step would do to the object, set by dry runs only.
+	Change string `json:"change,omitempty"`
 }
 
 type ChartResult struct {
@@ -27,4 +65,44 @@ type ChartResult struct {
 	Operation    string      `json:"operation"`
 	Revision     int         `json:"revision,omitempty"`
 	Updated      metav1.Time `json:"updated,omitempty"`
+	// Ready is true when the step waited for the objects of the release to become ready.
+	Ready bool `json:"ready,omitempty"`
+	// Change is what the step would do to the release, set by dry runs only.
+	Change string `json:"change,omitempty"`
+	// Manifest holds the rendered templates when the chart is rendered offline.
+	Manifest string `json:"-"`
+}
+
+type WaitResult struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Deleted    bool   `json:"deleted,omitempty"`
+	Waited     string `json:"waited"`
+}
+
+type JobResult struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Operation string            `json:"operation"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+	// Sensitive lists the outputs built from a sensitive value, which are masked
+	// outside of the steps applying them.
+	Sensitive []string `json:"sensitive,omitempty"`
+}
+
+type ManifestsResult struct {
+	Source    string         `json:"source"`
+	Operation string         `json:"operation"`
+	Objects   []ObjectResult `json:"objects"`
+}
+
+// SecretResult never holds the key values.
+type SecretResult struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Operation string   `json:"operation"`
+	Added     []string `json:"added,omitempty"`
+	Exported  []string `json:"exported,omitempty"`
 }
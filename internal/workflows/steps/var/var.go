@@ -8,7 +8,7 @@ import (
 	"github.com/krateoplatformops/krateoctl/internal/cache"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
-	"github.com/krateoplatformops/krateoctl/internal/expand"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -16,39 +16,27 @@ import (
 
 var _ steps.Handler[*steps.VarResult] = (*varStepHandler)(nil)
 
-func VarHandler(dyn *getter.Getter, env *cache.Cache[string, string], logger func(string, ...any)) steps.Handler[*steps.VarResult] {
+// VarHandler sets workflow variables. The values of the sensitive variables,
+// and the ones derived from them, are added to sensitive.
+func VarHandler(dyn *getter.Getter, env *cache.Cache[string, string], sensitive *redact.Values, logger func(string, ...any)) steps.Handler[*steps.VarResult] {
 	return &varStepHandler{
-		dyn:    dyn,
-		env:    env,
-		logger: logger,
-		subst: func(k string) string {
-			if v, ok := env.Get(k); ok {
-				return v
-			}
-
-			return "$" + k
-		},
+		dyn:       dyn,
+		env:       env,
+		sensitive: sensitive,
+		logger:    logger,
+		vars:      steps.EnvVars(env),
 	}
 }
 
 type varStepHandler struct {
-	dyn    *getter.Getter
-	env    *cache.Cache[string, string]
-	logger func(string, ...any)
-	ns     string
-	subst  func(k string) string
-	op     steps.Op
+	dyn       *getter.Getter
+	env       *cache.Cache[string, string]
+	sensitive *redact.Values
+	logger    func(string, ...any)
+	vars      steps.Vars
 }
 
-func (r *varStepHandler) Op(op steps.Op) {
-	r.op = op
-}
-
-func (r *varStepHandler) Namespace(ns string) {
-	r.ns = ns
-}
-
-func (r *varStepHandler) Handle(ctx context.Context, id string, ext *map[string]any) (*steps.VarResult, error) {
+func (r *varStepHandler) Handle(ctx context.Context, id string, ext *map[string]any, opts steps.HandleOptions) (*steps.VarResult, error) {
 	res := types.Var{}
 	data, err := json.Marshal(ext)
 	if err != nil {
@@ -61,17 +49,17 @@ func (r *varStepHandler) Handle(ctx context.Context, id string, ext *map[string]
 	}
 
 	result := &steps.VarResult{
-		Name: res.Name,
+		Name:      res.Name,
+		Sensitive: res.IsSensitive(),
 	}
 
 	if len(res.Value) > 0 {
-		val := expand.Expand(res.Value, "", r.subst)
-		r.env.Set(res.Name, val)
-		result.Value = val
+		val := r.vars.Expand(res.Value)
+		r.set(result, val)
 
 		r.logger(fmt.Sprintf(
 			" step (id: %s), type: var (name: %s, value: %s)",
-			id, res.Name, val))
+			id, res.Name, steps.Redact(result).(*steps.VarResult).Value))
 	} else {
 		r.logger(fmt.Sprintf(
 			" step (id: %s), type: var (name: %s) with.Value is empty", id, res.Name))
@@ -89,7 +77,7 @@ func (r *varStepHandler) Handle(ctx context.Context, id string, ext *map[string]
 
 	namespace := res.ValueFrom.Metadata.Namespace
 	if len(namespace) == 0 {
-		namespace = r.ns
+		namespace = opts.Namespace
 	}
 
 	name := res.ValueFrom.Metadata.Name
@@ -105,10 +93,22 @@ func (r *varStepHandler) Handle(ctx context.Context, id string, ext *map[string]
 
 	val, err := dynamic.Extract(ctx, obj, res.ValueFrom.Selector)
 	if val != nil {
-		valStr := steps.Strval(val)
-		r.env.Set(res.Name, valStr)
-		result.Value = valStr
+		r.set(result, steps.Strval(val))
 	}
 
 	return result, err
 }
+
+// set stores the value of the variable. A value built from a sensitive one,
+// such as a connection string embedding a password, is sensitive too.
+func (r *varStepHandler) set(result *steps.VarResult, val string) {
+	if r.sensitive != nil {
+		result.Sensitive = result.Sensitive || r.sensitive.Taints(val)
+		if result.Sensitive {
+			r.sensitive.Add(val)
+		}
+	}
+
+	r.env.Set(result.Name, val)
+	result.Value = val
+}
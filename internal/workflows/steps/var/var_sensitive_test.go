@@ -0,0 +1,49 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+)
+
+func TestVarHandlerSensitive(t *testing.T) {
+	env := cache.New[string, string]()
+	sensitive := &redact.Values{}
+	var logs []string
+	handler := VarHandler(nil, env, sensitive, func(format string, args ...any) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})
+
+	run := func(id string, with map[string]any) *steps.VarResult {
+		t.Helper()
+		res, err := handler.Handle(context.Background(), id, &with, steps.HandleOptions{})
+		if err != nil {
+			t.Fatalf("Handle(%s) error = %v", id, err)
+		}
+		return res
+	}
+
+	password := run("db-password", map[string]any{"name": "DB_PASSWORD", "value": "s3cr3t", "sensitive": true})
+	dsn := run("db-dsn", map[string]any{"name": "DB_DSN", "value": "postgres://app:${DB_PASSWORD}@db"})
+	host := run("db-host", map[string]any{"name": "DB_HOST", "value": "db"})
+
+	if !password.Sensitive || !dsn.Sensitive || host.Sensitive {
+		t.Fatalf("sensitive = %v, %v, %v, want the password and the value built from it", password.Sensitive, dsn.Sensitive, host.Sensitive)
+	}
+	if v, _ := env.Get("DB_DSN"); v != "postgres://app:s3cr3t@db" {
+		t.Fatalf("DB_DSN = %q, want the real value for the steps applying it", v)
+	}
+	if got := steps.Redact(dsn).(*steps.VarResult).Value; got != redact.Mask {
+		t.Fatalf("Redact() value = %q, want %q", got, redact.Mask)
+	}
+	for _, line := range logs {
+		if strings.Contains(line, "s3cr3t") {
+			t.Fatalf("log line %q leaks a sensitive value", line)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/krateoplatformops/krateoctl/internal/cache"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
 )
 
@@ -121,9 +122,6 @@ func TestVarStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create var handler: %v", err)
 			}
 
-			handler.Namespace(varNamespace)
-			handler.Op(steps.Create)
-
 			varJSON := `{
                 "name": "DIRECT_VALUE",
                 "value": "simple-direct-value"
@@ -134,7 +132,7 @@ func TestVarStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal var JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-direct-value", &ext)
+			result, err := handler.Handle(ctx, "test-direct-value", &ext, steps.HandleOptions{Namespace: varNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -164,8 +162,6 @@ func TestVarStepHandlerE2E(t *testing.T) {
 			env.Set("PORT", "443")
 
 			handler = createVarHandlerWithEnv(cfg, env)
-			handler.Namespace(varNamespace)
-			handler.Op(steps.Create)
 
 			varJSON := `{
                 "name": "FULL_URL",
@@ -177,7 +173,7 @@ func TestVarStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal var JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-substitution", &ext)
+			result, err := handler.Handle(ctx, "test-substitution", &ext, steps.HandleOptions{Namespace: varNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -197,9 +193,6 @@ func TestVarStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create var handler: %v", err)
 			}
 
-			handler.Namespace(varNamespace)
-			handler.Op(steps.Create)
-
 			varJSON := `{
                 "name": "DATABASE_URL",
                 "valueFrom": {
@@ -218,7 +211,7 @@ func TestVarStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal var JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-configmap", &ext)
+			result, err := handler.Handle(ctx, "test-configmap", &ext, steps.HandleOptions{Namespace: varNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -238,9 +231,6 @@ func TestVarStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create var handler: %v", err)
 			}
 
-			handler.Namespace(varNamespace)
-			handler.Op(steps.Create)
-
 			varJSON := `{
                 "name": "SECRET_PASSWORD",
                 "valueFrom": {
@@ -259,7 +249,7 @@ func TestVarStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal var JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-secret", &ext)
+			result, err := handler.Handle(ctx, "test-secret", &ext, steps.HandleOptions{Namespace: varNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -280,9 +270,6 @@ func TestVarStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create var handler: %v", err)
 			}
 
-			handler.Namespace(varNamespace)
-			handler.Op(steps.Create)
-
 			varJSON := `{
                 "name": "MISSING_VALUE",
                 "valueFrom": {
@@ -301,7 +288,7 @@ func TestVarStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal var JSON: %v", err)
 			}
-			_, err = handler.Handle(ctx, "test-missing", &ext)
+			_, err = handler.Handle(ctx, "test-missing", &ext, steps.HandleOptions{Namespace: varNamespace, Op: steps.Create})
 
 			if err == nil {
 				t.Fatal("Expected error for non-existent resource, got nil")
@@ -316,9 +303,6 @@ func TestVarStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create var handler: %v", err)
 			}
 
-			handler.Namespace(varNamespace)
-			handler.Op(steps.Create)
-
 			varJSON := `{
                 "name": "NESTED_VALUE",
                 "valueFrom": {
@@ -337,7 +321,7 @@ func TestVarStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal var JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-json-selector", &ext)
+			result, err := handler.Handle(ctx, "test-json-selector", &ext, steps.HandleOptions{Namespace: varNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -357,9 +341,6 @@ func TestVarStepHandlerE2E(t *testing.T) {
 				t.Fatalf("Failed to create var handler: %v", err)
 			}
 
-			handler.Namespace(varNamespace)
-			handler.Op(steps.Create)
-
 			// No namespace specified, should use handler's default namespace
 			varJSON := `{
                 "name": "DEFAULT_NS_VALUE",
@@ -378,7 +359,7 @@ func TestVarStepHandlerE2E(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Failed to unmarshal var JSON: %v", err)
 			}
-			result, err := handler.Handle(ctx, "test-default-ns", &ext)
+			result, err := handler.Handle(ctx, "test-default-ns", &ext, steps.HandleOptions{Namespace: varNamespace, Op: steps.Create})
 
 			if err != nil {
 				t.Fatalf("Handler failed: %v", err)
@@ -405,13 +386,13 @@ func createVarHandler(cfg *envconf.Config) (*varStepHandler, error) {
 	}
 
 	env := cache.New[string, string]()
-	handler := VarHandler(getter, env, func(string, ...any) {})
+	handler := VarHandler(getter, env, &redact.Values{}, func(string, ...any) {})
 	return handler.(*varStepHandler), nil
 }
 
 func createVarHandlerWithEnv(cfg *envconf.Config, env *cache.Cache[string, string]) *varStepHandler {
 	getter, _ := getter.NewGetter(cfg.Client().RESTConfig())
 
-	handler := VarHandler(getter, env, func(string, ...any) {})
+	handler := VarHandler(getter, env, &redact.Values{}, func(string, ...any) {})
 	return handler.(*varStepHandler)
 }
@@ -0,0 +1,58 @@
+package steps
+
+import (
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/expand"
+)
+
+// Vars resolves the $NAME and ${NAME} placeholders of the step inputs: it
+// returns the value of a variable and whether it is set.
+//
+// Every handler expands its inputs through Vars, so that the placeholders of
+// the variables that are not set are kept as written everywhere: $HOME or
+// ${VAR:-default} in a script and jq variables such as $name are left alone.
+type Vars func(name string) (string, bool)
+
+// EnvVars resolves the placeholders with the workflow variables.
+func EnvVars(env *cache.Cache[string, string]) Vars {
+	return func(name string) (string, bool) {
+		if env == nil {
+			return "", false
+		}
+		return env.Get(name)
+	}
+}
+
+// MapVars resolves the placeholders with the variables of the map.
+func MapVars(vars map[string]string) Vars {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+// Expand replaces the placeholders of s.
+func (v Vars) Expand(s string) string {
+	return expand.ExpandKnown(s, v)
+}
+
+// ExpandValues replaces, in place, the placeholders of every string found in a
+// decoded document and returns it.
+func (v Vars) ExpandValues(val any) any {
+	switch x := val.(type) {
+	case map[string]any:
+		for key, elem := range x {
+			x[key] = v.ExpandValues(elem)
+		}
+		return x
+	case []any:
+		for i, elem := range x {
+			x[i] = v.ExpandValues(elem)
+		}
+		return x
+	case string:
+		return v.Expand(x)
+	default:
+		return val
+	}
+}
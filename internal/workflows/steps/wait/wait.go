@@ -0,0 +1,173 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ steps.Handler[*steps.WaitResult] = (*waitStepHandler)(nil)
+
+type WaitHandlerOptions struct {
+	Dyn    *getter.Getter
+	Env    *cache.Cache[string, string]
+	Logger func(string, ...any)
+	// Progress is notified after every poll with a human readable status, such as the remaining time.
+	Progress func(id, message string)
+}
+
+func WaitHandler(opts WaitHandlerOptions) steps.Handler[*steps.WaitResult] {
+	hdl := &waitStepHandler{
+		env:      opts.Env,
+		logger:   opts.Logger,
+		progress: opts.Progress,
+		get: func(ctx context.Context, o getter.GetOptions) (*unstructured.Unstructured, error) {
+			return opts.Dyn.Get(ctx, o)
+		},
+	}
+	hdl.vars = steps.EnvVars(hdl.env)
+
+	return hdl
+}
+
+type waitStepHandler struct {
+	env      *cache.Cache[string, string]
+	vars     steps.Vars
+	logger   func(string, ...any)
+	progress func(id, message string)
+	get      func(context.Context, getter.GetOptions) (*unstructured.Unstructured, error)
+}
+
+// Handle polls the referenced object until the condition holds, the object is
+// deleted (forDeletion) or the timeout expires. In delete mode only waits for
+// deletion are performed, since the other conditions describe installed objects.
+func (r *waitStepHandler) Handle(ctx context.Context, id string, ext *map[string]any, opts steps.HandleOptions) (*steps.WaitResult, error) {
+	spec := types.Wait{}
+	data, err := json.Marshal(ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal wait step input: %w", err)
+	}
+
+	err = json.Unmarshal(data, &spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal wait step input: %w", err)
+	}
+	spec.SetDefaults()
+
+	gv, err := schema.ParseGroupVersion(spec.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API version: %w", err)
+	}
+
+	namespace := r.vars.Expand(spec.Metadata.Namespace)
+	if len(namespace) == 0 {
+		namespace = opts.Namespace
+	}
+
+	getOpts := getter.GetOptions{
+		GVK:       gv.WithKind(spec.Kind),
+		Namespace: namespace,
+		Name:      r.vars.Expand(spec.Metadata.Name),
+	}
+	condition := r.vars.Expand(spec.Condition)
+
+	result := &steps.WaitResult{
+		APIVersion: spec.APIVersion,
+		Kind:       spec.Kind,
+		Name:       getOpts.Name,
+		Namespace:  getOpts.Namespace,
+	}
+
+	if opts.Op == steps.Delete && !spec.ForDeletion {
+		r.logger(fmt.Sprintf("[wait:%s]: skipped in delete mode", id))
+		return result, nil
+	}
+
+	if opts.DryRun {
+		r.logger(fmt.Sprintf("[wait:%s]: skipped in dry-run mode", id))
+		return result, nil
+	}
+
+	start := time.Now()
+	deadline := start.Add(spec.Timeout.Duration)
+
+	ticker := time.NewTicker(spec.PollInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		done, reason, err := r.check(ctx, getOpts, condition, spec.ForDeletion)
+		if err != nil {
+			return result, err
+		}
+		if done {
+			result.Deleted = spec.ForDeletion
+			result.Waited = time.Since(start).Round(time.Second).String()
+			r.logger(fmt.Sprintf("[wait:%s]: %s %s/%s ready after %s", id, spec.Kind, namespace, getOpts.Name, result.Waited))
+			return result, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return result, fmt.Errorf("timed out after %s waiting for %s %s/%s: %s: %w",
+				spec.Timeout.Duration, spec.Kind, namespace, getOpts.Name, reason, context.DeadlineExceeded)
+		}
+
+		if r.progress != nil {
+			r.progress(id, fmt.Sprintf("waiting for %s %s, %s left", spec.Kind, getOpts.Name, remaining.Round(time.Second)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// check performs a single poll. It returns whether the wait is over and,
+// when it is not, the reason why.
+func (r *waitStepHandler) check(ctx context.Context, opts getter.GetOptions, condition string, forDeletion bool) (bool, string, error) {
+	obj, err := r.get(ctx, opts)
+	switch {
+	case apierrors.IsNotFound(err):
+		return forDeletion, "object not found", nil
+	case meta.IsNoMatchError(err):
+		// The CRD may not be registered yet: keep polling, or consider the object gone.
+		return forDeletion, err.Error(), nil
+	case err != nil:
+		return false, "", fmt.Errorf("failed to get object: %w", err)
+	}
+
+	if forDeletion {
+		return false, "object still exists", nil
+	}
+	if condition == "" {
+		return true, "", nil
+	}
+
+	val, err := dynamic.Extract(ctx, obj, condition)
+	if errors.Is(err, io.EOF) {
+		return false, fmt.Sprintf("condition %q produced no value", condition), nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("failed to evaluate condition %q: %w", condition, err)
+	}
+
+	if !dynamic.Truthy(val) {
+		return false, fmt.Sprintf("condition %q is %v", condition, val), nil
+	}
+	return true, "", nil
+}
@@ -0,0 +1,144 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/cache"
+	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// sequenceGetter returns the given objects in order, repeating the last one.
+// A nil object is reported as not found.
+func sequenceGetter(objs ...map[string]any) (func(context.Context, getter.GetOptions) (*unstructured.Unstructured, error), *int) {
+	calls := 0
+	return func(_ context.Context, opts getter.GetOptions) (*unstructured.Unstructured, error) {
+		obj := objs[min(calls, len(objs)-1)]
+		calls++
+		if obj == nil {
+			return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, opts.Name)
+		}
+		return &unstructured.Unstructured{Object: obj}, nil
+	}, &calls
+}
+
+func newTestHandler(get func(context.Context, getter.GetOptions) (*unstructured.Unstructured, error)) *waitStepHandler {
+	env := cache.New[string, string]()
+	env.Set("SVC", "frontend")
+
+	hdl := &waitStepHandler{env: env, logger: func(string, ...any) {}, get: get}
+	hdl.vars = steps.EnvVars(env)
+	return hdl
+}
+
+func TestWaitHandler(t *testing.T) {
+	pending := map[string]any{"status": map[string]any{"loadBalancer": map[string]any{}}}
+	ready := map[string]any{"status": map[string]any{"loadBalancer": map[string]any{
+		"ingress": []any{map[string]any{"ip": "10.0.0.1"}},
+	}}}
+
+	tests := []struct {
+		name      string
+		with      map[string]any
+		objs      []map[string]any
+		op        steps.Op
+		wantCalls int
+		wantErr   string
+	}{
+		{
+			name: "condition becomes true",
+			with: map[string]any{
+				"condition": ".status.loadBalancer.ingress[0].ip",
+			},
+			objs:      []map[string]any{nil, pending, ready},
+			wantCalls: 3,
+		},
+		{
+			name:      "waits for the object to exist without condition",
+			with:      map[string]any{},
+			objs:      []map[string]any{nil, pending},
+			wantCalls: 2,
+		},
+		{
+			name: "waits for deletion",
+			with: map[string]any{
+				"forDeletion": true,
+			},
+			objs:      []map[string]any{ready, ready, nil},
+			wantCalls: 3,
+		},
+		{
+			name: "times out",
+			with: map[string]any{
+				"condition": ".status.loadBalancer.ingress[0].ip",
+				"timeout":   "20ms",
+			},
+			objs:    []map[string]any{pending},
+			wantErr: "timed out after 20ms",
+		},
+		{
+			name: "skips condition waits in delete mode",
+			with: map[string]any{
+				"condition": ".status.loadBalancer.ingress[0].ip",
+			},
+			objs:      []map[string]any{pending},
+			op:        steps.Delete,
+			wantCalls: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			get, calls := sequenceGetter(tc.objs...)
+			hdl := newTestHandler(get)
+
+			var progress []string
+			hdl.progress = func(_ string, msg string) { progress = append(progress, msg) }
+
+			with := map[string]any{
+				"apiVersion":   "v1",
+				"kind":         "Service",
+				"metadata":     map[string]any{"name": "${SVC}"},
+				"pollInterval": "1ms",
+			}
+			for k, v := range tc.with {
+				with[k] = v
+			}
+
+			op := tc.op
+			if op == 0 {
+				op = steps.Create
+			}
+
+			res, err := hdl.Handle(context.Background(), "wait-lb", &with, steps.HandleOptions{Namespace: "krateo-system", Op: op})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Handle() error = %v, want %q", err, tc.wantErr)
+				}
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("Handle() error = %v, want it to wrap context.DeadlineExceeded", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			if *calls != tc.wantCalls {
+				t.Fatalf("get calls = %d, want %d", *calls, tc.wantCalls)
+			}
+			if res.Name != "frontend" || res.Namespace != "krateo-system" {
+				t.Fatalf("Handle() = %+v, want frontend in krateo-system", res)
+			}
+			if tc.wantCalls > 1 && len(progress) != tc.wantCalls-1 {
+				t.Fatalf("progress updates = %v, want %d", progress, tc.wantCalls-1)
+			}
+		})
+	}
+}
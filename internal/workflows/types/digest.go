@@ -0,0 +1,28 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Digest returns a stable fingerprint of the step configuration (id, type and with).
+// Two steps with the same digest perform the same operation.
+func Digest(step *Step) (string, error) {
+	if step == nil {
+		return "", fmt.Errorf("step is nil")
+	}
+
+	data, err := json.Marshal(struct {
+		ID   string          `json:"id"`
+		Type StepType        `json:"type"`
+		With *map[string]any `json:"with,omitempty"`
+	}{step.ID, step.Type, step.With})
+	if err != nil {
+		return "", fmt.Errorf("marshal step %s: %w", step.ID, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
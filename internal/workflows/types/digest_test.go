@@ -0,0 +1,23 @@
+package types
+
+import "testing"
+
+func TestDigest(t *testing.T) {
+	step := func(version string, deps ...string) *Step {
+		return &Step{ID: "backend", Type: TypeChart, With: &map[string]any{"version": version}, DependsOn: deps}
+	}
+
+	a, err := Digest(step("1.0.0"))
+	if err != nil {
+		t.Fatalf("Digest() error = %v", err)
+	}
+	b, _ := Digest(step("1.0.0", "crds"))
+	c, _ := Digest(step("1.1.0"))
+
+	if a != b {
+		t.Fatalf("Digest() changed with dependsOn: %s != %s", a, b)
+	}
+	if a == c {
+		t.Fatalf("Digest() did not change with the step configuration")
+	}
+}
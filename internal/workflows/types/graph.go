@@ -0,0 +1,198 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DependencyError reports an invalid dependsOn declaration on a step.
+type DependencyError struct {
+	StepID string
+	Reason string
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("step %q: %s", e.StepID, e.Reason)
+}
+
+// HasDependencies reports whether at least one step declares dependsOn.
+func HasDependencies(list []*Step) bool {
+	for _, step := range list {
+		if step != nil && len(step.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Subset returns copies of the steps for which keep is true, in the same order.
+// Their dependsOn entries are restricted to the kept steps, so that the subset
+// can run as a workflow on its own.
+func Subset(list []*Step, keep func(*Step) bool) []*Step {
+	var out []*Step
+	kept := make(map[string]bool, len(list))
+	for _, step := range list {
+		if keep(step) {
+			out = append(out, step)
+			kept[step.ID] = true
+		}
+	}
+
+	for i, step := range out {
+		c := *step
+		c.DependsOn = slices.DeleteFunc(slices.Clone(step.DependsOn), func(id string) bool {
+			return !kept[id]
+		})
+		out[i] = &c
+	}
+	return out
+}
+
+// ResolveDependencies returns, for each step, the indexes of the steps it must wait for.
+//
+// A step without dependsOn implicitly depends on the previous one, so the steps
+// that do not declare dependsOn keep their historical sequential order, even
+// when other steps do. A step with dependsOn waits for those steps only and may
+// run concurrently with the steps declared before it.
+//
+// With reverse set (delete mode) the dependsOn edges are inverted so that
+// dependents are processed before the steps they depend on. The list is then
+// expected in reverse order, so that the implicit edges are inverted too.
+func ResolveDependencies(list []*Step, reverse bool) ([][]int, error) {
+	deps := make([][]int, len(list))
+
+	if !HasDependencies(list) {
+		for i := 1; i < len(list); i++ {
+			deps[i] = []int{i - 1}
+		}
+		return deps, nil
+	}
+
+	index := make(map[string]int, len(list))
+	for i, step := range list {
+		if _, ok := index[step.ID]; ok {
+			return nil, &DependencyError{StepID: step.ID, Reason: "duplicate step id"}
+		}
+		index[step.ID] = i
+	}
+
+	for i := 1; i < len(list); i++ {
+		// The step declared last of the two, list[i-1] when the list is reversed.
+		later := list[i]
+		if reverse {
+			later = list[i-1]
+		}
+		if len(later.DependsOn) == 0 {
+			deps[i] = append(deps[i], i-1)
+		}
+	}
+
+	for i, step := range list {
+		seen := make(map[string]bool, len(step.DependsOn))
+		for _, dep := range step.DependsOn {
+			if dep == step.ID {
+				return nil, &DependencyError{StepID: step.ID, Reason: "step cannot depend on itself"}
+			}
+			j, ok := index[dep]
+			if !ok {
+				return nil, &DependencyError{StepID: step.ID, Reason: fmt.Sprintf("depends on unknown step %q", dep)}
+			}
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+
+			if reverse {
+				deps[j] = append(deps[j], i)
+			} else {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+
+	if cycle := findCycle(list, deps); len(cycle) > 0 {
+		return nil, &DependencyError{
+			StepID: cycle[0],
+			Reason: fmt.Sprintf("dependency cycle detected: %s", strings.Join(cycle, " -> ")),
+		}
+	}
+
+	return deps, nil
+}
+
+// findCycle walks the dependency edges, explicit and implicit, and returns the
+// first cycle found as a list of step IDs, starting and ending with the same step.
+func findCycle(list []*Step, deps [][]int) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(list))
+	path := make([]int, 0, len(list))
+
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		state[i] = visiting
+		path = append(path, i)
+
+		for _, j := range deps[i] {
+			switch state[j] {
+			case visiting:
+				var cycle []string
+				for _, k := range path[slices.Index(path, j):] {
+					cycle = append(cycle, list[k].ID)
+				}
+				return append(cycle, list[j].ID)
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range list {
+		if state[i] != unvisited {
+			continue
+		}
+		if cycle := visit(i); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// Branch returns the chain of explicit dependencies leading to the given step,
+// following the first declared dependency at every hop. The last element is the step itself.
+func Branch(list []*Step, id string) []string {
+	index := make(map[string]int, len(list))
+	for i, step := range list {
+		if _, ok := index[step.ID]; !ok {
+			index[step.ID] = i
+		}
+	}
+
+	var branch []string
+	seen := make(map[string]bool)
+	for cur := id; cur != "" && !seen[cur]; {
+		seen[cur] = true
+		branch = append(branch, cur)
+
+		i, ok := index[cur]
+		if !ok || len(list[i].DependsOn) == 0 {
+			break
+		}
+		cur = list[i].DependsOn[0]
+	}
+
+	slices.Reverse(branch)
+	return branch
+}
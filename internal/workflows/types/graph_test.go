@@ -0,0 +1,155 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []*Step
+		reverse bool
+		want    [][]int
+	}{
+		{
+			name:  "sequential when no dependsOn is declared",
+			steps: []*Step{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+			want:  [][]int{nil, {0}, {1}},
+		},
+		{
+			name: "explicit dependencies",
+			steps: []*Step{
+				{ID: "a"},
+				{ID: "b"},
+				{ID: "c", DependsOn: []string{"a", "b", "a"}},
+			},
+			want: [][]int{nil, {0}, {0, 1}},
+		},
+		{
+			name: "steps without dependsOn keep waiting for the previous step",
+			steps: []*Step{
+				{ID: "a"},
+				{ID: "b", DependsOn: []string{"a"}},
+				{ID: "c"},
+				{ID: "d", DependsOn: []string{"a"}},
+			},
+			want: [][]int{nil, {0}, {1}, {0}},
+		},
+		{
+			name: "reverse inverts the edges of the reversed list",
+			steps: []*Step{
+				{ID: "c", DependsOn: []string{"a"}},
+				{ID: "b", DependsOn: []string{"a"}},
+				{ID: "a"},
+			},
+			reverse: true,
+			want:    [][]int{nil, nil, {0, 1}},
+		},
+		{
+			name: "reverse inverts the implicit edges",
+			steps: []*Step{
+				{ID: "d", DependsOn: []string{"a"}},
+				{ID: "c"},
+				{ID: "b", DependsOn: []string{"a"}},
+				{ID: "a"},
+			},
+			reverse: true,
+			want:    [][]int{nil, nil, {1}, {0, 2}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ResolveDependencies(tc.steps, tc.reverse)
+			if err != nil {
+				t.Fatalf("ResolveDependencies() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("ResolveDependencies() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveDependenciesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		steps  []*Step
+		stepID string
+		reason string
+	}{
+		{
+			name:   "unknown dependency",
+			steps:  []*Step{{ID: "a", DependsOn: []string{"missing"}}},
+			stepID: "a",
+			reason: `depends on unknown step "missing"`,
+		},
+		{
+			name:   "self dependency",
+			steps:  []*Step{{ID: "a", DependsOn: []string{"a"}}},
+			stepID: "a",
+			reason: "step cannot depend on itself",
+		},
+		{
+			name:   "duplicate id",
+			steps:  []*Step{{ID: "a"}, {ID: "a", DependsOn: []string{"a"}}},
+			stepID: "a",
+			reason: "duplicate step id",
+		},
+		{
+			name: "cycle through an implicit dependency",
+			steps: []*Step{
+				{ID: "a", DependsOn: []string{"c"}},
+				{ID: "b"},
+				{ID: "c"},
+			},
+			stepID: "a",
+			reason: "dependency cycle detected: a -> c -> b -> a",
+		},
+		{
+			name: "cycle",
+			steps: []*Step{
+				{ID: "a", DependsOn: []string{"c"}},
+				{ID: "b", DependsOn: []string{"a"}},
+				{ID: "c", DependsOn: []string{"b"}},
+			},
+			stepID: "a",
+			reason: "dependency cycle detected: a -> c -> b -> a",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ResolveDependencies(tc.steps, false)
+			var depErr *DependencyError
+			if !errors.As(err, &depErr) {
+				t.Fatalf("ResolveDependencies() error = %v, want *DependencyError", err)
+			}
+			if depErr.StepID != tc.stepID {
+				t.Fatalf("StepID = %q, want %q", depErr.StepID, tc.stepID)
+			}
+			if !strings.Contains(depErr.Reason, tc.reason) {
+				t.Fatalf("Reason = %q, want %q", depErr.Reason, tc.reason)
+			}
+		})
+	}
+}
+
+func TestBranch(t *testing.T) {
+	list := []*Step{
+		{ID: "crds"},
+		{ID: "db", DependsOn: []string{"crds"}},
+		{ID: "backend", DependsOn: []string{"db", "crds"}},
+		{ID: "frontend"},
+	}
+
+	if got, want := Branch(list, "backend"), []string{"crds", "db", "backend"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Branch() = %v, want %v", got, want)
+	}
+	if got, want := Branch(list, "frontend"), []string{"frontend"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Branch() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/itchyny/gojq"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultReadyTimeout is how long a step waits for its objects to become ready.
+const DefaultReadyTimeout = 5 * time.Minute
+
+// Readiness makes an object or chart step wait, after the apply, until its
+// objects are ready according to the health checks.
+type Readiness struct {
+	WaitForReady bool `json:"waitForReady,omitempty" yaml:"waitForReady,omitempty"`
+	// ReadyTimeout is the maximum time to wait for readiness. Defaults to 5m.
+	ReadyTimeout *metav1.Duration `json:"readyTimeout,omitempty" yaml:"readyTimeout,omitempty"`
+}
+
+// Timeout returns the configured ready timeout or the default one.
+func (r Readiness) Timeout() time.Duration {
+	if r.ReadyTimeout == nil || r.ReadyTimeout.Duration <= 0 {
+		return DefaultReadyTimeout
+	}
+	return r.ReadyTimeout.Duration
+}
+
+// HealthCheck is a user-defined readiness rule for a kind. The jq expression
+// runs against the object and the object is ready when it yields a value other
+// than false or null. It replaces the built-in rule of the kind, if any.
+type HealthCheck struct {
+	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string `json:"kind" yaml:"kind"`
+	Expression string `json:"expression" yaml:"expression"`
+}
+
+// Validate checks that the kind is set and that the expression parses.
+func (h HealthCheck) Validate() error {
+	if h.APIVersion == "" || h.Kind == "" {
+		return fmt.Errorf("apiVersion and kind are required")
+	}
+	if h.Expression == "" {
+		return fmt.Errorf("expression is required")
+	}
+	if _, err := gojq.Parse(h.Expression); err != nil {
+		return fmt.Errorf("invalid expression %q: %w", h.Expression, err)
+	}
+	return nil
+}
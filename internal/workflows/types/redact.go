@@ -0,0 +1,74 @@
+package types
+
+import (
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+)
+
+// RedactSteps returns a copy of list where the sensitive literals of the step
+// configurations are masked, for the plans and the installation snapshots:
+//
+//   - the value of the var steps marked sensitive
+//   - the data and stringData of the object steps creating a Secret
+//   - the literal values of the secret step keys
+//
+// The ${VAR} placeholders are kept, their values are never part of a step.
+func RedactSteps(list []*Step) []*Step {
+	if list == nil {
+		return nil
+	}
+
+	out := make([]*Step, len(list))
+	for i, step := range list {
+		out[i] = redactStep(step)
+	}
+	return out
+}
+
+func redactStep(step *Step) *Step {
+	if step == nil || step.With == nil {
+		return step
+	}
+
+	with := copyValue(*step.With).(map[string]any)
+	switch step.Type {
+	case TypeVar:
+		sensitive, _ := with["sensitive"].(bool)
+		if value, _ := with["value"].(string); sensitive && value != "" {
+			with["value"] = redact.Mask
+		}
+	case TypeObject:
+		with = redact.Secret(with)
+	case TypeSecret:
+		keys, _ := with["keys"].(map[string]any)
+		for _, key := range keys {
+			key, _ := key.(map[string]any)
+			if value, _ := key["value"].(string); value != "" {
+				key["value"] = redact.Mask
+			}
+		}
+	}
+
+	out := *step
+	out.With = &with
+	return &out
+}
+
+// copyValue deep copies the maps and slices of a decoded configuration.
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = copyValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = copyValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
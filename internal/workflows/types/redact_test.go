@@ -0,0 +1,58 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/krateoplatformops/krateoctl/internal/redact"
+)
+
+func TestRedactSteps(t *testing.T) {
+	list := []*Step{
+		{ID: "db-password", Type: TypeVar, With: &map[string]any{"name": "DB_PASSWORD", "value": "s3cr3t", "sensitive": true}},
+		{ID: "registry", Type: TypeVar, With: &map[string]any{"name": "REGISTRY", "value": "ghcr.io"}},
+		{ID: "db-secret", Type: TypeObject, With: &map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"stringData": map[string]any{"password": "${DB_PASSWORD}", "user": "app"},
+		}},
+		{ID: "jwt", Type: TypeSecret, With: &map[string]any{
+			"name": "jwt",
+			"keys": map[string]any{
+				"sign-key": map[string]any{"value": "literal"},
+				"aes-key":  map[string]any{"generate": SecretGenerateBase64Key},
+			},
+		}},
+	}
+
+	got := RedactSteps(list)
+
+	want := []map[string]any{
+		{"name": "DB_PASSWORD", "value": redact.Mask, "sensitive": true},
+		{"name": "REGISTRY", "value": "ghcr.io"},
+		{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"stringData": map[string]any{"password": redact.Mask, "user": redact.Mask},
+		},
+		{
+			"name": "jwt",
+			"keys": map[string]any{
+				"sign-key": map[string]any{"value": redact.Mask},
+				"aes-key":  map[string]any{"generate": SecretGenerateBase64Key},
+			},
+		},
+	}
+	for i, step := range got {
+		if !reflect.DeepEqual(*step.With, want[i]) {
+			t.Errorf("step %s with = %v, want %v", step.ID, *step.With, want[i])
+		}
+	}
+
+	if value := (*list[0].With)["value"]; value != "s3cr3t" {
+		t.Fatalf("RedactSteps() changed the original step, value = %v", value)
+	}
+	if keys := (*list[3].With)["keys"].(map[string]any); keys["sign-key"].(map[string]any)["value"] != "literal" {
+		t.Fatalf("RedactSteps() changed the original secret keys: %v", keys)
+	}
+}
@@ -0,0 +1,139 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryCondition names a class of errors that makes a step eligible for a retry.
+type RetryCondition string
+
+const (
+	// RetryOnConflict matches optimistic concurrency conflicts (HTTP 409).
+	RetryOnConflict RetryCondition = "conflict"
+	// RetryOnTimeout matches client and server timeouts, throttling and unavailable API servers.
+	RetryOnTimeout RetryCondition = "timeout"
+	// RetryOnWebhook matches admission webhooks that cannot be reached yet.
+	RetryOnWebhook RetryCondition = "webhook"
+	// RetryOnNotFound matches missing objects and kinds whose CRD is not registered yet.
+	RetryOnNotFound RetryCondition = "notFound"
+)
+
+const (
+	DefaultRetryBackoff    = time.Second
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy controls how a failed step is retried.
+//
+// The delay between attempts starts at Backoff and doubles after every
+// failure, up to MaxBackoff. When RetryOn is empty every error is retried.
+type RetryPolicy struct {
+	Attempts   int              `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	Backoff    string           `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	MaxBackoff string           `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`
+	RetryOn    []RetryCondition `json:"retryOn,omitempty" yaml:"retryOn,omitempty"`
+}
+
+// Validate checks the attempts count, the durations and the retry conditions.
+func (p *RetryPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if p.Attempts < 0 {
+		return fmt.Errorf("attempts must not be negative, got %d", p.Attempts)
+	}
+
+	if err := validateDuration("backoff", p.Backoff); err != nil {
+		return err
+	}
+	if err := validateDuration("maxBackoff", p.MaxBackoff); err != nil {
+		return err
+	}
+
+	for _, cond := range p.RetryOn {
+		switch cond {
+		case RetryOnConflict, RetryOnTimeout, RetryOnWebhook, RetryOnNotFound:
+		default:
+			return fmt.Errorf("unknown retryOn condition %q (supported: conflict, timeout, webhook, notFound)", cond)
+		}
+	}
+
+	return nil
+}
+
+// MaxAttempts returns the total number of attempts, at least one.
+func (p *RetryPolicy) MaxAttempts() int {
+	if p == nil || p.Attempts < 1 {
+		return 1
+	}
+	return p.Attempts
+}
+
+// Delay returns how long to wait after the given failed attempt (1-based).
+// Invalid durations fall back to the defaults; Validate reports them.
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	backoff, maxBackoff := DefaultRetryBackoff, DefaultRetryMaxBackoff
+	if p != nil {
+		if d, err := time.ParseDuration(p.Backoff); err == nil {
+			backoff = d
+		}
+		if d, err := time.ParseDuration(p.MaxBackoff); err == nil {
+			maxBackoff = d
+		}
+	}
+
+	delay := backoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// Merge returns a copy of p where every unset field is taken from defaults.
+func (p *RetryPolicy) Merge(defaults *RetryPolicy) *RetryPolicy {
+	switch {
+	case p == nil && defaults == nil:
+		return nil
+	case p == nil:
+		out := *defaults
+		out.RetryOn = append([]RetryCondition(nil), defaults.RetryOn...)
+		return &out
+	}
+
+	out := *p
+	out.RetryOn = append([]RetryCondition(nil), p.RetryOn...)
+	if defaults == nil {
+		return &out
+	}
+
+	if out.Attempts == 0 {
+		out.Attempts = defaults.Attempts
+	}
+	if out.Backoff == "" {
+		out.Backoff = defaults.Backoff
+	}
+	if out.MaxBackoff == "" {
+		out.MaxBackoff = defaults.MaxBackoff
+	}
+	if len(out.RetryOn) == 0 {
+		out.RetryOn = append(out.RetryOn, defaults.RetryOn...)
+	}
+	return &out
+}
+
+func validateDuration(field, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", field, value, err)
+	}
+	if d < 0 {
+		return fmt.Errorf("%s must not be negative, got %q", field, value)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{Backoff: "1s", MaxBackoff: "5s"}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := policy.Delay(i + 1); got != w {
+			t.Fatalf("Delay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+
+	var empty *RetryPolicy
+	if got := empty.Delay(1); got != DefaultRetryBackoff {
+		t.Fatalf("Delay() on nil policy = %s, want %s", got, DefaultRetryBackoff)
+	}
+	if got := empty.MaxAttempts(); got != 1 {
+		t.Fatalf("MaxAttempts() on nil policy = %d, want 1", got)
+	}
+}
+
+func TestRetryPolicyMerge(t *testing.T) {
+	defaults := &RetryPolicy{Attempts: 3, Backoff: "2s", RetryOn: []RetryCondition{RetryOnWebhook}}
+
+	tests := []struct {
+		name     string
+		policy   *RetryPolicy
+		defaults *RetryPolicy
+		want     *RetryPolicy
+	}{
+		{name: "both nil"},
+		{
+			name:     "defaults only",
+			defaults: defaults,
+			want:     &RetryPolicy{Attempts: 3, Backoff: "2s", RetryOn: []RetryCondition{RetryOnWebhook}},
+		},
+		{
+			name:     "step fields win",
+			policy:   &RetryPolicy{Attempts: 5, MaxBackoff: "10s"},
+			defaults: defaults,
+			want:     &RetryPolicy{Attempts: 5, Backoff: "2s", MaxBackoff: "10s", RetryOn: []RetryCondition{RetryOnWebhook}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.Merge(tc.defaults); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Merge() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *RetryPolicy
+		wantErr bool
+	}{
+		{name: "nil policy"},
+		{name: "valid", policy: &RetryPolicy{Attempts: 3, Backoff: "500ms", MaxBackoff: "1m", RetryOn: []RetryCondition{RetryOnConflict, RetryOnNotFound}}},
+		{name: "negative attempts", policy: &RetryPolicy{Attempts: -1}, wantErr: true},
+		{name: "invalid backoff", policy: &RetryPolicy{Backoff: "soon"}, wantErr: true},
+		{name: "unknown condition", policy: &RetryPolicy{RetryOn: []RetryCondition{"always"}}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.policy.Validate(); (err != nil) != tc.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// SecretGeneratePassword generates an alphanumeric password.
+	SecretGeneratePassword = "password"
+	// SecretGenerateBase64Key generates random bytes encoded in base64.
+	SecretGenerateBase64Key = "base64key"
+
+	// DefaultPasswordLength is the length of generated passwords.
+	DefaultPasswordLength = 16
+	// DefaultBase64KeyLength is the number of random bytes of generated keys.
+	DefaultBase64KeyLength = 32
+)
+
+// SecretStep creates a Secret, or adds the missing keys to an existing one.
+// Keys already present in the cluster are never overwritten.
+type SecretStep struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Type of a new Secret, defaults to Opaque.
+	Type string               `json:"type,omitempty"`
+	Keys map[string]SecretKey `json:"keys"`
+	// Constraints require two keys to hold the same value, in the form
+	// "<secret>.<key> == <secret>.<key>". Missing generated keys take the
+	// value of the other side when it exists.
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// SecretKey describes how the value of a missing key is obtained; exactly one
+// of Generate, Value and FromSecret must be set.
+type SecretKey struct {
+	// Generate is either password or base64key.
+	Generate string `json:"generate,omitempty"`
+	// Length of the password, or number of random bytes of the base64 key.
+	Length     int           `json:"length,omitempty"`
+	Value      string        `json:"value,omitempty"`
+	FromSecret *SecretKeyRef `json:"fromSecret,omitempty"`
+	// Export is the name of the workflow variable holding the key value.
+	// Defaults to the upper-cased secret name and key joined by an underscore.
+	Export string `json:"export,omitempty"`
+}
+
+// SecretKeyRef selects a key of another Secret.
+type SecretKeyRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Key       string `json:"key"`
+}
+
+// SecretKeyPath identifies a key of a Secret in the step namespace.
+type SecretKeyPath struct {
+	Secret string
+	Key    string
+}
+
+func (p SecretKeyPath) String() string {
+	return p.Secret + "." + p.Key
+}
+
+// SecretConstraint requires two keys to hold the same value.
+type SecretConstraint struct {
+	Left  SecretKeyPath
+	Right SecretKeyPath
+}
+
+func (c SecretConstraint) String() string {
+	return c.Left.String() + " == " + c.Right.String()
+}
+
+// ParseSecretConstraint parses an expression such as "krateo-db.DB_PASS == krateo-db-user.password".
+// The secret name is everything before the last dot, since names may contain dots.
+func ParseSecretConstraint(expr string) (SecretConstraint, error) {
+	left, right, ok := strings.Cut(expr, "==")
+	if !ok {
+		return SecretConstraint{}, fmt.Errorf("invalid constraint %q: expected <secret>.<key> == <secret>.<key>", expr)
+	}
+
+	l, err := parseSecretKeyPath(left)
+	if err != nil {
+		return SecretConstraint{}, fmt.Errorf("invalid constraint %q: %w", expr, err)
+	}
+	r, err := parseSecretKeyPath(right)
+	if err != nil {
+		return SecretConstraint{}, fmt.Errorf("invalid constraint %q: %w", expr, err)
+	}
+
+	return SecretConstraint{Left: l, Right: r}, nil
+}
+
+func parseSecretKeyPath(s string) (SecretKeyPath, error) {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndex(s, ".")
+	if i <= 0 || i == len(s)-1 {
+		return SecretKeyPath{}, fmt.Errorf("%q is not in the form <secret>.<key>", s)
+	}
+	return SecretKeyPath{Secret: s[:i], Key: s[i+1:]}, nil
+}
+
+// Validate checks the step definition.
+func (s *SecretStep) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(s.Keys) == 0 {
+		return fmt.Errorf("at least one key is required")
+	}
+
+	for _, name := range s.KeyNames() {
+		key := s.Keys[name]
+		sources := 0
+		if key.Generate != "" {
+			sources++
+		}
+		if key.Value != "" {
+			sources++
+		}
+		if key.FromSecret != nil {
+			sources++
+		}
+		if sources != 1 {
+			return fmt.Errorf("key %s: exactly one of generate, value or fromSecret must be set", name)
+		}
+
+		switch key.Generate {
+		case "", SecretGeneratePassword, SecretGenerateBase64Key:
+		default:
+			return fmt.Errorf("key %s: unsupported generate %q (supported: %s, %s)",
+				name, key.Generate, SecretGeneratePassword, SecretGenerateBase64Key)
+		}
+		if key.Length < 0 {
+			return fmt.Errorf("key %s: length must not be negative", name)
+		}
+		if ref := key.FromSecret; ref != nil && (ref.Name == "" || ref.Key == "") {
+			return fmt.Errorf("key %s: fromSecret requires name and key", name)
+		}
+	}
+
+	for _, expr := range s.Constraints {
+		c, err := ParseSecretConstraint(expr)
+		if err != nil {
+			return err
+		}
+		if c.Left.Secret != s.Name && c.Right.Secret != s.Name {
+			return fmt.Errorf("constraint %q does not reference secret %s", expr, s.Name)
+		}
+	}
+
+	return nil
+}
+
+// KeyNames returns the declared keys in lexical order.
+func (s *SecretStep) KeyNames() []string {
+	names := make([]string, 0, len(s.Keys))
+	for name := range s.Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var invalidVarChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// ExportName returns the workflow variable set to the value of the given key.
+func (s *SecretStep) ExportName(key string) string {
+	if name := s.Keys[key].Export; name != "" {
+		return name
+	}
+	return strings.ToUpper(invalidVarChars.ReplaceAllString(s.Name+"_"+key, "_"))
+}
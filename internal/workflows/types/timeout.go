@@ -0,0 +1,33 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// ValidateTimeout checks that a step timeout is a positive duration. An empty
+// timeout means that the step is not bounded.
+func ValidateTimeout(timeout string) error {
+	if timeout == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout %q: %w", timeout, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("timeout must be positive, got %q", timeout)
+	}
+	return nil
+}
+
+// AttemptTimeout returns the time allowed to every attempt of the step, zero
+// when it is not bounded. Invalid timeouts are reported by ValidateTimeout.
+func (s *Step) AttemptTimeout() time.Duration {
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -31,6 +32,14 @@ type ValueFromSource struct {
 type Var struct {
 	Data      `json:",inline"`
 	ValueFrom *ValueFromSource `json:"valueFrom,omitempty"`
+	// Sensitive masks the value in logs, reports, plans and snapshots. Values
+	// read from a Secret are always sensitive.
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// IsSensitive reports whether the value of the variable must be masked.
+func (v *Var) IsSensitive() bool {
+	return v.Sensitive || (v.ValueFrom != nil && v.ValueFrom.Kind == "Secret")
 }
 
 type Credentials struct {
@@ -58,6 +67,14 @@ type ChartSpec struct {
 	// Wait for the release to become ready.
 	Wait bool `json:"wait,omitempty"`
 
+	// Readiness waits, after the install or upgrade, until every object of the release
+	// is ready according to the health checks, custom resources included.
+	Readiness `json:",inline" yaml:",inline"`
+
+	// Atomic rolls a failed upgrade back to the previous revision and uninstalls a failed
+	// install. It implies Wait. When not set, the workflow-level default (--atomic) applies.
+	Atomic *bool `json:"atomic,omitempty"`
+
 	// Timeout is the time to wait for any individual kubernetes operation (like Jobs for hooks) to complete. Defaults to 5m.
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
 
@@ -67,8 +84,67 @@ type ChartSpec struct {
 	// Values defines the Helm values
 	Values map[string]any `json:"values,omitempty" yaml:"values,omitempty"`
 
+	// ValuesFrom lists external values sources, merged in declared order before Values.
+	// They are resolved at apply time, so only the references are stored in krateo.yaml and in the snapshot.
+	ValuesFrom []ValuesFromSource `json:"valuesFrom,omitempty" yaml:"valuesFrom,omitempty"`
+
 	// InsecureSkipTLSVerify skips tls certificate checks for the chart download
 	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// Credentials authenticate the chart download from a private Helm repository or OCI registry.
+	Credentials *Credentials `json:"credentials,omitempty"`
+
+	// TLS configures the CA bundle and the client certificate used for the chart download.
+	TLS *ChartTLS `json:"tls,omitempty"`
+}
+
+// ValuesFromSource locates Helm values outside of krateo.yaml; exactly one of
+// File, ConfigMapKeyRef or SecretKeyRef must be set.
+type ValuesFromSource struct {
+	// File is the path of a YAML values file.
+	File string `json:"file,omitempty"`
+	// ConfigMapKeyRef selects a ConfigMap key; its namespace defaults to the release namespace.
+	ConfigMapKeyRef *rtv1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// SecretKeyRef selects a Secret key; its namespace defaults to the release namespace.
+	SecretKeyRef *rtv1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+	// TargetPath is a dot separated path, such as auth.oidc.clientSecret, where the
+	// content is set as a string. When empty the content is parsed as a YAML values document.
+	TargetPath string `json:"targetPath,omitempty"`
+}
+
+// Validate checks that exactly one source is configured.
+func (s ValuesFromSource) Validate() error {
+	count := 0
+	if s.File != "" {
+		count++
+	}
+	if s.ConfigMapKeyRef != nil {
+		count++
+	}
+	if s.SecretKeyRef != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("exactly one of file, configMapKeyRef or secretKeyRef must be set")
+	}
+	return nil
+}
+
+// ChartTLS holds the paths of the PEM files used to reach a chart repository or registry.
+type ChartTLS struct {
+	// CAFile is a CA bundle used to verify the server certificate, in addition to the system roots.
+	CAFile string `json:"caFile,omitempty"`
+	// CertFile and KeyFile are the client certificate and key presented to the server.
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+}
+
+// Validate checks that the client certificate and key are set together.
+func (t *ChartTLS) Validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("tls.certFile and tls.keyFile must be set together")
+	}
+	return nil
 }
 
 // SetDefaults applies default values to optional fields.
@@ -98,6 +174,8 @@ type ChartObservation struct {
 
 type Object struct {
 	ObjectMeta `json:",inline" yaml:",inline"`
+	// Readiness options belong to the step and are not part of the applied object.
+	Readiness  `json:",inline" yaml:",inline"`
 	BodyFields map[string]any `json:"-" yaml:"-"`
 }
 
@@ -105,8 +183,10 @@ type Object struct {
 func (o *Object) extractBodyFields(raw map[string]any) {
 	o.BodyFields = make(map[string]any)
 	for k, v := range raw {
-		// Skip keys that are part of the core ObjectMeta structure
-		if k != "apiVersion" && k != "kind" && k != "metadata" {
+		switch k {
+		// Skip keys that are part of the core ObjectMeta structure and the step options
+		case "apiVersion", "kind", "metadata", "waitForReady", "readyTimeout":
+		default:
 			o.BodyFields[k] = v
 		}
 	}
@@ -125,6 +205,12 @@ func (o *Object) UnmarshalJSON(data []byte) error {
 	}
 	o.ObjectMeta = om
 
+	var ready Readiness
+	if err := json.Unmarshal(data, &ready); err != nil {
+		return err
+	}
+	o.Readiness = ready
+
 	o.extractBodyFields(raw)
 	return nil
 }
@@ -144,16 +230,138 @@ func (o *Object) UnmarshalYAML(node *yaml.Node) error {
 	}
 	o.ObjectMeta = om
 
+	var ready Readiness
+	if err := node.Decode(&ready); err != nil {
+		return err
+	}
+	o.Readiness = ready
+
 	o.extractBodyFields(raw)
 	return nil
 }
 
+// Wait blocks a workflow until a jq condition holds on a cluster object,
+// or until the object is deleted.
+type Wait struct {
+	ObjectMeta `json:",inline"`
+	// Condition is a jq expression evaluated on the object; it holds unless it yields false or null.
+	// When empty the step waits for the object to exist.
+	Condition string `json:"condition,omitempty"`
+	// ForDeletion waits until the object no longer exists; Condition is ignored.
+	ForDeletion bool `json:"forDeletion,omitempty"`
+	// Timeout is the maximum time to wait. Defaults to 5m.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// PollInterval is the time between two checks. Defaults to 2s.
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+}
+
+// SetDefaults applies default values to optional fields.
+func (w *Wait) SetDefaults() {
+	if w.Timeout == nil {
+		w.Timeout = &metav1.Duration{Duration: 5 * time.Minute}
+	}
+	if w.PollInterval == nil || w.PollInterval.Duration <= 0 {
+		w.PollInterval = &metav1.Duration{Duration: 2 * time.Second}
+	}
+}
+
+// JobStep runs a Kubernetes Job and optionally exports its outputs as workflow variables.
+type JobStep struct {
+	// Template is the Job manifest; metadata.name is used as prefix of the generated Job name.
+	Template map[string]any `json:"template"`
+	// Timeout is the maximum time to wait for the Job to complete. Defaults to 5m.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Output describes where KEY=VALUE outputs are read from. When nil no output is read.
+	Output *JobOutput `json:"output,omitempty"`
+}
+
+// SetDefaults applies default values to optional fields.
+func (j *JobStep) SetDefaults() {
+	if j.Timeout == nil {
+		j.Timeout = &metav1.Duration{Duration: 5 * time.Minute}
+	}
+	if j.Output != nil {
+		if j.Output.Source == "" {
+			j.Output.Source = JobOutputTerminationMessage
+		}
+		if j.Output.Marker == "" {
+			j.Output.Marker = DefaultJobOutputMarker
+		}
+	}
+}
+
+const (
+	// JobOutputTerminationMessage reads the outputs from the container termination message.
+	JobOutputTerminationMessage = "terminationMessage"
+	// JobOutputLogs reads the outputs from the log lines starting with the output marker.
+	JobOutputLogs = "logs"
+	// DefaultJobOutputMarker prefixes the output lines in the Job logs.
+	DefaultJobOutputMarker = "::krateoctl-output::"
+)
+
+// JobOutput selects the container output parsed for KEY=VALUE pairs.
+type JobOutput struct {
+	// Source is either terminationMessage (default) or logs.
+	Source string `json:"source,omitempty"`
+	// Marker prefixes the output lines when Source is logs.
+	Marker string `json:"marker,omitempty"`
+	// Container is the container to read; defaults to the first container of the pod.
+	Container string `json:"container,omitempty"`
+}
+
+// Manifests applies every object found in a multi-document YAML source.
+type Manifests struct {
+	Source ManifestsSource `json:"source"`
+}
+
+// ManifestsSource locates the manifests; exactly one field must be set.
+type ManifestsSource struct {
+	// File is the path of a YAML file, possibly containing multiple documents.
+	File string `json:"file,omitempty"`
+	// Dir is a directory whose .yaml, .yml and .json files are read in lexical order.
+	Dir string `json:"dir,omitempty"`
+	// URL is an http(s) address serving a YAML file.
+	URL string `json:"url,omitempty"`
+}
+
+// String returns the configured source in a human readable form.
+func (s ManifestsSource) String() string {
+	switch {
+	case s.File != "":
+		return "file " + s.File
+	case s.Dir != "":
+		return "dir " + s.Dir
+	case s.URL != "":
+		return "url " + s.URL
+	default:
+		return ""
+	}
+}
+
+// Validate checks that exactly one source is configured.
+func (s ManifestsSource) Validate() error {
+	count := 0
+	for _, v := range []string{s.File, s.Dir, s.URL} {
+		if v != "" {
+			count++
+		}
+	}
+	if count != 1 {
+		return fmt.Errorf("exactly one of source.file, source.dir or source.url must be set")
+	}
+	return nil
+}
+
 type StepType string
 
 const (
-	TypeObject StepType = "object"
-	TypeChart  StepType = "chart"
-	TypeVar    StepType = "var"
+	TypeObject    StepType = "object"
+	TypeChart     StepType = "chart"
+	TypeVar       StepType = "var"
+	TypeWait      StepType = "wait"
+	TypeJob       StepType = "job"
+	TypeManifests StepType = "manifests"
+	TypeSecret    StepType = "secret"
 )
 
 type Step struct {
@@ -161,6 +369,14 @@ type Step struct {
 	Type StepType        `json:"type"`
 	With *map[string]any `json:"with"`
 	Skip bool            `json:"skip,omitempty"`
+	// DependsOn lists the IDs of the steps that must complete before this one starts.
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	// When is a jq expression evaluated right before the step starts; the step is skipped when it yields false or null.
+	When string `json:"when,omitempty" yaml:"when,omitempty"`
+	// Retry controls how many times the step is attempted before the workflow fails.
+	Retry *RetryPolicy `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// Timeout bounds every attempt of the step, such as 10m; the handler is cancelled when it expires.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 type Workflow struct {
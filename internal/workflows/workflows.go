@@ -2,17 +2,27 @@ package workflows
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/krateoplatformops/krateoctl/internal/cache"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/applier"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/deletor"
 	"github.com/krateoplatformops/krateoctl/internal/dynamic/getter"
+	"github.com/krateoplatformops/krateoctl/internal/health"
+	"github.com/krateoplatformops/krateoctl/internal/redact"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/steps"
 	charthandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/chart"
+	jobhandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/job"
+	manifestshandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/manifests"
 	objecthandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/object"
+	secrethandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/secret"
 	varhandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/var"
+	waithandler "github.com/krateoplatformops/krateoctl/internal/workflows/steps/wait"
 	"github.com/krateoplatformops/krateoctl/internal/workflows/types"
 	"k8s.io/client-go/rest"
 )
@@ -24,6 +34,33 @@ type Opts struct {
 	Logger    func(string, ...any)
 	Cfg       *rest.Config
 	Namespace string
+	// Parallelism limits how many independent steps run at the same time (default 1).
+	Parallelism int
+	// Completed holds the checkpoints of a previous run, keyed by step ID. Steps whose
+	// digest, computed on the input with its variables expanded, still matches are
+	// not executed again; var and secret steps always run.
+	Completed map[string]Checkpoint
+	// OnStepCompleted is invoked, sequentially, after every step that completes successfully.
+	OnStepCompleted func(StepResult[any])
+	// Progress receives status updates from long running steps, such as the time left to a wait step.
+	Progress func(id, message string)
+	// HealthChecks are the user-defined readiness rules used by the steps with waitForReady.
+	HealthChecks []types.HealthCheck
+	// Atomic makes chart steps roll back failed upgrades and uninstall failed installs,
+	// unless a step sets its own atomic option.
+	Atomic bool
+	// Op is the operation passed to the step handlers; Delete runs the steps in reverse order.
+	Op steps.Op
+	// Vars seeds the workflow variables, e.g. with the ones set by a previous run (see RunVars).
+	Vars map[string]string
+	// DryRun runs the steps against the API server in dry-run mode (see steps.HandleOptions).
+	DryRun bool
+}
+
+// Checkpoint describes a step completed by a previous run.
+type Checkpoint struct {
+	Digest string
+	Result any
 }
 
 func New(opts Opts) (*Workflow, error) {
@@ -34,29 +71,79 @@ func New(opts Opts) (*Workflow, error) {
 	if opts.Logger == nil {
 		opts.Logger = func(string, ...any) {}
 	}
+	// Every handler logs through the mask of the sensitive values.
+	sensitive := &redact.Values{}
+	opts.Logger = sensitive.Logger(opts.Logger)
+
 	wf := &Workflow{
-		logger: opts.Logger,
-		ns:     opts.Namespace,
-		env:    cache.New[string, string](),
+		logger:      opts.Logger,
+		ns:          opts.Namespace,
+		parallelism: opts.Parallelism,
+		completed:   opts.Completed,
+		onCompleted: opts.OnStepCompleted,
+		env:         cache.New[string, string](),
+		op:          opts.Op,
+		dryRun:      opts.DryRun,
+	}
+	for k, v := range opts.Vars {
+		wf.env.Set(k, v)
 	}
 
-	wf.varHandler = varhandler.VarHandler(opts.Getter, wf.env, opts.Logger)
-	wf.objectHandler = objecthandler.ObjectHandler(opts.Applier, opts.Deletor, wf.env, opts.Logger)
+	wf.varHandler = varhandler.VarHandler(opts.Getter, wf.env, sensitive, opts.Logger)
+	ready := health.NewWaiter(opts.Getter, opts.HealthChecks, opts.Progress)
+	wf.objectHandler = objecthandler.ObjectHandler(opts.Applier, opts.Deletor, ready, wf.env, opts.Logger)
+	wf.waitHandler = waithandler.WaitHandler(waithandler.WaitHandlerOptions{
+		Dyn:      opts.Getter,
+		Env:      wf.env,
+		Logger:   opts.Logger,
+		Progress: opts.Progress,
+	})
 	wf.chartHandler = charthandler.ChartHandler(charthandler.ChartHandlerOptions{
-		Env:    wf.env,
-		Logger: opts.Logger,
-		Dyn:    opts.Getter,
-		Cfg:    opts.Cfg,
+		Env:       wf.env,
+		Logger:    opts.Logger,
+		Dyn:       opts.Getter,
+		Cfg:       opts.Cfg,
+		Atomic:    opts.Atomic,
+		Readiness: ready,
+		Sensitive: sensitive,
+	})
+	wf.jobHandler = jobhandler.JobHandler(jobhandler.JobHandlerOptions{
+		Applier:   opts.Applier,
+		Getter:    opts.Getter,
+		Cfg:       opts.Cfg,
+		Env:       wf.env,
+		Sensitive: sensitive,
+		Logger:    opts.Logger,
+	})
+	wf.manifestsHandler = manifestshandler.ManifestsHandler(manifestshandler.ManifestsHandlerOptions{
+		Applier: opts.Applier,
+		Deletor: opts.Deletor,
+		Env:     wf.env,
+		Logger:  opts.Logger,
+	})
+	wf.secretHandler = secrethandler.SecretHandler(secrethandler.SecretHandlerOptions{
+		Applier:   opts.Applier,
+		Getter:    opts.Getter,
+		Env:       wf.env,
+		Sensitive: sensitive,
+		Logger:    opts.Logger,
 	})
 
 	return wf, nil
 }
 
 type StepResult[T any] struct {
-	id     string
-	digest string
-	err    error
-	res    T
+	id       string
+	digest   string
+	err      error
+	res      T
+	branch   []string
+	reason   string
+	attempts int
+	resumed  bool
+	duration time.Duration
+	// interrupted is set when the step failed because the run context was cancelled.
+	interrupted bool
 }
 
 func (r *StepResult[T]) ID() string {
@@ -71,6 +158,40 @@ func (r *StepResult[T]) Err() error {
 	return r.err
 }
 
+// Branch returns the chain of dependsOn step IDs that led to a failed step,
+// ending with the step itself. It is empty for successful steps.
+func (r *StepResult[T]) Branch() []string {
+	return r.branch
+}
+
+// Resumed reports whether the step was not executed because a previous run
+// already completed it with the same digest.
+func (r *StepResult[T]) Resumed() bool {
+	return r.resumed
+}
+
+// SkipReason explains why a step whose when condition evaluated to false was skipped.
+func (r *StepResult[T]) SkipReason() string {
+	return r.reason
+}
+
+// Attempts returns how many times the step was executed, including retries.
+func (r *StepResult[T]) Attempts() int {
+	return r.attempts
+}
+
+// Duration returns how long the step ran, retries included. It is zero for
+// the steps that were skipped, resumed or never started.
+func (r *StepResult[T]) Duration() time.Duration {
+	return r.duration
+}
+
+// Interrupted reports whether the step was cancelled while running, because
+// the run context was cancelled on interrupt or at the global timeout.
+func (r *StepResult[T]) Interrupted() bool {
+	return r.interrupted
+}
+
 // Aggiungi questi metodi al StepResult
 
 func (r *StepResult[T]) Result() T {
@@ -79,22 +200,34 @@ func (r *StepResult[T]) Result() T {
 
 func Err[T any](results []StepResult[T]) error {
 	for _, x := range results {
-		if x.Err() != nil {
-			return fmt.Errorf("%s: %w", x.ID(), x.Err())
+		if x.Err() == nil {
+			continue
+		}
+		if branch := x.Branch(); len(branch) > 1 {
+			return fmt.Errorf("%s (branch: %s): %w", x.ID(), strings.Join(branch, " -> "), x.Err())
 		}
+		return fmt.Errorf("%s: %w", x.ID(), x.Err())
 	}
 
 	return nil
 }
 
 type Workflow struct {
-	logger        func(string, ...any)
-	ns            string
-	env           *cache.Cache[string, string]
-	varHandler    steps.Handler[*steps.VarResult]
-	objectHandler steps.Handler[*steps.ObjectResult]
-	chartHandler  steps.Handler[*steps.ChartResult]
-	op            steps.Op
+	logger           func(string, ...any)
+	ns               string
+	parallelism      int
+	completed        map[string]Checkpoint
+	onCompleted      func(StepResult[any])
+	env              *cache.Cache[string, string]
+	varHandler       steps.Handler[*steps.VarResult]
+	objectHandler    steps.Handler[*steps.ObjectResult]
+	chartHandler     steps.Handler[*steps.ChartResult]
+	waitHandler      steps.Handler[*steps.WaitResult]
+	jobHandler       steps.Handler[*steps.JobResult]
+	manifestsHandler steps.Handler[*steps.ManifestsResult]
+	secretHandler    steps.Handler[*steps.SecretResult]
+	op               steps.Op
+	dryRun           bool
 }
 
 func (wf *Workflow) Op(op steps.Op) {
@@ -103,8 +236,30 @@ func (wf *Workflow) Op(op steps.Op) {
 
 // StepNotifier is invoked before each workflow step is executed (or skipped).
 // The idx argument is zero-based and maps directly to the Workflow specification order.
+// Notifications are always delivered sequentially, even when steps run in parallel.
 type StepNotifier func(idx int, step *types.Step, skipped bool)
 
+// Run executes the workflow steps as a dependency graph.
+//
+// Steps wait for the ones listed in dependsOn; when no step declares dependencies
+// they run one after another in declaration order. At most Opts.Parallelism steps
+// run at the same time. After the first failure no further step is started, while
+// the ones already in flight are allowed to finish. Steps that never started keep
+// an empty result ID. In dry-run mode the steps that do not depend on a failed
+// one keep being started, so that every rejection is reported.
+//
+// Once ctx is cancelled no further step is started: the steps in flight are
+// cancelled with it and reported as interrupted (see StepResult.Interrupted).
+//
+// A step with a when expression is evaluated right before it would start (see
+// EvalCondition) and is skipped, with a reason, when the condition is false.
+// Conditions are ignored in delete mode, so that uninstall and prune remove
+// every step.
+// A failing step is retried according to its retry policy before it counts as a failure.
+// Steps found in Opts.Completed with an unchanged digest are reported as resumed
+// and their stored result is made available to the following when conditions.
+// The result of every completed step is also published as ${steps.<id>.<field>}
+// variables (see StepOutputs).
 func (wf *Workflow) Run(ctx context.Context, spec *types.Workflow, skip func(*types.Step) bool, notify StepNotifier) (results []StepResult[any]) {
 	results = make([]StepResult[any], len(spec.Steps))
 
@@ -112,53 +267,274 @@ func (wf *Workflow) Run(ctx context.Context, spec *types.Workflow, skip func(*ty
 		slices.Reverse(spec.Steps)
 	}
 
-	for i, x := range spec.Steps {
-		results[i] = StepResult[any]{id: x.ID}
+	deps, err := types.ResolveDependencies(spec.Steps, wf.op == steps.Delete)
+	if err != nil {
+		idx := 0
+		var depErr *types.DependencyError
+		if errors.As(err, &depErr) {
+			idx = max(slices.IndexFunc(spec.Steps, func(s *types.Step) bool { return s.ID == depErr.StepID }), 0)
+		}
+		if len(results) > 0 {
+			results[idx] = StepResult[any]{id: spec.Steps[idx].ID, err: err}
+		}
+		return
+	}
 
-		if skip != nil && skip(x) {
-			// wf.logr.Debug(fmt.Sprintf("skipping step with id: %s (%v)", x.ID, x.Type))
-			wf.logger(fmt.Sprintf("skipping step with id: %s (%v)", x.ID, x.Type))
-			if notify != nil {
-				notify(i, x, true)
+	pending := make([]int, len(spec.Steps))
+	dependents := make([][]int, len(spec.Steps))
+	for i, list := range deps {
+		pending[i] = len(list)
+		for _, j := range list {
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	var ready []int
+	for i := range spec.Steps {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	// release marks a step as completed and queues the dependents that are now unblocked.
+	release := func(i int) {
+		for _, d := range dependents[i] {
+			pending[d]--
+			if pending[d] == 0 {
+				pos, _ := slices.BinarySearch(ready, d)
+				ready = slices.Insert(ready, pos, d)
 			}
-			continue
 		}
+	}
+
+	parallelism := max(wf.parallelism, 1)
+	done := make(chan int)
+	running := 0
+	failed := false
+	completed := make([]bool, len(spec.Steps))
+
+	for {
+		for (!failed || wf.dryRun) && ctx.Err() == nil && running < parallelism && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+
+			x := spec.Steps[i]
+			results[i] = StepResult[any]{id: x.ID}
+
+			digest, err := wf.digest(x)
+			if err != nil {
+				results[i].err = err
+				failed = true
+				continue
+			}
+			results[i].digest = digest
 
-		wf.logger(fmt.Sprintf("executing step with id: %s (%v)", x.ID, x.Type))
-		if notify != nil {
-			notify(i, x, false)
+			if skip != nil && skip(x) {
+				wf.logger(fmt.Sprintf("skipping step with id: %s (%v)", x.ID, x.Type))
+				if notify != nil {
+					notify(i, x, true)
+				}
+				release(i)
+				continue
+			}
+
+			if cp, ok := wf.completed[x.ID]; ok && cp.Digest == digest && wf.resumable(x, cp.Result) {
+				wf.logger(fmt.Sprintf("step with id: %s (%v) already completed in a previous run", x.ID, x.Type))
+				results[i].res = cp.Result
+				results[i].resumed = true
+				completed[i] = true
+				wf.restore(x, cp.Result)
+				wf.publish(x.ID, cp.Result)
+				if notify != nil {
+					notify(i, x, true)
+				}
+				release(i)
+				continue
+			}
+
+			// In delete mode there are no step results to evaluate the conditions
+			// against, and deleting what a skipped step never created is harmless.
+			if x.When != "" && wf.op != steps.Delete {
+				ok, err := EvalCondition(ctx, x.When, wf.vars(), completedResults(spec.Steps, results, completed))
+				if err != nil {
+					results[i].err = fmt.Errorf("evaluate when condition: %w", err)
+					results[i].branch = types.Branch(spec.Steps, x.ID)
+					failed = true
+					continue
+				}
+				if !ok {
+					results[i].reason = skipReason(x.When)
+					wf.logger(fmt.Sprintf("skipping step with id: %s (%v): %s", x.ID, x.Type, results[i].reason))
+					if notify != nil {
+						notify(i, x, true)
+					}
+					release(i)
+					continue
+				}
+			}
+
+			wf.logger(fmt.Sprintf("executing step with id: %s (%v)", x.ID, x.Type))
+			if notify != nil {
+				notify(i, x, false)
+			}
+
+			running++
+			go func(i int, x *types.Step) {
+				start := time.Now()
+				results[i].res, results[i].attempts, results[i].err = wf.executeWithRetry(ctx, x)
+				results[i].duration = time.Since(start)
+				done <- i
+			}(i, x)
 		}
 
-		switch x.Type {
-		case types.TypeVar:
-			wf.varHandler.Namespace(wf.ns)
-			wf.varHandler.Op(wf.op)
-			result, err := wf.varHandler.Handle(ctx, x.ID, x.With)
-			results[i].res = result
-			results[i].err = err
-
-		case types.TypeObject:
-			wf.objectHandler.Namespace(wf.ns)
-			wf.objectHandler.Op(wf.op)
-			result, err := wf.objectHandler.Handle(ctx, x.ID, x.With)
-			results[i].res = result
-			results[i].err = err
-
-		case types.TypeChart:
-			wf.chartHandler.Namespace(wf.ns)
-			wf.chartHandler.Op(wf.op)
-			result, err := wf.chartHandler.Handle(ctx, x.ID, x.With)
-			results[i].res = result
-			results[i].err = err
-
-		default:
-			results[i].err = fmt.Errorf("handler for step of type %q not found", x.Type)
+		if running == 0 {
+			break
 		}
 
+		i := <-done
+		running--
+
 		if results[i].err != nil {
-			return
+			failed = true
+			results[i].branch = types.Branch(spec.Steps, results[i].id)
+			results[i].interrupted = ctx.Err() != nil
+			continue
 		}
+		completed[i] = true
+		wf.publish(results[i].id, results[i].res)
+		if wf.onCompleted != nil && spec.Steps[i].Type != types.TypeVar {
+			wf.onCompleted(results[i])
+		}
+		release(i)
 	}
 
 	return
 }
+
+// digest fingerprints the step as it is about to run: the placeholders of its
+// input are expanded first, so that a step whose variables changed since the
+// previous run is not resumed.
+func (wf *Workflow) digest(x *types.Step) (string, error) {
+	if x.With == nil {
+		return types.Digest(x)
+	}
+
+	data, err := json.Marshal(x.With)
+	if err != nil {
+		return "", fmt.Errorf("marshal step %s: %w", x.ID, err)
+	}
+	var with map[string]any
+	if err := json.Unmarshal(data, &with); err != nil {
+		return "", fmt.Errorf("unmarshal step %s: %w", x.ID, err)
+	}
+	steps.EnvVars(wf.env).ExpandValues(with)
+
+	return types.Digest(&types.Step{ID: x.ID, Type: x.Type, With: &with})
+}
+
+// alwaysRuns reports whether the step is executed even when a previous run
+// completed it: var and secret steps export variables that only exist while
+// the workflow runs, and running them again changes nothing on the cluster.
+func alwaysRuns(x *types.Step) bool {
+	return x.Type == types.TypeVar || x.Type == types.TypeSecret
+}
+
+// resumable reports whether a completed step can be restored from the result
+// of its checkpoint. The sensitive outputs of a job step are masked in the
+// checkpoint, so the step runs again to compute them.
+func (wf *Workflow) resumable(x *types.Step, res any) bool {
+	if alwaysRuns(x) {
+		return false
+	}
+	if x.Type != types.TypeJob {
+		return true
+	}
+	job, err := jobResult(res)
+	if err != nil {
+		wf.logger(fmt.Sprintf("unable to restore the outputs of step %s: %v", x.ID, err))
+		return false
+	}
+	return len(job.Sensitive) == 0
+}
+
+// restore exports again the variables a resumed step exported when it ran: the
+// outputs of a job step are also available as ${KEY}.
+func (wf *Workflow) restore(x *types.Step, res any) {
+	if x.Type != types.TypeJob {
+		return
+	}
+	job, err := jobResult(res)
+	if err != nil {
+		wf.logger(fmt.Sprintf("unable to restore the outputs of step %s: %v", x.ID, err))
+		return
+	}
+	for k, v := range job.Outputs {
+		wf.env.Set(k, v)
+	}
+}
+
+// jobResult decodes the checkpoint result of a job step, read back from the
+// Installation status as a map.
+func jobResult(res any) (*steps.JobResult, error) {
+	job := &steps.JobResult{}
+	if res == nil {
+		return job, nil
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// vars returns a copy of the workflow variables collected so far.
+func (wf *Workflow) vars() map[string]string {
+	out := map[string]string{}
+	wf.env.ForEach(func(k, v string) bool {
+		out[k] = v
+		return true
+	})
+	return out
+}
+
+// completedResults collects the results of the steps that finished successfully, keyed by step id.
+func completedResults(list []*types.Step, results []StepResult[any], completed []bool) map[string]any {
+	out := make(map[string]any, len(list))
+	for i, ok := range completed {
+		if ok {
+			out[list[i].ID] = results[i].res
+		}
+	}
+	return out
+}
+
+// execute dispatches a single step to the handler matching its type.
+func (wf *Workflow) execute(ctx context.Context, x *types.Step) (any, error) {
+	opts := steps.HandleOptions{
+		Namespace: wf.ns,
+		Op:        wf.op,
+		DryRun:    wf.dryRun,
+	}
+
+	switch x.Type {
+	case types.TypeVar:
+		return wf.varHandler.Handle(ctx, x.ID, x.With, opts)
+	case types.TypeObject:
+		return wf.objectHandler.Handle(ctx, x.ID, x.With, opts)
+	case types.TypeChart:
+		return wf.chartHandler.Handle(ctx, x.ID, x.With, opts)
+	case types.TypeWait:
+		return wf.waitHandler.Handle(ctx, x.ID, x.With, opts)
+	case types.TypeJob:
+		return wf.jobHandler.Handle(ctx, x.ID, x.With, opts)
+	case types.TypeManifests:
+		return wf.manifestsHandler.Handle(ctx, x.ID, x.With, opts)
+	case types.TypeSecret:
+		return wf.secretHandler.Handle(ctx, x.ID, x.With, opts)
+	default:
+		return nil, fmt.Errorf("handler for step of type %q not found", x.Type)
+	}
+}
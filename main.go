@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/krateoplatformops/krateoctl/internal/cmd/gencrd"
 	"github.com/krateoplatformops/krateoctl/internal/cmd/genschema"
@@ -52,6 +55,32 @@ func main() {
 
 	flag.Parse()
 	rest.SetDefaultWarningHandler(rest.NoWarnings{})
-	ctx := context.Background()
-	os.Exit(int(tool.Execute(ctx)))
+	ctx, stop := interruptContext()
+	status := tool.Execute(ctx)
+	stop()
+	os.Exit(int(status))
+}
+
+// interruptContext returns a context cancelled on the first SIGINT or SIGTERM,
+// so that the running command can stop its steps and record their state. The
+// default behaviour is restored afterwards: a second signal exits immediately.
+func interruptContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			fmt.Fprintln(os.Stderr, "\nInterrupted, stopping the running steps (interrupt again to exit immediately)...")
+			cancel(errors.New("interrupted"))
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
 }